@@ -0,0 +1,347 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// GetChapterAnnotations godoc
+// @Summary Get Chapter Annotations
+// @Description Get the current user's highlights, bookmarks and notes on a chapter in reading order. Annotations whose passage was removed by an edit are marked orphaned.
+// @Tags Annotations
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Success 200 {object} GetAnnotationsResponse "Fetched annotations successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Chapter not found"
+// @Router /v1/chapters/{id}/annotations [get]
+func (app *application) getChapterAnnotationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	annotations, err := app.models.Annotations.GetByChapter(user.ID, id)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": annotations}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// CreateAnnotation godoc
+// @Summary Create Annotation
+// @Description Highlight a passage, bookmark a position or attach a note to a chapter the current user can read. Offsets count Unicode characters in the chapter content; a bookmark may use the same start and end offset.
+// @Tags Annotations
+// @Param request body CreateAnnotationBody true "Annotation data to create"
+// @Param id path int true "Chapter ID"
+// @Produce  json
+// @Success 201 {object} AnnotationResponse "Created annotation successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Chapter not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/chapters/{id}/annotations [post]
+func (app *application) createAnnotationHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	chapter, book, ok := app.readCommentableChapter(w, r, id)
+
+	if !ok {
+		return
+	}
+
+	err = app.withholdPremiumContent(user, chapter)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if chapter.Locked {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Kind        string `json:"kind"`
+		StartOffset int    `json:"startOffset"`
+		EndOffset   int    `json:"endOffset"`
+		Note        string `json:"note"`
+		Color       string `json:"color"`
+	}
+
+	err = app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	annotation := &data.Annotation{
+		UserID:    user.ID,
+		BookID:    book.ID,
+		ChapterID: chapter.ID,
+		Kind:      input.Kind,
+		Anchor:    data.Anchor{Start: input.StartOffset, End: input.EndOffset},
+		Note:      strings.TrimSpace(input.Note),
+		Color:     input.Color,
+	}
+
+	if annotation.Kind == data.AnnotationHighlight && annotation.Color == "" {
+		annotation.Color = data.HighlightColors[0]
+	}
+
+	v := validator.New()
+
+	if data.ValidateAnnotation(v, annotation); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	content := ""
+	if chapter.Content != nil {
+		content = *chapter.Content
+	}
+
+	annotation.Anchor, err = data.NewAnchor(content, input.StartOffset, input.EndOffset)
+
+	if err != nil {
+		v.AddError("endOffset", "must be within the chapter content")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	annotation.ChapterTitle = chapter.Title
+
+	err = app.models.Annotations.Insert(annotation)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/annotations/%d", annotation.ID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": annotation}, headers)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// UpdateAnnotation godoc
+// @Summary Update Annotation
+// @Description Change the note or highlight color of one of the current user's annotations
+// @Tags Annotations
+// @Param request body UpdateAnnotationBody true "Annotation data to update"
+// @Param id path int true "Annotation ID"
+// @Produce  json
+// @Success 200 {object} AnnotationResponse "Updated annotation successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 404 {object} GeneralErrorResponse "Annotation not found"
+// @Failure 409 {object} GeneralErrorResponse "Edit Conflict"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/annotations/{id} [patch]
+func (app *application) updateAnnotationHandler(w http.ResponseWriter, r *http.Request) {
+	annotation, ok := app.readOwnAnnotation(w, r)
+
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Note  *string `json:"note"`
+		Color *string `json:"color"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Note != nil {
+		annotation.Note = strings.TrimSpace(*input.Note)
+	}
+
+	if input.Color != nil {
+		annotation.Color = *input.Color
+	}
+
+	v := validator.New()
+
+	if data.ValidateAnnotation(v, annotation); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Annotations.Update(annotation)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": annotation}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// DeleteAnnotation godoc
+// @Summary Delete Annotation
+// @Description Delete one of the current user's annotations
+// @Tags Annotations
+// @Produce  json
+// @Param id path int true "Annotation ID"
+// @Success 200 {object} DeleteSuccessResponse "Deleted annotation successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Annotation not found"
+// @Router /v1/annotations/{id} [delete]
+func (app *application) deleteAnnotationHandler(w http.ResponseWriter, r *http.Request) {
+	annotation, ok := app.readOwnAnnotation(w, r)
+
+	if !ok {
+		return
+	}
+
+	err := app.models.Annotations.Delete(annotation.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "annotation deleted"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// ExportAnnotations godoc
+// @Summary Export my highlights
+// @Description Download the current user's highlights and notes on a book as a Markdown document, grouped by chapter
+// @Tags Annotations
+// @Produce  text/markdown
+// @Param id path int true "Book ID"
+// @Success 200 {string} string "Markdown document"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not found"
+// @Router /v1/books/{id}/annotations/export [get]
+func (app *application) exportAnnotationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	annotations, err := app.models.Annotations.GetByBook(user.ID, book.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("highlights-%d.md", book.ID)))
+
+	if _, err := io.WriteString(w, data.AnnotationsMarkdown(book.Title, annotations)); err != nil {
+		app.logError(r, err)
+	}
+}
+
+// readOwnAnnotation loads the annotation named by the id parameter if it
+// belongs to the current user, and writes a not found response otherwise.
+func (app *application) readOwnAnnotation(w http.ResponseWriter, r *http.Request) (*data.Annotation, bool) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	annotation, err := app.models.Annotations.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return nil, false
+	}
+
+	if annotation.UserID != user.ID {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	return annotation, true
+}
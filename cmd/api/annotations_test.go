@@ -0,0 +1,211 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAnnotationTestApplication returns the chapter test application with a
+// premium chapter (4) added to Dune, which MockReader owns when entitled.
+func newAnnotationTestApplication(t *testing.T, entitled bool) *application {
+	app := newChapterTestApplication(t, false)
+
+	content := "Only buyers read this part."
+
+	chapters := app.models.Chapters.(*mockData.ChapterModel)
+	chapters.Chapters = append(chapters.Chapters, &data.Chapter{
+		ID: 4, ChapterNo: 3, Title: "Three", Content: &content, BookID: 1,
+		UserID: mockData.MockAuthor.ID, Access: data.ChapterAccessPremium, IsPublished: true,
+	})
+
+	if entitled {
+		app.models.Entitlements = &mockData.EntitlementModel{Entitlements: []*data.Entitlement{
+			{UserID: mockData.MockReader.ID, BookID: 1},
+		}}
+	}
+
+	return app
+}
+
+func TestCreateAnnotation(t *testing.T) {
+	tests := []struct {
+		name       string
+		entitled   bool
+		chapterID  string
+		payload    map[string]any
+		wantStatus int
+		wantError  map[string]any
+		wantQuote  string
+		wantColor  string
+	}{
+		{
+			name:       "Highlight",
+			chapterID:  "1",
+			payload:    map[string]any{"kind": "highlight", "startOffset": 9, "endOffset": 13},
+			wantStatus: http.StatusCreated,
+			wantQuote:  "dark",
+			wantColor:  "yellow",
+		},
+		{
+			name:       "Note on a passage",
+			chapterID:  "1",
+			payload:    map[string]any{"kind": "note", "startOffset": 18, "endOffset": 30, "note": "  Classic opening  "},
+			wantStatus: http.StatusCreated,
+			wantQuote:  "stormy night",
+		},
+		{name: "Bookmark", chapterID: "1", payload: map[string]any{"kind": "bookmark", "startOffset": 5, "endOffset": 5}, wantStatus: http.StatusCreated},
+		{
+			name:       "Premium chapter, entitled",
+			entitled:   true,
+			chapterID:  "4",
+			payload:    map[string]any{"kind": "highlight", "startOffset": 0, "endOffset": 4, "color": "green"},
+			wantStatus: http.StatusCreated,
+			wantQuote:  "Only",
+			wantColor:  "green",
+		},
+		{name: "Premium chapter, not entitled", chapterID: "4", payload: map[string]any{"kind": "highlight", "startOffset": 0, "endOffset": 4}, wantStatus: http.StatusForbidden},
+		{name: "Draft chapter", chapterID: "2", payload: map[string]any{"kind": "bookmark"}, wantStatus: http.StatusNotFound},
+		{
+			name:       "Empty highlight",
+			chapterID:  "1",
+			payload:    map[string]any{"kind": "highlight", "startOffset": 9, "endOffset": 9, "color": "orange"},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"endOffset": "must be greater than startOffset", "color": "must be a supported highlight color"},
+		},
+		{
+			name:       "Note without text",
+			chapterID:  "1",
+			payload:    map[string]any{"kind": "note", "startOffset": 0, "endOffset": 2, "note": "   "},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"note": "must be provided"},
+		},
+		{
+			name:       "Past the end of the chapter",
+			chapterID:  "1",
+			payload:    map[string]any{"kind": "highlight", "startOffset": 20, "endOffset": 40},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"endOffset": "must be within the chapter content"},
+		},
+		{
+			name:       "Unknown kind",
+			chapterID:  "1",
+			payload:    map[string]any{"kind": "doodle"},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"kind": "must be highlight, bookmark or note"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newAnnotationTestApplication(t, tt.entitled)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, body := ts.request(t, http.MethodPost, "/v1/chapters/"+tt.chapterID+"/annotations", newTestToken(t, app, mockData.MockReader), tt.payload)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+			}
+
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+
+			annotation, _ := body["data"].(map[string]any)
+			assert.Equal(t, tt.wantQuote, annotation["quote"])
+			assert.Equal(t, tt.wantColor, annotation["color"])
+			assert.Equal(t, float64(mockData.MockReader.ID), annotation["userId"])
+		})
+	}
+}
+
+func TestAnnotationOwnership(t *testing.T) {
+	app := newAnnotationTestApplication(t, false)
+
+	app.models.Annotations = &mockData.AnnotationModel{Annotations: []*data.Annotation{
+		{ID: 1, UserID: mockData.MockReader.ID, BookID: 1, ChapterID: 1, Kind: data.AnnotationHighlight, Anchor: data.Anchor{Start: 9, End: 13, Quote: "dark"}, Color: "yellow", Version: 1},
+		{ID: 2, UserID: mockData.MockAuthor.ID, BookID: 1, ChapterID: 1, Kind: data.AnnotationBookmark, Version: 1},
+	}}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	reader := newTestToken(t, app, mockData.MockReader)
+
+	status, _, body := ts.request(t, http.MethodGet, "/v1/chapters/1/annotations", reader, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, body["data"], 1, "only the reader's own annotations are listed")
+
+	status, _, _ = ts.request(t, http.MethodPatch, "/v1/annotations/2", reader, map[string]string{"note": "Mine now"})
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _, _ = ts.request(t, http.MethodDelete, "/v1/annotations/2", reader, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _, body = ts.request(t, http.MethodPatch, "/v1/annotations/1", reader, map[string]string{"color": "teal"})
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, map[string]any{"color": "must be a supported highlight color"}, body["error"])
+
+	status, _, body = ts.request(t, http.MethodPatch, "/v1/annotations/1", reader, map[string]string{"color": "blue", "note": "Foreshadowing"})
+	assert.Equal(t, http.StatusOK, status)
+
+	annotation, _ := body["data"].(map[string]any)
+	assert.Equal(t, "blue", annotation["color"])
+	assert.Equal(t, "Foreshadowing", annotation["note"])
+	assert.Equal(t, "dark", annotation["quote"], "editing the note keeps the anchored passage")
+
+	status, _, _ = ts.request(t, http.MethodDelete, "/v1/annotations/1", reader, nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _, _ = ts.request(t, http.MethodDelete, "/v1/annotations/1", reader, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestExportAnnotations(t *testing.T) {
+	app := newAnnotationTestApplication(t, true)
+
+	app.models.Annotations = &mockData.AnnotationModel{Annotations: []*data.Annotation{
+		{ID: 1, UserID: mockData.MockReader.ID, BookID: 1, ChapterID: 4, ChapterTitle: "Three", Kind: data.AnnotationHighlight, Anchor: data.Anchor{Start: 0, End: 4, Quote: "Only"}, Color: "yellow"},
+		{ID: 2, UserID: mockData.MockReader.ID, BookID: 1, ChapterID: 1, ChapterTitle: "One", Kind: data.AnnotationNote, Anchor: data.Anchor{Start: 18, End: 30, Quote: "stormy night"}, Note: "Classic opening"},
+		{ID: 3, UserID: mockData.MockReader.ID, BookID: 1, ChapterID: 1, ChapterTitle: "One", Kind: data.AnnotationBookmark, Anchor: data.Anchor{Start: 2, End: 2}},
+		{ID: 4, UserID: mockData.MockReader.ID, BookID: 1, ChapterID: 1, ChapterTitle: "One", Kind: data.AnnotationHighlight, Anchor: data.Anchor{Start: 0, End: 2, Quote: "It"}, Color: "pink", Orphaned: true},
+		{ID: 5, UserID: mockData.MockAuthor.ID, BookID: 1, ChapterID: 1, ChapterTitle: "One", Kind: data.AnnotationNote, Note: "Author's own note"},
+	}}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	token := newTestToken(t, app, mockData.MockReader)
+
+	status, headers, body := ts.download(t, "/v1/books/1/annotations/export", token)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "text/markdown; charset=utf-8", headers.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="highlights-1.md"`, headers.Get("Content-Disposition"))
+	assert.Equal(t, `# Dune
+
+## One
+
+> It
+>
+> *This passage has changed since it was highlighted.*
+
+> stormy night
+
+Classic opening
+
+## Three
+
+> Only
+`, body)
+
+	status, _, _ = ts.download(t, "/v1/books/9/annotations/export", token)
+	assert.Equal(t, http.StatusNotFound, status)
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// SaveChapterAutosave godoc
+// @Summary Save Chapter Autosave
+// @Description Store the current user's unsaved edit of a chapter, replacing their previous autosave. Autosaves do not change the chapter, bump its version or add revisions; commit the autosave to apply it. baseVersion is the chapter version the edit started from.
+// @Tags Chapters
+// @Param request body SaveAutosaveBody true "Autosave data"
+// @Param id path int true "Chapter ID"
+// @Produce  json
+// @Success 200 {object} AutosaveResponse "Saved autosave successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Chapter not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/chapters/{id}/autosave [put]
+func (app *application) saveAutosaveHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	chapter, ok := app.readRevisionChapter(w, r, data.RoleEditor)
+
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Title         string  `json:"title"`
+		Description   string  `json:"description"`
+		Content       string  `json:"content"`
+		ContentFormat *string `json:"contentFormat"`
+		BaseVersion   int     `json:"baseVersion"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	autosave := &data.ChapterAutosave{
+		ChapterID:     chapter.ID,
+		BaseVersion:   input.BaseVersion,
+		Title:         input.Title,
+		Description:   input.Description,
+		Content:       input.Content,
+		ContentFormat: chapter.ContentFormat,
+	}
+
+	if input.ContentFormat != nil {
+		autosave.ContentFormat = *input.ContentFormat
+	}
+
+	v := validator.New()
+
+	v.Check(autosave.BaseVersion <= chapter.Version, "baseVersion", "must not be newer than the chapter")
+
+	if data.ValidateAutosave(v, autosave); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Autosaves.Save(user.ID, autosave)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": autosave}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetChapterAutosave godoc
+// @Summary Get Chapter Autosave
+// @Description Get the current user's autosave of a chapter
+// @Tags Chapters
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Success 200 {object} AutosaveResponse "Fetched autosave successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Autosave not found"
+// @Router /v1/chapters/{id}/autosave [get]
+func (app *application) getAutosaveHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	chapter, ok := app.readRevisionChapter(w, r, data.RoleEditor)
+
+	if !ok {
+		return
+	}
+
+	autosave, err := app.models.Autosaves.Get(user.ID, chapter.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": autosave}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// DiscardChapterAutosave godoc
+// @Summary Discard Chapter Autosave
+// @Description Throw away the current user's autosave of a chapter
+// @Tags Chapters
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Success 200 {object} DeleteSuccessResponse "Discarded autosave successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Autosave not found"
+// @Router /v1/chapters/{id}/autosave [delete]
+func (app *application) discardAutosaveHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	chapter, ok := app.readRevisionChapter(w, r, data.RoleEditor)
+
+	if !ok {
+		return
+	}
+
+	err := app.models.Autosaves.Delete(user.ID, chapter.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "autosave discarded"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// CommitChapterAutosave godoc
+// @Summary Commit Chapter Autosave
+// @Description Apply the current user's autosave to the chapter as a normal update, saving a new revision, and discard the autosave. If the chapter changed since the autosave's base version the content is merged line by line with the current content, and the title and description are only taken from the autosave where it changed them; a clean merge is saved and returned with merged set to true, otherwise the conflicting regions and fields are returned with a 409 and the autosave is kept. A plain 409 is returned when the base version's revision is no longer available to merge against.
+// @Tags Chapters
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Success 200 {object} UpdateChapterResponse "Committed autosave successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Autosave not found"
+// @Failure 409 {object} MergeConflictResponse "Edit Conflict"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/chapters/{id}/autosave/commit [post]
+func (app *application) commitAutosaveHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	chapter, ok := app.readRevisionChapter(w, r, data.RoleEditor)
+
+	if !ok {
+		return
+	}
+
+	autosave, err := app.models.Autosaves.Get(user.ID, chapter.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	title, description, content := autosave.Title, autosave.Description, autosave.Content
+	merged := false
+
+	if autosave.BaseVersion != chapter.Version {
+		base, ok := app.readMergeBase(w, r, chapter, autosave.BaseVersion)
+
+		if !ok {
+			return
+		}
+
+		var conflicts []data.MergeConflict
+
+		content, conflicts = mergeChapterContent(chapter, base, autosave.Content)
+
+		// Title and description are only taken from the autosave where it
+		// changed them, so edits saved since its base version are kept.
+		var conflict *data.MergeConflict
+
+		if title, conflict = data.MergeField("title", base.Title, autosave.Title, chapter.Title); conflict != nil {
+			conflicts = append(conflicts, *conflict)
+		}
+
+		if description, conflict = data.MergeField("description", base.Description, autosave.Description, chapter.Description); conflict != nil {
+			conflicts = append(conflicts, *conflict)
+		}
+
+		if len(conflicts) > 0 {
+			app.mergeConflictResponse(w, r, chapter.Version, conflicts)
+			return
+		}
+
+		merged = true
+	}
+
+	chapter.Title = title
+	chapter.Description = description
+	chapter.Content = &content
+	chapter.ContentFormat = autosave.ContentFormat
+
+	v := validator.New()
+
+	if data.ValidateChapter(v, chapter); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Chapters.Update(chapter)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	// The chapter is saved at this point, so a failure to drop the autosave
+	// is only logged; the purge job removes it eventually.
+	err = app.models.Autosaves.Delete(user.ID, chapter.ID)
+
+	if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+		app.logError(r, err)
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": chapter, "merged": merged}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// purgeAutosaves discards chapter autosaves that have not been touched
+// within the configured time. It runs periodically for the lifetime of the
+// server.
+func (app *application) purgeAutosaves() {
+	before := time.Now().Add(-app.config.autosaves.ttl)
+
+	purged, err := app.models.Autosaves.Purge(before)
+	if err != nil {
+		app.logger.Error("purging chapter autosaves", "error", err)
+		return
+	}
+
+	if purged > 0 {
+		app.logger.Info("purged chapter autosaves", "autosaves", purged)
+	}
+}
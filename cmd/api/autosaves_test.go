@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitAutosave(t *testing.T) {
+	tests := []struct {
+		name            string
+		autosave        data.ChapterAutosave
+		wantStatus      int
+		wantMerged      bool
+		wantTitle       string
+		wantDescription string
+		wantContent     string
+		wantConflicts   []any
+	}{
+		{
+			name:            "Chapter renamed since the base version",
+			autosave:        data.ChapterAutosave{BaseVersion: 2, Title: "One", Description: "First", Content: "a\nb\nC\n"},
+			wantStatus:      http.StatusOK,
+			wantMerged:      true,
+			wantTitle:       "Chapter One",
+			wantDescription: "First",
+			wantContent:     "A\nb\nC\n",
+		},
+		{
+			name:            "Autosave changed the description",
+			autosave:        data.ChapterAutosave{BaseVersion: 2, Title: "One", Description: "The first", Content: "a\nb\nc\n"},
+			wantStatus:      http.StatusOK,
+			wantMerged:      true,
+			wantTitle:       "Chapter One",
+			wantDescription: "The first",
+			wantContent:     "A\nb\nc\n",
+		},
+		{
+			name:            "Both renamed the chapter",
+			autosave:        data.ChapterAutosave{BaseVersion: 2, Title: "Prologue", Description: "First", Content: "a\nb\nC\n"},
+			wantStatus:      http.StatusConflict,
+			wantTitle:       "Chapter One",
+			wantDescription: "First",
+			wantContent:     "A\nb\nc\n",
+			wantConflicts:   []any{map[string]any{"field": "title", "base": "One", "yours": "Prologue", "current": "Chapter One"}},
+		},
+		{
+			name:            "Autosave of the current version",
+			autosave:        data.ChapterAutosave{BaseVersion: 3, Title: "Prologue", Description: "First", Content: "Z\nb\nc\n"},
+			wantStatus:      http.StatusOK,
+			wantTitle:       "Prologue",
+			wantDescription: "First",
+			wantContent:     "Z\nb\nc\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newChapterTestApplication(t, false)
+
+			current := "A\nb\nc\n"
+			base := "a\nb\nc\n"
+
+			app.models.Chapters = &mockData.ChapterModel{Chapters: []*data.Chapter{
+				{ID: 1, ChapterNo: 1, Title: "Chapter One", Description: "First", Content: &current, BookID: 1, UserID: mockData.MockAuthor.ID, Access: data.ChapterAccessFree, ContentFormat: data.ContentFormatText, IsPublished: true, Version: 3},
+			}}
+
+			app.models.Revisions = &mockData.ChapterRevisionModel{Revisions: []*data.ChapterRevision{
+				{ID: 2, ChapterID: 1, Revision: 2, Title: "One", Description: "First", Content: &base},
+			}}
+
+			autosave := tt.autosave
+			autosave.ChapterID = 1
+			autosave.ContentFormat = data.ContentFormatText
+
+			autosaves := &mockData.AutosaveModel{}
+			autosaves.Save(mockData.MockAuthor.ID, &autosave)
+
+			app.models.Autosaves = autosaves
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, body := ts.request(t, http.MethodPost, "/v1/chapters/1/autosave/commit", newTestToken(t, app, mockData.MockAuthor), nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			_, err := autosaves.Get(mockData.MockAuthor.ID, 1)
+
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantMerged, body["merged"])
+				assert.ErrorIs(t, err, data.ErrRecordNotFound, "a committed autosave is discarded")
+			} else {
+				e, _ := body["error"].(map[string]any)
+				assert.Equal(t, tt.wantConflicts, e["conflicts"])
+				assert.NoError(t, err, "a conflicting autosave is kept")
+			}
+
+			chapter, err := app.models.Chapters.Get(1)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantTitle, chapter.Title)
+				assert.Equal(t, tt.wantDescription, chapter.Description)
+				assert.Equal(t, tt.wantContent, *chapter.Content)
+			}
+		})
+	}
+}
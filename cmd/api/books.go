@@ -17,7 +17,10 @@ import (
 // @Param        page   query     int     false  "Page number (default: 1)"
 // @Param        limit  query     int     false  "Items per page (default: 10)"
 // @Param        sort   query     string  false  "Sort by field, e.g. 'name' or '-createdAt' for descending"
+// @Param        cursor query     string  false  "Opaque cursor from metadata.nextCursor or metadata.prevCursor, used instead of page"
+// @Param        count  query     string  false  "Total count mode: exact (default), estimated or none"
 // @Success 200 {object} GetBooksResponse "Fetched Books successfully"
+// @Header 200 {string} Link "RFC 8288 links to the next and previous pages"
 // @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
 // @Router /v1/books [get]
 func (app *application) getBooksHandler(w http.ResponseWriter, r *http.Request) {
@@ -37,8 +40,10 @@ func (app *application) getBooksHandler(w http.ResponseWriter, r *http.Request)
 	input.Filters.PageSize = app.readInt(qs, "limit", 20, v)
 
 	input.Filters.Sort = app.readString(qs, "sort", "created_at")
+	input.Filters.Cursor = app.readString(qs, "cursor", "")
+	input.Filters.Count = app.readString(qs, "count", data.CountExact)
 
-	input.Filters.SortSafelist = []string{"id", "title", "created_at", "published_at", "-id", "-title", "-created_at", "-published_at"}
+	input.Filters.SortSafelist = []string{"id", "title", "created_at", "published_at", "rating", "length", "-id", "-title", "-created_at", "-published_at", "-rating", "-length"}
 
 	if data.ValidateFilter(v, input.Filters); !v.IsValid() {
 		app.failedValidationResponse(w, r, v.Errors)
@@ -52,7 +57,14 @@ func (app *application) getBooksHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"data": books, "metadata": metadata}, nil)
+	err = app.loadBookSeries(books...)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": books, "metadata": metadata}, app.paginationLinks(r, metadata))
 
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -69,7 +81,10 @@ func (app *application) getBooksHandler(w http.ResponseWriter, r *http.Request)
 // @Param        page   query     int     false  "Page number (default: 1)"
 // @Param        limit  query     int     false  "Items per page (default: 10)"
 // @Param        sort   query     string  false  "Sort by field, e.g. 'name' or '-createdAt' for descending"
+// @Param        cursor query     string  false  "Opaque cursor from metadata.nextCursor or metadata.prevCursor, used instead of page"
+// @Param        count  query     string  false  "Total count mode: exact (default), estimated or none"
 // @Success 200 {object} GetBooksResponse "Fetched Books successfully"
+// @Header 200 {string} Link "RFC 8288 links to the next and previous pages"
 // @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
 // @Failure 404 {object} GeneralErrorResponse "Content Not Found Error"
 // @Router /v1/users/{id}/books [get]
@@ -97,8 +112,10 @@ func (app *application) getBooksByUser(w http.ResponseWriter, r *http.Request) {
 	input.Filters.PageSize = app.readInt(qs, "limit", 20, v)
 
 	input.Filters.Sort = app.readString(qs, "sort", "created_at")
+	input.Filters.Cursor = app.readString(qs, "cursor", "")
+	input.Filters.Count = app.readString(qs, "count", data.CountExact)
 
-	input.Filters.SortSafelist = []string{"id", "title", "created_at", "published_at", "-id", "-title", "-created_at", "-published_at"}
+	input.Filters.SortSafelist = []string{"id", "title", "created_at", "published_at", "rating", "length", "-id", "-title", "-created_at", "-published_at", "-rating", "-length"}
 
 	if data.ValidateFilter(v, input.Filters); !v.IsValid() {
 		app.failedValidationResponse(w, r, v.Errors)
@@ -112,7 +129,14 @@ func (app *application) getBooksByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"data": books, "metadata": metadata}, nil)
+	err = app.loadBookSeries(books...)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": books, "metadata": metadata}, app.paginationLinks(r, metadata))
 
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -152,6 +176,13 @@ func (app *application) getBookByIDHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	err = app.loadBookSeries(book)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"data": book}, nil)
 
 	if err != nil {
@@ -183,7 +214,10 @@ func (app *application) createBookHandler(w http.ResponseWriter, r *http.Request
 
 	var input struct {
 		Title        string `json:"title"`
+		Description  string `json:"description"`
 		CoverPicture string `json:"coverPicture"`
+		Price        int64  `json:"price"`
+		Currency     string `json:"currency"`
 	}
 
 	err := app.readJSON(w, r, &input)
@@ -195,10 +229,17 @@ func (app *application) createBookHandler(w http.ResponseWriter, r *http.Request
 
 	book := &data.Book{
 		Title:        input.Title,
+		Description:  input.Description,
 		CoverPicture: input.CoverPicture,
+		Price:        input.Price,
+		Currency:     input.Currency,
 		UserID:       user.ID,
 	}
 
+	if book.Currency == "" {
+		book.Currency = data.DefaultCurrency
+	}
+
 	v := validator.New()
 
 	if data.ValidateBook(v, book); !v.IsValid() {
@@ -261,15 +302,25 @@ func (app *application) updateBookHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if book.UserID != user.ID {
+	permitted, err := app.hasBookRole(user, book, data.RoleEditor)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
 		app.notPermittedResponse(w, r)
 		return
 	}
 
 	var input struct {
 		Title        *string `json:"title"`
+		Description  *string `json:"description"`
 		CoverPicture *string `json:"coverPicture"`
 		IsPublished  *bool   `json:"isPublished"`
+		Price        *int64  `json:"price"`
+		Currency     *string `json:"currency"`
 	}
 
 	err = app.readJSON(w, r, &input)
@@ -287,10 +338,22 @@ func (app *application) updateBookHandler(w http.ResponseWriter, r *http.Request
 		book.Title = *input.Title
 	}
 
+	if input.Description != nil {
+		book.Description = *input.Description
+	}
+
 	if input.IsPublished != nil {
 		book.IsPublished = *input.IsPublished
 	}
 
+	if input.Price != nil {
+		book.Price = *input.Price
+	}
+
+	if input.Currency != nil {
+		book.Currency = *input.Currency
+	}
+
 	v := validator.New()
 
 	if data.ValidateBook(v, book); !v.IsValid() {
@@ -312,6 +375,13 @@ func (app *application) updateBookHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	err = app.loadBookSeries(book)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"data": book}, nil)
 
 	if err != nil {
@@ -322,7 +392,7 @@ func (app *application) updateBookHandler(w http.ResponseWriter, r *http.Request
 
 // Deletebook godoc
 // @Summary Delete Book
-// @Description Delete Book
+// @Description Move a book and its chapters to the trash
 // @Tags Books
 // @Produce  json
 // @Param id path int true "Book ID"
@@ -355,7 +425,14 @@ func (app *application) deleteBookHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if book.UserID != user.ID {
+	permitted, err := app.hasBookRole(user, book, data.RoleOwner)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
 		app.notPermittedResponse(w, r)
 		return
 	}
@@ -372,9 +449,29 @@ func (app *application) deleteBookHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"message": "book successfully deleted"}, nil)
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "book moved to trash"}, nil)
 
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+// loadBookSeries fills in the series each of the books belongs to, if any.
+func (app *application) loadBookSeries(books ...*data.Book) error {
+	bookIDs := make([]int64, len(books))
+
+	for i, book := range books {
+		bookIDs[i] = book.ID
+	}
+
+	series, err := app.models.Series.GetByBooks(bookIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		book.Series = series[book.ID]
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// GetCart godoc
+// @Summary Get my cart
+// @Description Get the books in the current user's cart
+// @Tags Store
+// @Produce  json
+// @Success 200 {object} CartResponse "Fetched cart successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Router /v1/me/cart [get]
+func (app *application) getCartHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	items, err := app.models.Cart.Get(user.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": items}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// AddCartItem godoc
+// @Summary Add book to cart
+// @Description Add a published book to the current user's cart
+// @Tags Store
+// @Param request body AddCartItemBody true "Book to add"
+// @Produce  json
+// @Success 200 {object} CartResponse "Added book to cart successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/me/cart [post]
+func (app *application) addCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	var input struct {
+		BookID int64 `json:"bookId"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if v.Check(input.BookID > 0, "bookId", "must be provided"); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	book, err := app.models.Books.Get(input.BookID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("bookId", "book not found")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	v.Check(book.IsPublished, "bookId", "book is not for sale")
+	v.Check(book.UserID != user.ID, "bookId", "you cannot buy your own book")
+
+	if !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Cart.Add(user.ID, book.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	items, err := app.models.Cart.Get(user.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": items}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// RemoveCartItem godoc
+// @Summary Remove book from cart
+// @Description Remove a book from the current user's cart
+// @Tags Store
+// @Produce  json
+// @Param id path int true "Book ID"
+// @Success 200 {object} DeleteSuccessResponse "Removed book from cart successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not in cart"
+// @Router /v1/me/cart/{id} [delete]
+func (app *application) removeCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	bookID, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.Cart.Remove(user.ID, bookID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "book removed from cart"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
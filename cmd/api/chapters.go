@@ -11,15 +11,21 @@ import (
 
 // GetChaptersByBook godoc
 // @Summary Get Specific Book's Chapters
-// @Description Get Created Chapters By Specific Book
+// @Description Get the table of contents of a book in reading order, without chapter content. Pass include=content to get full chapters instead; premium chapters are then returned without content unless the caller is entitled to the book. Draft chapters are only listed for the book's owner and collaborators.
 // @Tags Chapters
 // @Produce  json
 // @Param id path int true "Book ID"
-// @Success 200 {object} GetChaptersResponse "Fetched Chapters successfully"
+// @Param        page     query     int     false  "Page number (default: 1)"
+// @Param        limit    query     int     false  "Items per page (default: 50)"
+// @Param        include  query     string  false  "Set to content to include chapter content"
+// @Success 200 {object} GetTOCResponse "Fetched Chapters successfully"
 // @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
 // @Failure 404 {object} GeneralErrorResponse "Content Not Found Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
 // @Router /v1/books/{id}/chapters [get]
 func (app *application) getChaptersByBookHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
 	bookId, err := app.readIDParam(r)
 
 	if err != nil {
@@ -27,14 +33,81 @@ func (app *application) getChaptersByBookHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	chapters, err := app.models.Chapters.GetByBookId(bookId)
+	book, err := app.models.Books.Get(bookId)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	var input struct {
+		Include string
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Include = app.readString(qs, "include", "")
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "limit", 50, v)
+
+	input.Filters.Sort = "chapter_no"
+	input.Filters.SortSafelist = []string{"chapter_no"}
+
+	v.Check(input.Include == "" || input.Include == "content", "include", "must be content")
+
+	if data.ValidateFilter(v, input.Filters); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	includeDrafts, err := app.hasBookRole(user, book, data.RoleViewer)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if input.Include != "content" {
+		entries, metadata, err := app.models.Chapters.GetTOC(book.ID, includeDrafts, input.Filters)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		err = app.writeJSON(w, http.StatusOK, envelope{"data": entries, "metadata": metadata}, app.paginationLinks(r, metadata))
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	chapters, metadata, err := app.models.Chapters.GetByBookId(book.ID, includeDrafts, input.Filters)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.withholdPremiumContent(user, chapters...)
 
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"data": chapters}, nil)
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": chapters, "metadata": metadata}, app.paginationLinks(r, metadata))
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -43,10 +116,11 @@ func (app *application) getChaptersByBookHandler(w http.ResponseWriter, r *http.
 
 // GetChapterById godoc
 // @Summary Get Chapter By ID
-// @Description Get Specific Chapter By ID
+// @Description Get Specific Chapter By ID. A draft chapter is only visible to the book's owner and collaborators. A premium chapter is returned without content unless the caller is entitled to the book. With the format query the content is returned as sanitized HTML, as written, or as plain text with Markdown formatting removed.
 // @Tags Chapters
 // @Produce  json
 // @Param id path int true "Chapter ID"
+// @Param        format  query     string  false  "html, markdown or text (default: as written)"
 // @Success 200 {object} GetChapterResponse "Fetched chapter success"
 // @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
 // @Failure 404 {object} GeneralErrorResponse "Book not found"
@@ -59,6 +133,15 @@ func (app *application) getChapterByIDHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	format := app.readString(r.URL.Query(), "format", "")
+
+	v := validator.New()
+
+	if v.Check(format == "" || validator.In(format, "html", "markdown", "text"), "format", "must be html, markdown or text"); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
 	chapter, err := app.models.Chapters.Get(id)
 
 	if err != nil {
@@ -72,6 +155,38 @@ func (app *application) getChapterByIDHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if !chapter.IsPublished {
+		allowed, err := app.hasChapterRole(app.contextGetUser(r), chapter, data.RoleViewer)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if !allowed {
+			app.notFoundResponse(w, r)
+			return
+		}
+	}
+
+	err = app.withholdPremiumContent(app.contextGetUser(r), chapter)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if chapter.Content != nil && format != "" {
+		content, err := app.renderChapter(chapter, format)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		chapter.Content = &content
+	}
+
 	err = app.writeJSON(w, http.StatusOK, envelope{"data": chapter}, nil)
 
 	if err != nil {
@@ -102,9 +217,11 @@ func (app *application) createChapterHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	var input struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		BookID      int64  `json:"bookId"`
+		Title         string `json:"title"`
+		Description   string `json:"description"`
+		BookID        int64  `json:"bookId"`
+		Access        string `json:"access"`
+		ContentFormat string `json:"contentFormat"`
 	}
 
 	err := app.readJSON(w, r, &input)
@@ -115,10 +232,20 @@ func (app *application) createChapterHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	chapter := &data.Chapter{
-		Title:       input.Title,
-		Description: input.Description,
-		BookID:      input.BookID,
-		UserID:      user.ID,
+		Title:         input.Title,
+		Description:   input.Description,
+		BookID:        input.BookID,
+		UserID:        user.ID,
+		Access:        input.Access,
+		ContentFormat: input.ContentFormat,
+	}
+
+	if chapter.Access == "" {
+		chapter.Access = data.ChapterAccessFree
+	}
+
+	if chapter.ContentFormat == "" {
+		chapter.ContentFormat = data.ContentFormatText
 	}
 
 	v := validator.New()
@@ -141,7 +268,14 @@ func (app *application) createChapterHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if book.UserID != user.ID {
+	permitted, err := app.hasBookRole(user, book, data.RoleEditor)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
 		app.notPermittedResponse(w, r)
 		return
 	}
@@ -166,16 +300,17 @@ func (app *application) createChapterHandler(w http.ResponseWriter, r *http.Requ
 
 // UpdateChapter godoc
 // @Summary Update Chapter
-// @Description Update Chapter
+// @Description Update a chapter. Send baseVersion with the version the edit started from; if the chapter has changed since, the new content is merged line by line with the current content. A clean merge is saved and returned with merged set to true, otherwise the conflicting regions are returned with a 409. A plain 409 is returned when the base version's revision is no longer available to merge against.
 // @Tags Chapters
 // @Param request body UpdateChapterBody true "Chapter data to update"
 // @Param id path int true "Chapter ID"
 // @Produce  json
-// @Success 200 {object} BookResponse "Updated chapter successfully"
+// @Success 200 {object} UpdateChapterResponse "Updated chapter successfully"
 // @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
 // @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
 // @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
 // @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 409 {object} MergeConflictResponse "Edit Conflict"
 // @Failure 422 {object} ValidationErrorResponse "Validation Error"
 // @Router /v1/chapters/{id} [patch]
 func (app *application) updateChapterHandler(w http.ResponseWriter, r *http.Request) {
@@ -201,15 +336,25 @@ func (app *application) updateChapterHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if chapter.UserID != user.ID {
+	permitted, err := app.hasChapterRole(user, chapter, data.RoleEditor)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
 		app.notPermittedResponse(w, r)
 		return
 	}
 
 	var input struct {
-		Title       *string `json:"title"`
-		Description *string `json:"description"`
-		Content     *string `json:"content"`
+		Title         *string `json:"title"`
+		Description   *string `json:"description"`
+		Content       *string `json:"content"`
+		ContentFormat *string `json:"contentFormat"`
+		Access        *string `json:"access"`
+		BaseVersion   *int    `json:"baseVersion"`
 	}
 
 	err = app.readJSON(w, r, &input)
@@ -219,6 +364,38 @@ func (app *application) updateChapterHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	v := validator.New()
+
+	if input.BaseVersion != nil {
+		v.Check(*input.BaseVersion >= 1, "baseVersion", "must be a positive integer")
+		v.Check(*input.BaseVersion <= chapter.Version, "baseVersion", "must not be newer than the chapter")
+
+		if !v.IsValid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	merged := false
+
+	if input.BaseVersion != nil && *input.BaseVersion != chapter.Version && input.Content != nil {
+		base, ok := app.readMergeBase(w, r, chapter, *input.BaseVersion)
+
+		if !ok {
+			return
+		}
+
+		content, conflicts := mergeChapterContent(chapter, base, *input.Content)
+
+		if len(conflicts) > 0 {
+			app.mergeConflictResponse(w, r, chapter.Version, conflicts)
+			return
+		}
+
+		input.Content = &content
+		merged = true
+	}
+
 	if input.Title != nil {
 		chapter.Title = *input.Title
 	}
@@ -231,7 +408,13 @@ func (app *application) updateChapterHandler(w http.ResponseWriter, r *http.Requ
 		chapter.Content = input.Content
 	}
 
-	v := validator.New()
+	if input.ContentFormat != nil {
+		chapter.ContentFormat = *input.ContentFormat
+	}
+
+	if input.Access != nil {
+		chapter.Access = *input.Access
+	}
 
 	if data.ValidateChapter(v, chapter); !v.IsValid() {
 		app.failedValidationResponse(w, r, v.Errors)
@@ -252,7 +435,7 @@ func (app *application) updateChapterHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusCreated, envelope{"data": chapter}, nil)
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": chapter, "merged": merged}, nil)
 
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -262,7 +445,7 @@ func (app *application) updateChapterHandler(w http.ResponseWriter, r *http.Requ
 
 // Deletechapter godoc
 // @Summary Delete Chapter
-// @Description Delete Chapter
+// @Description Move a chapter to the trash
 // @Tags Chapters
 // @Produce  json
 // @Param id path int true "Chapter ID"
@@ -295,7 +478,14 @@ func (app *application) deleteChapterHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if chapter.UserID != user.ID {
+	permitted, err := app.hasChapterRole(user, chapter, data.RoleEditor)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
 		app.notPermittedResponse(w, r)
 		return
 	}
@@ -312,10 +502,368 @@ func (app *application) deleteChapterHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"message": "chapter successfully deleted"}, nil)
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "chapter moved to trash"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+
+}
+
+// BatchChapters godoc
+// @Summary Batch Chapters
+// @Description Create, update and delete many chapters of a book in one request, applied in order in a single transaction. Updates only change the fields that are set and, when version is given, only apply to that version. If any operation fails, nothing is saved and the 422 response lists the errors of each failed operation.
+// @Tags Chapters
+// @Param request body BatchChaptersBody true "Chapter operations"
+// @Param id path int true "Book ID"
+// @Produce  json
+// @Success 200 {object} BatchChaptersResponse "Applied operations successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not found"
+// @Failure 422 {object} BatchChaptersErrorResponse "Validation Error"
+// @Router /v1/books/{id}/chapters/batch [post]
+func (app *application) batchChaptersHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	permitted, err := app.hasBookRole(user, book, data.RoleEditor)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Operations []data.ChapterOp `json:"operations"`
+	}
+
+	// A whole manuscript does not fit in the default body limit.
+	err = app.readJSONLimit(w, r, &input, 16<<20)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if data.ValidateChapterBatch(v, input.Operations); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	results, err := app.models.Chapters.Batch(book.ID, user.ID, input.Operations)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrBatchFailed):
+			app.failedBatchResponse(w, r, results)
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": results}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// ReorderChapters godoc
+// @Summary Reorder Chapters
+// @Description Renumber the chapters of a book in the given order. The list must contain every chapter of the book that is not in the trash, exactly once.
+// @Tags Chapters
+// @Param request body ReorderChaptersBody true "Ordered chapter ids"
+// @Param id path int true "Book ID"
+// @Produce  json
+// @Success 200 {object} GetTOCResponse "Reordered chapters successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/books/{id}/chapters/order [put]
+func (app *application) reorderChaptersHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	permitted, err := app.hasBookRole(user, book, data.RoleEditor)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	var input struct {
+		ChapterIDs []int64 `json:"chapterIds"`
+	}
+
+	err = app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if data.ValidateChapterOrder(v, input.ChapterIDs); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Chapters.Reorder(book.ID, input.ChapterIDs)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidChapterOrder):
+			v.AddError("chapterIds", "must list every chapter of the book exactly once")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	toc, _, err := app.models.Chapters.GetTOC(book.ID, true, data.Filters{Page: 1, PageSize: len(input.ChapterIDs)})
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": toc}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// PublishChapter godoc
+// @Summary Publish Chapter
+// @Description Make a draft chapter visible to readers
+// @Tags Chapters
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Success 200 {object} GetChapterResponse "Published chapter successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Chapter not found"
+// @Failure 409 {object} GeneralErrorResponse "Edit Conflict"
+// @Router /v1/chapters/{id}/publish [post]
+func (app *application) publishChapterHandler(w http.ResponseWriter, r *http.Request) {
+	app.setChapterPublished(w, r, true)
+}
+
+// UnpublishChapter godoc
+// @Summary Unpublish Chapter
+// @Description Turn a chapter back into a draft that only the book's owner and collaborators can see
+// @Tags Chapters
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Success 200 {object} GetChapterResponse "Unpublished chapter successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Chapter not found"
+// @Failure 409 {object} GeneralErrorResponse "Edit Conflict"
+// @Router /v1/chapters/{id}/unpublish [post]
+func (app *application) unpublishChapterHandler(w http.ResponseWriter, r *http.Request) {
+	app.setChapterPublished(w, r, false)
+}
+
+func (app *application) setChapterPublished(w http.ResponseWriter, r *http.Request, published bool) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	chapter, err := app.models.Chapters.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	permitted, err := app.hasChapterRole(user, chapter, data.RoleEditor)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	if chapter.IsPublished != published {
+		err = app.models.Chapters.SetPublished(chapter, published)
+
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrEditConflict):
+				app.editConflictResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+
+			return
+		}
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": chapter}, nil)
 
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
+}
+
+// renderChapter returns the chapter's content in the requested format. HTML
+// is cached on the chapter per version so each edit is rendered only once.
+func (app *application) renderChapter(chapter *data.Chapter, format string) (string, error) {
+	switch format {
+	case "html":
+		rendered, err := app.models.Chapters.GetRendered(chapter.ID, chapter.Version)
+
+		if err == nil {
+			return rendered, nil
+		}
+
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			return "", err
+		}
+
+		rendered, err = data.RenderHTML(chapter.ContentFormat, *chapter.Content)
+
+		if err != nil {
+			return "", err
+		}
+
+		err = app.models.Chapters.SaveRendered(chapter.ID, chapter.Version, rendered)
+
+		if err != nil {
+			app.logger.Error("caching rendered chapter", "chapter", chapter.ID, "error", err)
+		}
+
+		return rendered, nil
+	case "text":
+		return data.RenderText(chapter.ContentFormat, *chapter.Content)
+	default:
+		return *chapter.Content, nil
+	}
+}
+
+// readMergeBase returns the chapter as it stood at baseVersion, for merging
+// an edit started from it. It writes the error response itself when it
+// returns false.
+func (app *application) readMergeBase(w http.ResponseWriter, r *http.Request, chapter *data.Chapter, baseVersion int) (*data.ChapterRevision, bool) {
+	base, err := app.models.Revisions.GetAt(chapter.ID, baseVersion)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return nil, false
+	}
+
+	return base, true
+}
+
+// mergeChapterContent merges content, edited from base, into the chapter's
+// current content. When there are conflicts the merged content should not
+// be used.
+func mergeChapterContent(chapter *data.Chapter, base *data.ChapterRevision, content string) (string, []data.MergeConflict) {
+	var baseContent, currentContent string
+
+	if base.Content != nil {
+		baseContent = *base.Content
+	}
+
+	if chapter.Content != nil {
+		currentContent = *chapter.Content
+	}
+
+	merged, conflicts := data.Merge3(baseContent, content, currentContent)
+
+	for i := range conflicts {
+		conflicts[i].Field = "content"
+	}
 
+	return merged, conflicts
 }
@@ -0,0 +1,668 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newChapterTestApplication returns a test application with a published book
+// (1) and a draft book (2) by MockAuthor. Book 1 has a published chapter (1)
+// and a draft chapter (2); book 2 has a published chapter (3). MockReader is
+// a viewer on both books when viewer is set.
+func newChapterTestApplication(t *testing.T, viewer bool) *application {
+	app := newTestApplication(t)
+
+	content := "It was a dark and stormy night."
+
+	app.models.Books = &mockData.BookModel{Books: []*data.Book{
+		{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, IsPublished: true},
+		{ID: 2, Title: "Emma", UserID: mockData.MockAuthor.ID},
+	}}
+
+	app.models.Chapters = &mockData.ChapterModel{Chapters: []*data.Chapter{
+		{ID: 1, ChapterNo: 1, Title: "One", Content: &content, BookID: 1, UserID: mockData.MockAuthor.ID, Access: data.ChapterAccessFree, ContentFormat: data.ContentFormatText, IsPublished: true},
+		{ID: 2, ChapterNo: 2, Title: "Two", Content: &content, BookID: 1, UserID: mockData.MockAuthor.ID, Access: data.ChapterAccessFree, ContentFormat: data.ContentFormatText},
+		{ID: 3, ChapterNo: 1, Title: "One", Content: &content, BookID: 2, UserID: mockData.MockAuthor.ID, Access: data.ChapterAccessFree, ContentFormat: data.ContentFormatText, IsPublished: true},
+	}}
+
+	if viewer {
+		app.models.Collaborators = &mockData.CollaboratorModel{Collaborators: []*data.Collaborator{
+			{BookID: 1, UserID: mockData.MockReader.ID, Role: data.RoleViewer, Status: data.InvitationAccepted},
+			{BookID: 2, UserID: mockData.MockReader.ID, Role: data.RoleViewer, Status: data.InvitationAccepted},
+		}}
+	}
+
+	return app
+}
+
+func TestGetDraftChapter(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		viewer     bool
+		chapterID  string
+		wantStatus int
+	}{
+		{name: "Published chapter, anonymous", chapterID: "1", wantStatus: http.StatusOK},
+		{name: "Draft chapter, anonymous", chapterID: "2", wantStatus: http.StatusNotFound},
+		{name: "Draft chapter, stranger", user: mockData.MockReader, chapterID: "2", wantStatus: http.StatusNotFound},
+		{name: "Draft chapter, viewer", user: mockData.MockReader, viewer: true, chapterID: "2", wantStatus: http.StatusOK},
+		{name: "Draft chapter, owner", user: mockData.MockAuthor, chapterID: "2", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newChapterTestApplication(t, tt.viewer)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			token := ""
+			if tt.user != nil {
+				token = newTestToken(t, app, tt.user)
+			}
+
+			status, _, _ := ts.request(t, http.MethodGet, "/v1/chapters/"+tt.chapterID, token, nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestTOCHidesDrafts(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    *data.User
+		viewer  bool
+		wantIDs []float64
+	}{
+		{name: "Anonymous", wantIDs: []float64{1}},
+		{name: "Stranger", user: mockData.MockReader, wantIDs: []float64{1}},
+		{name: "Viewer", user: mockData.MockReader, viewer: true, wantIDs: []float64{1, 2}},
+		{name: "Owner", user: mockData.MockAuthor, wantIDs: []float64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newChapterTestApplication(t, tt.viewer)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			token := ""
+			if tt.user != nil {
+				token = newTestToken(t, app, tt.user)
+			}
+
+			for _, path := range []string{"/v1/books/1/chapters", "/v1/books/1/chapters?include=content"} {
+				status, _, body := ts.request(t, http.MethodGet, path, token, nil)
+
+				assert.Equal(t, http.StatusOK, status, path)
+
+				ids := []float64{}
+
+				entries, _ := body["data"].([]any)
+
+				for _, entry := range entries {
+					ids = append(ids, entry.(map[string]any)["id"].(float64))
+				}
+
+				assert.Equal(t, tt.wantIDs, ids, path)
+			}
+		})
+	}
+}
+
+func TestCommentsOnDrafts(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		viewer     bool
+		method     string
+		path       string
+		payload    any
+		wantStatus int
+		wantError  any
+	}{
+		{name: "List on draft chapter, stranger", user: mockData.MockReader, method: http.MethodGet, path: "/v1/chapters/2/comments", wantStatus: http.StatusNotFound},
+		{name: "List on draft chapter, viewer", user: mockData.MockReader, viewer: true, method: http.MethodGet, path: "/v1/chapters/2/comments", wantStatus: http.StatusOK},
+		{name: "List on draft book, anonymous", method: http.MethodGet, path: "/v1/chapters/3/comments", wantStatus: http.StatusNotFound},
+		{name: "Replies on draft chapter, anonymous", method: http.MethodGet, path: "/v1/comments/1/replies", wantStatus: http.StatusNotFound},
+		{name: "Replies on draft chapter, viewer", user: mockData.MockReader, viewer: true, method: http.MethodGet, path: "/v1/comments/1/replies", wantStatus: http.StatusOK},
+		{name: "Replies on published chapter, anonymous", method: http.MethodGet, path: "/v1/comments/3/replies", wantStatus: http.StatusOK},
+		{name: "Edit own comment on draft chapter", user: mockData.MockReader, method: http.MethodPatch, path: "/v1/comments/1", payload: map[string]string{"body": "Edited"}, wantStatus: http.StatusNotFound},
+		{name: "Delete own comment on draft chapter", user: mockData.MockReader, method: http.MethodDelete, path: "/v1/comments/1", wantStatus: http.StatusNotFound},
+		{name: "Delete own comment on published chapter", user: mockData.MockReader, method: http.MethodDelete, path: "/v1/comments/3", wantStatus: http.StatusOK},
+		{name: "Post on draft chapter, stranger", user: mockData.MockReader, method: http.MethodPost, path: "/v1/chapters/2/comments", payload: map[string]string{"body": "First!"}, wantStatus: http.StatusNotFound},
+		{
+			name:       "Post on draft chapter, owner",
+			user:       mockData.MockAuthor,
+			method:     http.MethodPost,
+			path:       "/v1/chapters/2/comments",
+			payload:    map[string]string{"body": "First!"},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"chapter": "only published chapters of published books can be commented on"},
+		},
+		{
+			name:       "Post on draft book, owner",
+			user:       mockData.MockAuthor,
+			method:     http.MethodPost,
+			path:       "/v1/chapters/3/comments",
+			payload:    map[string]string{"body": "First!"},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"chapter": "only published chapters of published books can be commented on"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newChapterTestApplication(t, tt.viewer)
+
+			// MockReader commented on chapter 2 before it went back to draft.
+			parentID := int64(1)
+
+			app.models.Comments = &mockData.CommentModel{Comments: []*data.Comment{
+				{ID: 1, ChapterID: 2, UserID: mockData.MockReader.ID, Body: "Great start", CreatedAt: time.Now(), Version: 1},
+				{ID: 2, ChapterID: 2, ParentID: &parentID, UserID: mockData.MockReader.ID, Body: "Agreed", CreatedAt: time.Now(), Version: 1},
+				{ID: 3, ChapterID: 1, UserID: mockData.MockReader.ID, Body: "Loved it", CreatedAt: time.Now(), Version: 1},
+			}}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			token := ""
+			if tt.user != nil {
+				token = newTestToken(t, app, tt.user)
+			}
+
+			status, _, body := ts.request(t, tt.method, tt.path, token, tt.payload)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+			}
+		})
+	}
+}
+
+func TestTOCPagination(t *testing.T) {
+	app := newTestApplication(t)
+
+	content := "It was a dark and stormy night."
+
+	chapters := []*data.Chapter{}
+
+	for i := int64(1); i <= 5; i++ {
+		chapters = append(chapters, &data.Chapter{
+			ID: i, ChapterNo: i, Title: "Chapter", Content: &content, BookID: 1,
+			UserID: mockData.MockAuthor.ID, Access: data.ChapterAccessFree, IsPublished: true,
+		})
+	}
+
+	app.models.Books = &mockData.BookModel{Books: []*data.Book{
+		{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, IsPublished: true},
+	}}
+
+	app.models.Chapters = &mockData.ChapterModel{Chapters: chapters}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name         string
+		query        string
+		wantStatus   int
+		wantIDs      []float64
+		wantMetadata map[string]any
+		wantError    map[string]any
+	}{
+		{
+			name:         "Default page",
+			query:        "",
+			wantStatus:   http.StatusOK,
+			wantIDs:      []float64{1, 2, 3, 4, 5},
+			wantMetadata: map[string]any{"currentPage": float64(1), "pageSize": float64(50), "firstPage": float64(1), "lastPage": float64(1), "totalRecords": float64(5)},
+		},
+		{
+			name:         "Middle page",
+			query:        "?page=2&limit=2",
+			wantStatus:   http.StatusOK,
+			wantIDs:      []float64{3, 4},
+			wantMetadata: map[string]any{"currentPage": float64(2), "pageSize": float64(2), "firstPage": float64(1), "lastPage": float64(3), "totalRecords": float64(5)},
+		},
+		{
+			name:         "Last page",
+			query:        "?page=3&limit=2",
+			wantStatus:   http.StatusOK,
+			wantIDs:      []float64{5},
+			wantMetadata: map[string]any{"currentPage": float64(3), "pageSize": float64(2), "firstPage": float64(1), "lastPage": float64(3), "totalRecords": float64(5)},
+		},
+		{
+			name:         "Past the last page",
+			query:        "?page=4&limit=2",
+			wantStatus:   http.StatusOK,
+			wantIDs:      []float64{},
+			wantMetadata: map[string]any{"currentPage": float64(4), "pageSize": float64(2), "firstPage": float64(1), "lastPage": float64(3), "totalRecords": float64(5)},
+		},
+		{
+			name:       "Zero limit",
+			query:      "?limit=0",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"page_size": "must be greater than zero"},
+		},
+		{
+			name:       "Limit too large",
+			query:      "?limit=101",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"page_size": "must be a maximum of 100"},
+		},
+		{
+			name:       "Unknown include",
+			query:      "?include=comments",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"include": "must be content"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, body := ts.request(t, http.MethodGet, "/v1/books/1/chapters"+tt.query, "", nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+				return
+			}
+
+			ids := []float64{}
+
+			entries, _ := body["data"].([]any)
+
+			for _, entry := range entries {
+				e := entry.(map[string]any)
+
+				assert.NotContains(t, e, "content", "table of contents entries must not carry content")
+
+				ids = append(ids, e["id"].(float64))
+			}
+
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, tt.wantMetadata, body["metadata"])
+		})
+	}
+}
+
+func TestTOCIncludeContent(t *testing.T) {
+	app := newChapterTestApplication(t, false)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	status, _, body := ts.request(t, http.MethodGet, "/v1/books/1/chapters?include=content&limit=1", "", nil)
+
+	assert.Equal(t, http.StatusOK, status)
+
+	entries, _ := body["data"].([]any)
+
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "It was a dark and stormy night.", entries[0].(map[string]any)["content"])
+	}
+}
+
+func TestReorderChapters(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		role       string
+		chapterIDs []int64
+		wantStatus int
+		wantIDs    []float64
+		wantError  map[string]any
+	}{
+		{name: "Owner", user: mockData.MockAuthor, chapterIDs: []int64{2, 1}, wantStatus: http.StatusOK, wantIDs: []float64{2, 1}},
+		{name: "Editor collaborator", user: mockData.MockReader, role: data.RoleEditor, chapterIDs: []int64{2, 1}, wantStatus: http.StatusOK, wantIDs: []float64{2, 1}},
+		{name: "Viewer collaborator", user: mockData.MockReader, role: data.RoleViewer, chapterIDs: []int64{2, 1}, wantStatus: http.StatusForbidden},
+		{name: "Stranger", user: mockData.MockReader, chapterIDs: []int64{2, 1}, wantStatus: http.StatusForbidden},
+		{
+			name:       "Missing chapter",
+			user:       mockData.MockAuthor,
+			chapterIDs: []int64{2},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"chapterIds": "must list every chapter of the book exactly once"},
+		},
+		{
+			name:       "Chapter of another book",
+			user:       mockData.MockAuthor,
+			chapterIDs: []int64{3, 1},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"chapterIds": "must list every chapter of the book exactly once"},
+		},
+		{
+			name:       "Duplicate chapter",
+			user:       mockData.MockAuthor,
+			chapterIDs: []int64{1, 1},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"chapterIds": "must not contain duplicate values"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newChapterTestApplication(t, false)
+
+			if tt.role != "" {
+				app.models.Collaborators = &mockData.CollaboratorModel{Collaborators: []*data.Collaborator{
+					{BookID: 1, UserID: tt.user.ID, Role: tt.role, Status: data.InvitationAccepted},
+				}}
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			payload := map[string][]int64{"chapterIds": tt.chapterIDs}
+
+			status, _, body := ts.request(t, http.MethodPut, "/v1/books/1/chapters/order", newTestToken(t, app, tt.user), payload)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+			}
+
+			toc, _, _ := app.models.Chapters.GetTOC(1, true, data.Filters{Page: 1, PageSize: 10})
+
+			if tt.wantStatus == http.StatusOK {
+				ids := []float64{}
+
+				entries, _ := body["data"].([]any)
+
+				for i, entry := range entries {
+					e := entry.(map[string]any)
+
+					assert.Equal(t, float64(i+1), e["chapterNo"], "chapters are renumbered in the new order")
+
+					ids = append(ids, e["id"].(float64))
+				}
+
+				assert.Equal(t, tt.wantIDs, ids)
+				assert.Equal(t, int64(2), toc[0].ID)
+			} else {
+				assert.Equal(t, int64(1), toc[0].ID, "a refused reorder must not change the order")
+			}
+		})
+	}
+}
+
+func TestUpdateChapterMerge(t *testing.T) {
+	tests := []struct {
+		name          string
+		revisions     []int
+		baseVersion   int
+		content       string
+		wantStatus    int
+		wantContent   string
+		wantConflicts bool
+	}{
+		{name: "Base revision kept", revisions: []int{1, 2, 3}, baseVersion: 2, content: "a\nb\nC\n", wantStatus: http.StatusCreated, wantContent: "A\nb\nC\n"},
+		{name: "Base version from publishing", revisions: []int{1, 3}, baseVersion: 2, content: "a\nb\nC\n", wantStatus: http.StatusCreated, wantContent: "A\nb\nC\n"},
+		{name: "Base revision pruned", revisions: []int{3}, baseVersion: 2, content: "a\nb\nC\n", wantStatus: http.StatusConflict, wantContent: "A\nb\nc\n"},
+		{name: "Conflicting edits", revisions: []int{1, 2, 3}, baseVersion: 2, content: "Z\nb\nc\n", wantStatus: http.StatusConflict, wantContent: "A\nb\nc\n", wantConflicts: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newChapterTestApplication(t, false)
+
+			// Version 2 only published the chapter, so it has the content of
+			// version 1 and, where it was recorded, a revision of its own.
+			contents := map[int]string{1: "a\nb\nc\n", 2: "a\nb\nc\n", 3: "A\nb\nc\n"}
+
+			current := contents[3]
+
+			app.models.Chapters = &mockData.ChapterModel{Chapters: []*data.Chapter{
+				{ID: 1, ChapterNo: 1, Title: "One", Content: &current, BookID: 1, UserID: mockData.MockAuthor.ID, Access: data.ChapterAccessFree, ContentFormat: data.ContentFormatText, IsPublished: true, Version: 3},
+			}}
+
+			revisions := &mockData.ChapterRevisionModel{}
+
+			for _, revision := range tt.revisions {
+				content := contents[revision]
+				revisions.Revisions = append(revisions.Revisions, &data.ChapterRevision{ID: int64(revision), ChapterID: 1, Revision: revision, Content: &content})
+			}
+
+			app.models.Revisions = revisions
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			payload := map[string]any{"content": tt.content, "baseVersion": tt.baseVersion}
+
+			status, _, body := ts.request(t, http.MethodPatch, "/v1/chapters/1", newTestToken(t, app, mockData.MockAuthor), payload)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus == http.StatusCreated {
+				assert.Equal(t, true, body["merged"])
+			}
+
+			if tt.wantConflicts {
+				e, _ := body["error"].(map[string]any)
+				assert.NotEmpty(t, e["conflicts"])
+			}
+
+			chapter, err := app.models.Chapters.Get(1)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantContent, *chapter.Content)
+			}
+		})
+	}
+}
+
+// newMarkdownChapterTestApplication returns a test application with a
+// published Markdown chapter (1) that tries to smuggle in a script and a
+// premium one (2) MockReader is not entitled to.
+func newMarkdownChapterTestApplication(t *testing.T) (*application, *mockData.ChapterModel) {
+	app := newTestApplication(t)
+
+	content := "# Night\n\nIt was a **dark** night.<img src=x onerror=alert(1)>\n"
+	premium := "Only *buyers* read this."
+
+	app.models.Books = &mockData.BookModel{Books: []*data.Book{
+		{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, IsPublished: true},
+	}}
+
+	chapters := &mockData.ChapterModel{Chapters: []*data.Chapter{
+		{ID: 1, ChapterNo: 1, Title: "One", Content: &content, BookID: 1, UserID: mockData.MockAuthor.ID, Access: data.ChapterAccessFree, ContentFormat: data.ContentFormatMarkdown, IsPublished: true, Version: 1},
+		{ID: 2, ChapterNo: 2, Title: "Two", Content: &premium, BookID: 1, UserID: mockData.MockAuthor.ID, Access: data.ChapterAccessPremium, ContentFormat: data.ContentFormatMarkdown, IsPublished: true, Version: 1},
+	}}
+
+	app.models.Chapters = chapters
+
+	return app, chapters
+}
+
+func TestGetChapterFormats(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		wantStatus  int
+		wantContent any
+	}{
+		{name: "As written", path: "/v1/chapters/1", wantStatus: http.StatusOK, wantContent: "# Night\n\nIt was a **dark** night.<img src=x onerror=alert(1)>\n"},
+		{name: "Markdown", path: "/v1/chapters/1?format=markdown", wantStatus: http.StatusOK, wantContent: "# Night\n\nIt was a **dark** night.<img src=x onerror=alert(1)>\n"},
+		{name: "Sanitized HTML", path: "/v1/chapters/1?format=html", wantStatus: http.StatusOK, wantContent: "<h1>Night</h1>\n<p>It was a <strong>dark</strong> night.</p>\n"},
+		{name: "Plain text", path: "/v1/chapters/1?format=text", wantStatus: http.StatusOK, wantContent: "Night\nIt was a dark night."},
+		{name: "Locked premium chapter", path: "/v1/chapters/2?format=html", wantStatus: http.StatusOK, wantContent: nil},
+		{name: "Unknown format", path: "/v1/chapters/1?format=pdf", wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newMarkdownChapterTestApplication(t)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, body := ts.request(t, http.MethodGet, tt.path, newTestToken(t, app, mockData.MockReader), nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus != http.StatusOK {
+				assert.Equal(t, map[string]any{"format": "must be html, markdown or text"}, body["error"])
+				return
+			}
+
+			chapter, _ := body["data"].(map[string]any)
+			assert.Equal(t, tt.wantContent, chapter["content"])
+		})
+	}
+}
+
+func TestRenderedChapterCache(t *testing.T) {
+	app, chapters := newMarkdownChapterTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	reader := newTestToken(t, app, mockData.MockReader)
+
+	renderedContent := func() any {
+		t.Helper()
+
+		status, _, body := ts.request(t, http.MethodGet, "/v1/chapters/1?format=html", reader, nil)
+		assert.Equal(t, http.StatusOK, status)
+
+		chapter, _ := body["data"].(map[string]any)
+		return chapter["content"]
+	}
+
+	first := renderedContent()
+	assert.Equal(t, first, chapters.Rendered[mockData.RenderedKey{ID: 1, Version: 1}], "the first render is cached for the chapter version")
+
+	chapters.Rendered[mockData.RenderedKey{ID: 1, Version: 1}] = "<p>cached</p>"
+	assert.Equal(t, "<p>cached</p>", renderedContent(), "a cached render is served as is")
+
+	status, _, _ := ts.request(t, http.MethodPatch, "/v1/chapters/1", newTestToken(t, app, mockData.MockAuthor), map[string]any{"content": "Plain *now*", "contentFormat": data.ContentFormatText})
+	assert.Equal(t, http.StatusCreated, status)
+
+	assert.Equal(t, "<p>Plain *now*</p>\n", renderedContent(), "an edit is rendered afresh under its new version")
+
+	status, _, body := ts.request(t, http.MethodPatch, "/v1/chapters/1", newTestToken(t, app, mockData.MockAuthor), map[string]any{"contentFormat": "rtf"})
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, map[string]any{"contentFormat": "must be markdown or text"}, body["error"])
+}
+
+func TestBatchChapters(t *testing.T) {
+	type op = map[string]any
+
+	tests := []struct {
+		name         string
+		user         *data.User
+		viewer       bool
+		path         string
+		operations   []op
+		wantStatus   int
+		wantStatuses []any
+		wantErrors   []any
+	}{
+		{
+			name: "Create, update and delete",
+			user: mockData.MockAuthor,
+			path: "/v1/books/1/chapters/batch",
+			operations: []op{
+				{"op": "create", "title": "Three", "content": "# Dawn", "contentFormat": "markdown"},
+				{"op": "update", "id": 1, "title": "Chapter One", "version": 0},
+				{"op": "delete", "id": 2},
+			},
+			wantStatus:   http.StatusOK,
+			wantStatuses: []any{"created", "updated", "deleted"},
+		},
+		{
+			name: "One failure rolls back the rest",
+			user: mockData.MockAuthor,
+			path: "/v1/books/1/chapters/batch",
+			operations: []op{
+				{"op": "create", "title": "Three"},
+				{"op": "update", "id": 3, "title": "Stolen"},
+				{"op": "delete", "id": 1, "version": 5},
+				{"op": "update", "id": 1, "title": "", "access": "vip"},
+				{"op": "rename", "id": 1},
+			},
+			wantStatus:   http.StatusUnprocessableEntity,
+			wantStatuses: []any{"rolledBack", "failed", "failed", "failed", "failed"},
+			wantErrors: []any{
+				nil,
+				map[string]any{"id": "must be a chapter of this book"},
+				map[string]any{"version": "does not match the chapter's current version"},
+				map[string]any{"title": "must be provided", "access": "must be free or premium"},
+				map[string]any{"op": "must be create, update or delete"},
+			},
+		},
+		{name: "No operations", user: mockData.MockAuthor, path: "/v1/books/1/chapters/batch", operations: []op{}, wantStatus: http.StatusUnprocessableEntity},
+		{name: "Viewer", user: mockData.MockReader, viewer: true, path: "/v1/books/1/chapters/batch", operations: []op{{"op": "delete", "id": 1}}, wantStatus: http.StatusForbidden},
+		{name: "Stranger", user: mockData.MockReader, path: "/v1/books/1/chapters/batch", operations: []op{{"op": "delete", "id": 1}}, wantStatus: http.StatusForbidden},
+		{name: "Missing book", user: mockData.MockAuthor, path: "/v1/books/9/chapters/batch", operations: []op{{"op": "delete", "id": 1}}, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newChapterTestApplication(t, tt.viewer)
+			chapters := app.models.Chapters.(*mockData.ChapterModel)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, body := ts.request(t, http.MethodPost, tt.path, newTestToken(t, app, tt.user), map[string]any{"operations": tt.operations})
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			var results []any
+
+			switch status {
+			case http.StatusOK:
+				results, _ = body["data"].([]any)
+			case http.StatusUnprocessableEntity:
+				failure, _ := body["error"].(map[string]any)
+				results, _ = failure["results"].([]any)
+			}
+
+			statuses, errs := []any{}, []any{}
+
+			for _, result := range results {
+				result := result.(map[string]any)
+				statuses = append(statuses, result["status"])
+				errs = append(errs, result["errors"])
+			}
+
+			if tt.wantStatuses != nil {
+				assert.Equal(t, tt.wantStatuses, statuses)
+			}
+
+			if tt.wantErrors != nil {
+				assert.Equal(t, tt.wantErrors, errs)
+			}
+
+			live := []string{}
+
+			for _, c := range chapters.Chapters {
+				if c.DeletedAt == nil {
+					live = append(live, c.Title)
+				}
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, []string{"Chapter One", "One", "Three"}, live)
+			} else {
+				assert.Equal(t, []string{"One", "Two", "One"}, live, "a failed batch saves nothing")
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// GetCollaborators godoc
+// @Summary Get Book Collaborators
+// @Description Get collaborators and pending invitations of a book
+// @Tags Collaborators
+// @Produce  json
+// @Param id path int true "Book ID"
+// @Success 200 {object} GetCollaboratorsResponse "Fetched collaborators successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not found"
+// @Router /v1/books/{id}/collaborators [get]
+func (app *application) getCollaboratorsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	permitted, err := app.hasBookRole(user, book, data.RoleViewer)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	collaborators, err := app.models.Collaborators.GetByBook(book.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": collaborators}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// InviteCollaborator godoc
+// @Summary Invite Collaborator
+// @Description Invite a registered user to collaborate on a book. The invitee receives an email and must accept the invitation. The response is the same pending invitation whether or not the email belongs to an account, or is already invited, so it cannot be used to look up accounts.
+// @Tags Collaborators
+// @Param request body InviteCollaboratorBody true "Invitee email and role"
+// @Param id path int true "Book ID"
+// @Produce  json
+// @Success 201 {object} CollaboratorResponse "Invitation sent successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/books/{id}/collaborators [post]
+func (app *application) inviteCollaboratorHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	permitted, err := app.hasBookRole(user, book, data.RoleOwner)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	var input struct {
+		Email string `json:"email"`
+		Role  string `json:"role"`
+	}
+
+	err = app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	collaborator := &data.Collaborator{
+		BookID:    book.ID,
+		Email:     input.Email,
+		Role:      input.Role,
+		InvitedBy: user.ID,
+	}
+
+	v := validator.New()
+
+	data.ValidateEmail(v, input.Email)
+
+	if data.ValidateCollaborator(v, collaborator); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// The response must not tell the inviter whether the address belongs to
+	// an account, so unknown addresses and repeated invitations are answered
+	// exactly like a fresh one: a pending invitation carrying only what the
+	// inviter sent.
+	response := *collaborator
+	response.Status = data.InvitationPending
+	response.CreatedAt = time.Now()
+
+	invitee, err := app.models.Users.GetByEmail(input.Email)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.writeInvitation(w, r, &response)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if invitee.ID == book.UserID {
+		v.AddError("email", "the author of the book cannot be invited")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	collaborator.UserID = invitee.ID
+
+	err = app.models.Collaborators.Invite(collaborator)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateCollaborator):
+			app.writeInvitation(w, r, &response)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	response.CreatedAt = collaborator.CreatedAt
+
+	app.background(func() {
+
+		data := map[string]interface{}{
+			"bookID":      book.ID,
+			"bookTitle":   book.Title,
+			"inviterName": user.Name,
+			"role":        collaborator.Role,
+		}
+
+		err := app.mailer.Send(invitee.Email, "collaborator_invite.tmpl", data)
+		if err != nil {
+			app.logger.Error(err.Error())
+		}
+
+	})
+
+	app.writeInvitation(w, r, &response)
+}
+
+func (app *application) writeInvitation(w http.ResponseWriter, r *http.Request, invitation *data.Collaborator) {
+	err := app.writeJSON(w, http.StatusCreated, envelope{"data": invitation}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// RemoveCollaborator godoc
+// @Summary Remove Collaborator
+// @Description Remove a collaborator or revoke an invitation. Collaborators may also remove themselves.
+// @Tags Collaborators
+// @Produce  json
+// @Param id path int true "Book ID"
+// @Param userId path int true "Collaborator User ID"
+// @Success 200 {object} DeleteSuccessResponse "Removed collaborator successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Collaborator not found"
+// @Router /v1/books/{id}/collaborators/{userId} [delete]
+func (app *application) removeCollaboratorHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	collaboratorID, err := app.readParamInt(r, "userId")
+
+	if err != nil || collaboratorID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if collaboratorID != user.ID {
+		permitted, err := app.hasBookRole(user, book, data.RoleOwner)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if !permitted {
+			app.notPermittedResponse(w, r)
+			return
+		}
+	}
+
+	err = app.models.Collaborators.Delete(book.ID, collaboratorID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "collaborator successfully removed"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetInvitations godoc
+// @Summary Get my invitations
+// @Description Get pending collaboration invitations for the current user
+// @Tags Collaborators
+// @Produce  json
+// @Success 200 {object} GetCollaboratorsResponse "Fetched invitations successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Router /v1/me/invitations [get]
+func (app *application) getInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	invitations, err := app.models.Collaborators.GetInvitationsByUser(user.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": invitations}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// AcceptInvitation godoc
+// @Summary Accept invitation
+// @Description Accept a pending invitation to collaborate on a book
+// @Tags Collaborators
+// @Produce  json
+// @Param id path int true "Book ID"
+// @Success 200 {object} DeleteSuccessResponse "Accepted invitation successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Invitation not found"
+// @Router /v1/me/invitations/{id}/accept [post]
+func (app *application) acceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	app.respondInvitation(w, r, true)
+}
+
+// DeclineInvitation godoc
+// @Summary Decline invitation
+// @Description Decline a pending invitation to collaborate on a book
+// @Tags Collaborators
+// @Produce  json
+// @Param id path int true "Book ID"
+// @Success 200 {object} DeleteSuccessResponse "Declined invitation successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Invitation not found"
+// @Router /v1/me/invitations/{id}/decline [post]
+func (app *application) declineInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	app.respondInvitation(w, r, false)
+}
+
+func (app *application) respondInvitation(w http.ResponseWriter, r *http.Request, accept bool) {
+	user := app.contextGetUser(r)
+
+	bookID, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.Collaborators.Respond(bookID, user.ID, accept)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	message := "invitation declined"
+	if accept {
+		message = "invitation accepted"
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": message}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCollaboratorTestApplication returns a test application with one book
+// by MockAuthor on which MockReader holds role, or no role when it is empty.
+func newCollaboratorTestApplication(t *testing.T, role, status string) (*application, *mockData.CollaboratorModel) {
+	app := newTestApplication(t)
+
+	app.models.Books = &mockData.BookModel{Books: []*data.Book{
+		{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, IsPublished: true},
+	}}
+
+	collaborators := &mockData.CollaboratorModel{}
+
+	if role != "" {
+		collaborators.Collaborators = []*data.Collaborator{
+			{BookID: 1, UserID: mockData.MockReader.ID, Role: role, Status: status},
+		}
+	}
+
+	app.models.Collaborators = collaborators
+
+	return app, collaborators
+}
+
+func TestGetCollaborators(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		role       string
+		status     string
+		wantStatus int
+	}{
+		{name: "Owner", user: mockData.MockAuthor, wantStatus: http.StatusOK},
+		{name: "Viewer collaborator", user: mockData.MockReader, role: data.RoleViewer, status: data.InvitationAccepted, wantStatus: http.StatusOK},
+		{name: "Pending invitation", user: mockData.MockReader, role: data.RoleEditor, status: data.InvitationPending, wantStatus: http.StatusForbidden},
+		{name: "Declined invitation", user: mockData.MockReader, role: data.RoleEditor, status: data.InvitationDeclined, wantStatus: http.StatusForbidden},
+		{name: "Stranger", user: mockData.MockReader, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newCollaboratorTestApplication(t, tt.role, tt.status)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, _ := ts.request(t, http.MethodGet, "/v1/books/1/collaborators", newTestToken(t, app, tt.user), nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestInviteCollaborator(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		role       string
+		email      string
+		wantStatus int
+		wantError  map[string]any
+	}{
+		{name: "Owner", user: mockData.MockAuthor, email: mockData.MockUser.Email, wantStatus: http.StatusCreated},
+		{name: "Owner collaborator", user: mockData.MockReader, role: data.RoleOwner, email: mockData.MockUser.Email, wantStatus: http.StatusCreated},
+		{name: "Editor collaborator", user: mockData.MockReader, role: data.RoleEditor, email: mockData.MockUser.Email, wantStatus: http.StatusForbidden},
+		{name: "Stranger", user: mockData.MockReader, email: mockData.MockUser.Email, wantStatus: http.StatusForbidden},
+		{name: "Unknown email", user: mockData.MockAuthor, email: "nobody@example.com", wantStatus: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, collaborators := newCollaboratorTestApplication(t, tt.role, data.InvitationAccepted)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			payload := map[string]string{"email": tt.email, "role": data.RoleEditor}
+
+			status, _, body := ts.request(t, http.MethodPost, "/v1/books/1/collaborators", newTestToken(t, app, tt.user), payload)
+
+			app.wg.Wait()
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+			}
+
+			role, err := collaborators.GetRole(1, mockData.MockUser.ID)
+			assert.ErrorIs(t, err, data.ErrRecordNotFound, "an invitation must not grant a role before it is accepted")
+			assert.Empty(t, role)
+
+			invitations, _ := collaborators.GetInvitationsByUser(mockData.MockUser.ID)
+
+			if tt.wantStatus == http.StatusCreated && tt.email == mockData.MockUser.Email {
+				assert.Len(t, invitations, 1)
+			} else {
+				assert.Empty(t, invitations)
+			}
+		})
+	}
+}
+
+// TestInviteCollaboratorResponse checks that an invitation to an unknown
+// address or a repeated invitation cannot be told apart from a fresh one.
+func TestInviteCollaboratorResponse(t *testing.T) {
+	app, collaborators := newCollaboratorTestApplication(t, "", "")
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	token := newTestToken(t, app, mockData.MockAuthor)
+
+	invite := func(email string) map[string]any {
+		status, _, body := ts.request(t, http.MethodPost, "/v1/books/1/collaborators", token, map[string]string{"email": email, "role": data.RoleEditor})
+		assert.Equal(t, http.StatusCreated, status)
+
+		invitation, ok := body["data"].(map[string]any)
+		assert.True(t, ok)
+		delete(invitation, "createdAt")
+		delete(invitation, "email")
+
+		return invitation
+	}
+
+	registered := invite(mockData.MockUser.Email)
+	repeated := invite(mockData.MockUser.Email)
+	unknown := invite("nobody@example.com")
+
+	app.wg.Wait()
+
+	assert.Equal(t, registered, repeated)
+	assert.Equal(t, registered, unknown)
+	assert.Equal(t, data.InvitationPending, unknown["status"])
+	assert.Len(t, collaborators.Collaborators, 1, "only the registered user is invited")
+}
+
+func TestRemoveCollaborator(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		role       string
+		path       string
+		wantStatus int
+	}{
+		{name: "Owner removes collaborator", user: mockData.MockAuthor, role: data.RoleEditor, path: "/v1/books/1/collaborators/3", wantStatus: http.StatusOK},
+		{name: "Collaborator leaves", user: mockData.MockReader, role: data.RoleViewer, path: "/v1/books/1/collaborators/3", wantStatus: http.StatusOK},
+		{name: "Editor removes owner", user: mockData.MockReader, role: data.RoleEditor, path: "/v1/books/1/collaborators/2", wantStatus: http.StatusForbidden},
+		{name: "Not a collaborator", user: mockData.MockAuthor, path: "/v1/books/1/collaborators/3", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newCollaboratorTestApplication(t, tt.role, data.InvitationAccepted)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, _ := ts.request(t, http.MethodDelete, tt.path, newTestToken(t, app, tt.user), nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
@@ -0,0 +1,558 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// GetComments godoc
+// @Summary Get Chapter Comments
+// @Description Get a page of a chapter's top-level comments. The first page also lists the pinned comments.
+// @Tags Comments
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Param        limit  query     int     false  "Items per page (default: 20)"
+// @Param        sort   query     string  false  "created_at (oldest first, default) or -created_at (newest first)"
+// @Param        cursor query     string  false  "Opaque cursor from metadata.nextCursor or metadata.prevCursor"
+// @Success 200 {object} GetCommentsResponse "Fetched comments successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 404 {object} GeneralErrorResponse "Chapter not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/chapters/{id}/comments [get]
+func (app *application) getCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	chapter, _, ok := app.readCommentableChapter(w, r, id)
+
+	if !ok {
+		return
+	}
+
+	filters, ok := app.readCommentFilters(w, r)
+
+	if !ok {
+		return
+	}
+
+	comments, metadata, err := app.models.Comments.GetByChapter(chapter.ID, filters)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	response := envelope{"data": comments, "metadata": metadata}
+
+	if filters.Cursor == "" {
+		pinned, err := app.models.Comments.GetPinned(chapter.ID)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		response["pinned"] = pinned
+	}
+
+	err = app.writeJSON(w, http.StatusOK, response, app.paginationLinks(r, metadata))
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetCommentReplies godoc
+// @Summary Get Comment Replies
+// @Description Get a page of the direct replies to a comment
+// @Tags Comments
+// @Produce  json
+// @Param id path int true "Comment ID"
+// @Param        limit  query     int     false  "Items per page (default: 20)"
+// @Param        sort   query     string  false  "created_at (oldest first, default) or -created_at (newest first)"
+// @Param        cursor query     string  false  "Opaque cursor from metadata.nextCursor or metadata.prevCursor"
+// @Success 200 {object} GetCommentRepliesResponse "Fetched replies successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 404 {object} GeneralErrorResponse "Comment not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/comments/{id}/replies [get]
+func (app *application) getCommentRepliesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	comment, err := app.models.Comments.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if _, _, ok := app.readCommentableChapter(w, r, comment.ChapterID); !ok {
+		return
+	}
+
+	filters, ok := app.readCommentFilters(w, r)
+
+	if !ok {
+		return
+	}
+
+	replies, metadata, err := app.models.Comments.GetReplies(comment.ID, filters)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": replies, "metadata": metadata}, app.paginationLinks(r, metadata))
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// CreateComment godoc
+// @Summary Create Comment
+// @Description Comment on a published chapter of a published book, or reply to another comment on it. Posting is rate limited per user.
+// @Tags Comments
+// @Param request body CreateCommentBody true "Comment data to create"
+// @Param id path int true "Chapter ID"
+// @Produce  json
+// @Success 201 {object} CommentResponse "Created comment successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 404 {object} GeneralErrorResponse "Chapter not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Failure 429 {object} GeneralErrorResponse "Rate limit exceeded"
+// @Router /v1/chapters/{id}/comments [post]
+func (app *application) createCommentHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	chapter, book, ok := app.readCommentableChapter(w, r, id)
+
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+
+	if !book.IsPublished || !chapter.IsPublished {
+		v.AddError("chapter", "only published chapters of published books can be commented on")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	var input struct {
+		Body     string `json:"body"`
+		ParentID *int64 `json:"parentId"`
+	}
+
+	err = app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	comment := &data.Comment{
+		ChapterID: chapter.ID,
+		ParentID:  input.ParentID,
+		UserID:    user.ID,
+		UserName:  user.Name,
+		Body:      strings.TrimSpace(input.Body),
+	}
+
+	if data.ValidateComment(v, comment); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if input.ParentID != nil {
+		parent, err := app.models.Comments.Get(*input.ParentID)
+
+		if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if parent == nil || parent.ChapterID != chapter.ID || parent.Deleted {
+			v.AddError("parentId", "must be an existing comment on this chapter")
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	err = app.models.Comments.Insert(comment, app.config.comments.rateLimit, app.config.comments.rateWindow)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrCommentRateLimited):
+			app.rateLimitExceededResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/comments/%d", comment.ID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": comment}, headers)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// UpdateComment godoc
+// @Summary Update Comment
+// @Description Edit one of the current user's comments. Comments can only be edited for a short time after posting.
+// @Tags Comments
+// @Param request body UpdateCommentBody true "Comment data to update"
+// @Param id path int true "Comment ID"
+// @Produce  json
+// @Success 200 {object} CommentResponse "Updated comment successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Edit window has passed"
+// @Failure 404 {object} GeneralErrorResponse "Comment not found"
+// @Failure 409 {object} GeneralErrorResponse "Edit Conflict"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/comments/{id} [patch]
+func (app *application) updateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	comment, err := app.models.Comments.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if comment.UserID != user.ID || comment.Deleted {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if _, _, ok := app.readCommentableChapter(w, r, comment.ChapterID); !ok {
+		return
+	}
+
+	if !comment.CanEdit(time.Now(), app.config.comments.editWindow) {
+		app.errorResponse(w, r, http.StatusForbidden, fmt.Sprintf("comments can only be edited within %s of posting", app.config.comments.editWindow))
+		return
+	}
+
+	var input struct {
+		Body string `json:"body"`
+	}
+
+	err = app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	comment.Body = strings.TrimSpace(input.Body)
+
+	v := validator.New()
+
+	if data.ValidateComment(v, comment); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Comments.Update(comment)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": comment}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// DeleteComment godoc
+// @Summary Delete Comment
+// @Description Delete a comment. Its author, the book's owner and editors, and admins can delete it. Replies stay in the thread under a deleted placeholder.
+// @Tags Comments
+// @Produce  json
+// @Param id path int true "Comment ID"
+// @Success 200 {object} DeleteSuccessResponse "Deleted comment successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Comment not found"
+// @Router /v1/comments/{id} [delete]
+func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	comment, ok := app.readModeratedComment(w, r, true)
+
+	if !ok {
+		return
+	}
+
+	err := app.models.Comments.Delete(comment.ID, user.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "comment deleted"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// PinComment godoc
+// @Summary Pin Comment
+// @Description Pin a comment to the top of its chapter's comments. Book owners, editors and admins only.
+// @Tags Comments
+// @Produce  json
+// @Param id path int true "Comment ID"
+// @Success 200 {object} CommentResponse "Pinned comment successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Comment not found"
+// @Router /v1/comments/{id}/pin [post]
+func (app *application) pinCommentHandler(w http.ResponseWriter, r *http.Request) {
+	app.setCommentPinned(w, r, true)
+}
+
+// UnpinComment godoc
+// @Summary Unpin Comment
+// @Description Unpin a pinned comment. Book owners, editors and admins only.
+// @Tags Comments
+// @Produce  json
+// @Param id path int true "Comment ID"
+// @Success 200 {object} CommentResponse "Unpinned comment successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Comment not found"
+// @Router /v1/comments/{id}/unpin [post]
+func (app *application) unpinCommentHandler(w http.ResponseWriter, r *http.Request) {
+	app.setCommentPinned(w, r, false)
+}
+
+func (app *application) setCommentPinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	comment, ok := app.readModeratedComment(w, r, false)
+
+	if !ok {
+		return
+	}
+
+	err := app.models.Comments.SetPinned(comment.ID, pinned)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	comment.Pinned = pinned
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": comment}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// readModeratedComment loads the comment named by the id parameter if the
+// current user can moderate it: admins and the owner and editors of the book.
+// With allowAuthor the comment's own author is accepted too.
+func (app *application) readModeratedComment(w http.ResponseWriter, r *http.Request, allowAuthor bool) (*data.Comment, bool) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	comment, err := app.models.Comments.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return nil, false
+	}
+
+	if comment.Deleted {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	if user.IsAdmin {
+		return comment, true
+	}
+
+	chapter, _, ok := app.readCommentableChapter(w, r, comment.ChapterID)
+
+	if !ok {
+		return nil, false
+	}
+
+	if allowAuthor && comment.UserID == user.ID {
+		return comment, true
+	}
+
+	allowed, err := app.hasChapterRole(user, chapter, data.RoleEditor)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return nil, false
+	}
+
+	if !allowed {
+		app.notPermittedResponse(w, r)
+		return nil, false
+	}
+
+	return comment, true
+}
+
+// readCommentableChapter loads a chapter and its book for the comment
+// endpoints. Comments on draft chapters and unpublished books are only
+// visible to people who work on the book, so every comment endpoint goes
+// through it.
+func (app *application) readCommentableChapter(w http.ResponseWriter, r *http.Request, id int64) (*data.Chapter, *data.Book, bool) {
+	chapter, err := app.models.Chapters.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return nil, nil, false
+	}
+
+	book, err := app.models.Books.Get(chapter.BookID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return nil, nil, false
+	}
+
+	if !book.IsPublished || !chapter.IsPublished {
+		allowed, err := app.hasBookRole(app.contextGetUser(r), book, data.RoleViewer)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return nil, nil, false
+		}
+
+		if !allowed {
+			app.notFoundResponse(w, r)
+			return nil, nil, false
+		}
+	}
+
+	return chapter, book, true
+}
+
+func (app *application) readCommentFilters(w http.ResponseWriter, r *http.Request) (data.Filters, bool) {
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	filters := data.Filters{
+		Page:         1,
+		PageSize:     app.readInt(qs, "limit", 20, v),
+		Sort:         app.readString(qs, "sort", "created_at"),
+		SortSafelist: []string{"created_at", "-created_at"},
+		Cursor:       app.readString(qs, "cursor", ""),
+	}
+
+	if data.ValidateFilter(v, filters); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return filters, false
+	}
+
+	return filters, true
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCommentTestApplication returns the chapter test application with a
+// thread on published chapter 1: MockReader's comment (1) posted a minute
+// ago with MockAuthor's reply (2), an older comment by MockAuthor (3) and a
+// deleted one (5). Comment 4 sits on draft chapter 2. When editor is set,
+// MockReader is an editor of the book. Users may post two comments an hour
+// and edit them for ten minutes.
+func newCommentTestApplication(t *testing.T, editor bool) (*application, *mockData.CommentModel) {
+	app := newChapterTestApplication(t, false)
+
+	if editor {
+		app.models.Collaborators = &mockData.CollaboratorModel{Collaborators: []*data.Collaborator{
+			{BookID: 1, UserID: mockData.MockReader.ID, Role: data.RoleEditor, Status: data.InvitationAccepted},
+		}}
+	}
+
+	now := time.Now()
+	parentID := int64(1)
+
+	comments := &mockData.CommentModel{Comments: []*data.Comment{
+		{ID: 1, ChapterID: 1, UserID: mockData.MockReader.ID, Body: "Loved it", CreatedAt: now.Add(-time.Minute), Version: 1},
+		{ID: 2, ChapterID: 1, ParentID: &parentID, UserID: mockData.MockAuthor.ID, Body: "Thank you!", CreatedAt: now.Add(-2 * time.Hour), Version: 1},
+		{ID: 3, ChapterID: 1, UserID: mockData.MockAuthor.ID, Body: "Part two is coming", CreatedAt: now.Add(-3 * time.Hour), Version: 1},
+		{ID: 4, ChapterID: 2, UserID: mockData.MockAuthor.ID, Body: "Notes", CreatedAt: now.Add(-3 * time.Hour), Version: 1},
+		{ID: 5, ChapterID: 1, UserID: mockData.MockAdmin.ID, Deleted: true, CreatedAt: now.Add(-3 * time.Hour), Version: 1},
+	}}
+
+	app.models.Comments = comments
+	app.config.comments.editWindow = 10 * time.Minute
+	app.config.comments.rateLimit = 2
+	app.config.comments.rateWindow = time.Hour
+
+	return app, comments
+}
+
+func TestCreateComment(t *testing.T) {
+	parent := func(id int64) *int64 { return &id }
+
+	tests := []struct {
+		name       string
+		parentID   *int64
+		body       string
+		wantStatus int
+		wantError  map[string]any
+	}{
+		{name: "Top-level comment", body: "What a twist", wantStatus: http.StatusCreated},
+		{name: "Reply", parentID: parent(2), body: "You're welcome", wantStatus: http.StatusCreated},
+		{
+			name:       "Blank body",
+			body:       "   ",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"body": "must be provided"},
+		},
+		{
+			name:       "Reply to a comment on another chapter",
+			parentID:   parent(4),
+			body:       "Me too",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"parentId": "must be an existing comment on this chapter"},
+		},
+		{
+			name:       "Reply to a deleted comment",
+			parentID:   parent(5),
+			body:       "Me too",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"parentId": "must be an existing comment on this chapter"},
+		},
+		{
+			name:       "Reply to a missing comment",
+			parentID:   parent(99),
+			body:       "Me too",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"parentId": "must be an existing comment on this chapter"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, comments := newCommentTestApplication(t, false)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			payload := map[string]any{"body": tt.body, "parentId": tt.parentID}
+
+			status, headers, body := ts.request(t, http.MethodPost, "/v1/chapters/1/comments", newTestToken(t, app, mockData.MockReader), payload)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+				assert.Len(t, comments.Comments, 5)
+				return
+			}
+
+			assert.Equal(t, "/v1/comments/6", headers.Get("Location"))
+
+			comment, _ := body["data"].(map[string]any)
+			assert.Equal(t, tt.body, comment["body"])
+			assert.Equal(t, mockData.MockReader.Name, comment["userName"])
+		})
+	}
+}
+
+func TestCreateCommentRateLimit(t *testing.T) {
+	app, comments := newCommentTestApplication(t, false)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	token := newTestToken(t, app, mockData.MockReader)
+
+	// MockReader posted comment 1 a minute ago, so only one more fits in the hour.
+	status, _, _ := ts.request(t, http.MethodPost, "/v1/chapters/1/comments", token, map[string]string{"body": "Second"})
+	assert.Equal(t, http.StatusCreated, status)
+
+	status, _, body := ts.request(t, http.MethodPost, "/v1/chapters/1/comments", token, map[string]string{"body": "Third"})
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	assert.Equal(t, "rate limit exceeded", body["error"])
+	assert.Len(t, comments.Comments, 6)
+
+	status, _, _ = ts.request(t, http.MethodPost, "/v1/chapters/1/comments", newTestToken(t, app, mockData.MockAuthor), map[string]string{"body": "Thanks all"})
+	assert.Equal(t, http.StatusCreated, status, "the limit is per user")
+}
+
+func TestUpdateComment(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		commentID  string
+		wantStatus int
+		wantError  any
+	}{
+		{name: "Own comment within the edit window", user: mockData.MockReader, commentID: "1", wantStatus: http.StatusOK},
+		{
+			name:       "Own comment after the edit window",
+			user:       mockData.MockAuthor,
+			commentID:  "3",
+			wantStatus: http.StatusForbidden,
+			wantError:  "comments can only be edited within 10m0s of posting",
+		},
+		{name: "Someone else's comment", user: mockData.MockAuthor, commentID: "1", wantStatus: http.StatusNotFound},
+		{name: "Deleted comment", user: mockData.MockAdmin, commentID: "5", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, comments := newCommentTestApplication(t, false)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, body := ts.request(t, http.MethodPatch, "/v1/comments/"+tt.commentID, newTestToken(t, app, tt.user), map[string]string{"body": "  Edited  "})
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				comment, _ := comments.Get(1)
+				assert.Equal(t, "Edited", comment.Body)
+				assert.NotNil(t, comment.EditedAt)
+			}
+		})
+	}
+}
+
+func TestModerateComment(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		editor     bool
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "Author deletes own comment", user: mockData.MockReader, method: http.MethodDelete, path: "/v1/comments/1", wantStatus: http.StatusOK},
+		{name: "Reader deletes someone else's comment", user: mockData.MockReader, method: http.MethodDelete, path: "/v1/comments/3", wantStatus: http.StatusForbidden},
+		{name: "Editor deletes someone else's comment", user: mockData.MockReader, editor: true, method: http.MethodDelete, path: "/v1/comments/3", wantStatus: http.StatusOK},
+		{name: "Book owner deletes a reader's comment", user: mockData.MockAuthor, method: http.MethodDelete, path: "/v1/comments/1", wantStatus: http.StatusOK},
+		{name: "Admin deletes a comment", user: mockData.MockAdmin, method: http.MethodDelete, path: "/v1/comments/1", wantStatus: http.StatusOK},
+		{name: "Delete twice", user: mockData.MockAdmin, method: http.MethodDelete, path: "/v1/comments/5", wantStatus: http.StatusNotFound},
+		{name: "Reader pins own comment", user: mockData.MockReader, method: http.MethodPost, path: "/v1/comments/1/pin", wantStatus: http.StatusForbidden},
+		{name: "Editor pins a comment", user: mockData.MockReader, editor: true, method: http.MethodPost, path: "/v1/comments/1/pin", wantStatus: http.StatusOK},
+		{name: "Book owner pins a comment", user: mockData.MockAuthor, method: http.MethodPost, path: "/v1/comments/1/pin", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newCommentTestApplication(t, tt.editor)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, _ := ts.request(t, tt.method, tt.path, newTestToken(t, app, tt.user), nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestCommentThread(t *testing.T) {
+	app, _ := newCommentTestApplication(t, false)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	owner := newTestToken(t, app, mockData.MockAuthor)
+
+	status, _, _ := ts.request(t, http.MethodPost, "/v1/comments/3/pin", owner, nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _, _ = ts.request(t, http.MethodDelete, "/v1/comments/1", owner, nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _, body := ts.request(t, http.MethodGet, "/v1/chapters/1/comments", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	pinned, _ := body["pinned"].([]any)
+
+	if assert.Len(t, pinned, 1) {
+		assert.Equal(t, float64(3), pinned[0].(map[string]any)["id"])
+	}
+
+	topLevel, _ := body["data"].([]any)
+
+	if assert.Len(t, topLevel, 3) {
+		deleted := topLevel[0].(map[string]any)
+		assert.Equal(t, true, deleted["deleted"], "a deleted comment stays in the thread as a placeholder")
+		assert.Empty(t, deleted["body"])
+	}
+
+	status, _, body = ts.request(t, http.MethodGet, "/v1/comments/1/replies", "", nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	replies, _ := body["data"].([]any)
+
+	if assert.Len(t, replies, 1, "replies survive their parent's deletion") {
+		assert.Equal(t, "Thank you!", replies[0].(map[string]any)["body"])
+	}
+
+	status, _, _ = ts.request(t, http.MethodPost, "/v1/comments/3/unpin", owner, nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	_, _, body = ts.request(t, http.MethodGet, "/v1/chapters/1/comments", "", nil)
+	assert.Empty(t, body["pinned"])
+}
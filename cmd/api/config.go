@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/joho/godotenv"
@@ -37,6 +38,28 @@ func loadConfig(cfg *config) {
 	flag.StringVar(&cfg.googleOauth.clientID, "oauth-client-id", os.Getenv("GOOGLE_OAUTH_CLIENT_ID"), "Google oauth client id")
 	flag.StringVar(&cfg.googleOauth.clientSecret, "oauth-client-secret", os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET"), "Google oauth client secret")
 
+	flag.StringVar(&cfg.payments.provider, "payments-provider", os.Getenv("PAYMENTS_PROVIDER"), "Payment provider (fake, development and test only)")
+	flag.StringVar(&cfg.payments.webhookSecret, "payments-webhook-secret", os.Getenv("PAYMENTS_WEBHOOK_SECRET"), "Payment provider webhook signing secret")
+
+	flag.DurationVar(&cfg.orders.pendingTTL, "order-pending-ttl", getDurationEnv("ORDER_PENDING_TTL", time.Hour), "How long an unpaid order is kept before it is cancelled")
+	flag.DurationVar(&cfg.orders.expireInterval, "order-expire-interval", getDurationEnv("ORDER_EXPIRE_INTERVAL", 5*time.Minute), "How often unpaid orders past their TTL are cancelled")
+
+	flag.DurationVar(&cfg.trash.retention, "trash-retention", getDurationEnv("TRASH_RETENTION", 30*24*time.Hour), "How long deleted books and chapters stay in the trash")
+	flag.DurationVar(&cfg.trash.purgeInterval, "trash-purge-interval", getDurationEnv("TRASH_PURGE_INTERVAL", time.Hour), "How often expired trash is purged")
+
+	flag.DurationVar(&cfg.subscriptions.gracePeriod, "subscription-grace-period", getDurationEnv("SUBSCRIPTION_GRACE_PERIOD", 72*time.Hour), "How long subscribers keep access after a failed renewal")
+
+	flag.DurationVar(&cfg.comments.editWindow, "comment-edit-window", getDurationEnv("COMMENT_EDIT_WINDOW", 15*time.Minute), "How long authors can edit their comments after posting")
+	flag.IntVar(&cfg.comments.rateLimit, "comment-rate-limit", getIntEnv("COMMENT_RATE_LIMIT", 5), "Maximum comments a user can post per rate window")
+	flag.DurationVar(&cfg.comments.rateWindow, "comment-rate-window", getDurationEnv("COMMENT_RATE_WINDOW", time.Minute), "Window the comment rate limit applies to")
+
+	flag.IntVar(&cfg.revisions.keep, "revision-keep", getIntEnv("REVISION_KEEP", 20), "Newest chapter revisions always kept regardless of age")
+	flag.DurationVar(&cfg.revisions.retention, "revision-retention", getDurationEnv("REVISION_RETENTION", 90*24*time.Hour), "How long older chapter revisions are kept")
+	flag.DurationVar(&cfg.revisions.pruneInterval, "revision-prune-interval", getDurationEnv("REVISION_PRUNE_INTERVAL", time.Hour), "How often expired chapter revisions are pruned")
+
+	flag.DurationVar(&cfg.autosaves.ttl, "autosave-ttl", getDurationEnv("AUTOSAVE_TTL", 7*24*time.Hour), "How long an untouched chapter autosave is kept")
+	flag.DurationVar(&cfg.autosaves.purgeInterval, "autosave-purge-interval", getDurationEnv("AUTOSAVE_PURGE_INTERVAL", time.Hour), "How often stale chapter autosaves are discarded")
+
 	flag.Parse()
 }
 
@@ -55,3 +78,19 @@ func getIntEnv(key string, defaultValue int) int {
 
 	return value
 }
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	valueStr := os.Getenv(key)
+
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := time.ParseDuration(valueStr)
+	if err != nil {
+		hclog.Default().Error("Invalid environment value detected: ", valueStr)
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// CreateCoupon godoc
+// @Summary Create Coupon
+// @Description Create a percentage or fixed-amount coupon. Book owners can create coupons for their own books; store-wide coupons are admin only.
+// @Tags Coupons
+// @Param request body CreateCouponBody true "Coupon data to create"
+// @Produce  json
+// @Success 201 {object} CouponResponse "Created coupon successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/coupons [post]
+func (app *application) createCouponHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	var input struct {
+		Code           string     `json:"code"`
+		Kind           string     `json:"kind"`
+		Amount         int64      `json:"amount"`
+		Currency       string     `json:"currency"`
+		BookID         *int64     `json:"bookId"`
+		MaxUses        *int       `json:"maxUses"`
+		MaxUsesPerUser *int       `json:"maxUsesPerUser"`
+		StartsAt       *time.Time `json:"startsAt"`
+		EndsAt         *time.Time `json:"endsAt"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	coupon := &data.Coupon{
+		Code:           input.Code,
+		Kind:           input.Kind,
+		Amount:         input.Amount,
+		Currency:       input.Currency,
+		BookID:         input.BookID,
+		CreatedBy:      user.ID,
+		MaxUses:        input.MaxUses,
+		MaxUsesPerUser: input.MaxUsesPerUser,
+		StartsAt:       time.Now(),
+		EndsAt:         input.EndsAt,
+	}
+
+	if input.StartsAt != nil {
+		coupon.StartsAt = *input.StartsAt
+	}
+
+	v := validator.New()
+
+	if coupon.BookID == nil {
+		if !user.IsAdmin {
+			app.notPermittedResponse(w, r)
+			return
+		}
+
+		if coupon.Kind == data.CouponFixed && coupon.Currency == "" {
+			coupon.Currency = data.DefaultCurrency
+		}
+	} else {
+		book, err := app.models.Books.Get(*coupon.BookID)
+
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				v.AddError("bookId", "book not found")
+				app.failedValidationResponse(w, r, v.Errors)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+
+			return
+		}
+
+		permitted, err := app.hasBookRole(user, book, data.RoleOwner)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if !permitted {
+			app.notPermittedResponse(w, r)
+			return
+		}
+
+		if coupon.Kind == data.CouponFixed {
+			if coupon.Currency == "" {
+				coupon.Currency = book.Currency
+			}
+
+			v.Check(coupon.Currency == book.Currency, "currency", "must match the book's currency")
+		}
+	}
+
+	if coupon.Kind == data.CouponPercent {
+		coupon.Currency = ""
+	}
+
+	if data.ValidateCoupon(v, coupon); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Coupons.Insert(coupon)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateCouponCode):
+			v.AddError("code", "a coupon with this code already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/coupons/%d", coupon.ID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": coupon}, headers)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetCoupons godoc
+// @Summary Get my coupons
+// @Description Get the coupons created by the current user with their paid redemptions and total discount given
+// @Tags Coupons
+// @Produce  json
+// @Success 200 {object} GetCouponsResponse "Fetched coupons successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Router /v1/coupons [get]
+func (app *application) getCouponsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	coupons, err := app.models.Coupons.GetByCreator(user.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": coupons}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetCouponById godoc
+// @Summary Get Coupon By ID
+// @Description Get a coupon with its paid redemptions and total discount given. Only its creator and admins can see it.
+// @Tags Coupons
+// @Produce  json
+// @Param id path int true "Coupon ID"
+// @Success 200 {object} CouponResponse "Fetched coupon successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Coupon not found"
+// @Router /v1/coupons/{id} [get]
+func (app *application) getCouponByIDHandler(w http.ResponseWriter, r *http.Request) {
+	coupon, ok := app.readOwnCoupon(w, r)
+
+	if !ok {
+		return
+	}
+
+	err := app.writeJSON(w, http.StatusOK, envelope{"data": coupon}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// DeleteCoupon godoc
+// @Summary Delete Coupon
+// @Description Delete a coupon. Orders that already used it keep their discount.
+// @Tags Coupons
+// @Produce  json
+// @Param id path int true "Coupon ID"
+// @Success 200 {object} DeleteSuccessResponse "Deleted coupon successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Coupon not found"
+// @Router /v1/coupons/{id} [delete]
+func (app *application) deleteCouponHandler(w http.ResponseWriter, r *http.Request) {
+	coupon, ok := app.readOwnCoupon(w, r)
+
+	if !ok {
+		return
+	}
+
+	err := app.models.Coupons.Delete(coupon.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "coupon deleted"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// readOwnCoupon loads the coupon named by the id parameter if the current
+// user created it or is an admin, and writes a not found response otherwise.
+func (app *application) readOwnCoupon(w http.ResponseWriter, r *http.Request) (*data.Coupon, bool) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	coupon, err := app.models.Coupons.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return nil, false
+	}
+
+	if coupon.CreatedBy != user.ID && !user.IsAdmin {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	return coupon, true
+}
@@ -7,11 +7,13 @@ import (
 // Responses TYPE DTOS
 
 type MetadataDto struct {
-	CurrentPage  int `json:"currentPage,omitempty"`
-	PageSize     int `json:"pageSize,omitempty"`
-	FirstPage    int `json:"firstPage,omitempty"`
-	LastPage     int `json:"lastPage,omitempty"`
-	TotalRecords int `json:"totalRecords,omitempty"`
+	CurrentPage  int    `json:"currentPage,omitempty"`
+	PageSize     int    `json:"pageSize,omitempty"`
+	FirstPage    int    `json:"firstPage,omitempty"`
+	LastPage     int    `json:"lastPage,omitempty"`
+	TotalRecords int    `json:"totalRecords,omitempty"`
+	NextCursor   string `json:"nextCursor,omitempty"`
+	PrevCursor   string `json:"prevCursor,omitempty"`
 }
 
 type UserResponseDTO struct {
@@ -24,26 +26,384 @@ type UserResponseDTO struct {
 }
 
 type BookResponseDTO struct {
-	ID           int64      `json:"id"`
-	Title        string     `json:"string"`
-	UserID       int64      `json:"userId"`
-	CoverPicture string     `json:"coverPicture"`
-	CreatedAt    time.Time  `json:"createdAt"`
-	IsPublished  bool       `json:"isPublished"`
-	PublishedAt  *time.Time `json:"publishedAt"`
+	ID             int64          `json:"id"`
+	Title          string         `json:"string"`
+	Description    string         `json:"description"`
+	UserID         int64          `json:"userId"`
+	CoverPicture   string         `json:"coverPicture"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	IsPublished    bool           `json:"isPublished"`
+	PublishedAt    *time.Time     `json:"publishedAt"`
+	Price          int64          `json:"price"`
+	Currency       string         `json:"currency"`
+	RatingAverage  float64        `json:"ratingAverage"`
+	RatingCount    int            `json:"ratingCount"`
+	WordCount      int            `json:"wordCount"`
+	ReadingMinutes int            `json:"readingMinutes"`
+	DeletedAt      *time.Time     `json:"deletedAt,omitempty"`
+	Series         *BookSeriesDTO `json:"series,omitempty"`
+}
+
+type SeriesVolumeDTO struct {
+	BookID int64  `json:"bookId"`
+	Title  string `json:"title"`
+	Href   string `json:"href"`
+}
+
+type BookSeriesDTO struct {
+	ID       int64            `json:"id"`
+	Title    string           `json:"title"`
+	Position int              `json:"position"`
+	Previous *SeriesVolumeDTO `json:"previous"`
+	Next     *SeriesVolumeDTO `json:"next"`
+}
+
+type SeriesDTO struct {
+	ID          int64             `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	UserID      int64             `json:"userId"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	Books       []BookResponseDTO `json:"books"`
 }
 
 type ChapterResponseDTO struct {
-	ID          int64     `json:"id"`
-	ChapterNo   int64     `json:"chapterNo"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Content     *string   `json:"content"`
-	BookID      int64     `json:"bookId"`
-	UserID      int64     `json:"userId"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
-	Version     int       `json:"-"`
+	ID             int64      `json:"id"`
+	ChapterNo      int64      `json:"chapterNo"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Content        *string    `json:"content"`
+	ContentFormat  string     `json:"contentFormat" enums:"markdown,text"`
+	WordCount      int        `json:"wordCount"`
+	ReadingMinutes int        `json:"readingMinutes"`
+	Access         string     `json:"access" enums:"free,premium"`
+	Locked         bool       `json:"locked,omitempty"`
+	IsPublished    bool       `json:"isPublished"`
+	PublishedAt    *time.Time `json:"publishedAt"`
+	BookID         int64      `json:"bookId"`
+	UserID         int64      `json:"userId"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	DeletedAt      *time.Time `json:"deletedAt,omitempty"`
+	Version        int        `json:"version"`
+}
+
+type ChapterSummaryDTO struct {
+	ID             int64      `json:"id"`
+	ChapterNo      int64      `json:"chapterNo"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	WordCount      int        `json:"wordCount"`
+	ReadingMinutes int        `json:"readingMinutes"`
+	Access         string     `json:"access" enums:"free,premium"`
+	IsPublished    bool       `json:"isPublished"`
+	PublishedAt    *time.Time `json:"publishedAt"`
+}
+
+type SearchResultDTO struct {
+	Type     string  `json:"type"`
+	ID       int64   `json:"id"`
+	BookID   int64   `json:"bookId"`
+	Title    string  `json:"title"`
+	Headline string  `json:"headline"`
+	Rank     float64 `json:"rank"`
+}
+
+type SuggestionDTO struct {
+	Type  string  `json:"type"`
+	ID    int64   `json:"id"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+type CollaboratorDTO struct {
+	BookID      int64      `json:"bookId"`
+	UserID      int64      `json:"userId"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	Status      string     `json:"status"`
+	InvitedBy   int64      `json:"invitedBy"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	RespondedAt *time.Time `json:"respondedAt"`
+}
+
+type CartItemDTO struct {
+	BookID   int64     `json:"bookId"`
+	Title    string    `json:"title"`
+	Price    int64     `json:"price"`
+	Currency string    `json:"currency"`
+	AddedAt  time.Time `json:"addedAt"`
+}
+
+type OrderItemDTO struct {
+	BookID   *int64 `json:"bookId"`
+	Title    string `json:"title"`
+	Price    int64  `json:"price"`
+	Discount int64  `json:"discount"`
+}
+
+type OrderDTO struct {
+	ID              int64          `json:"id"`
+	UserID          int64          `json:"userId"`
+	Status          string         `json:"status" enums:"pending,paid,refunded,cancelled"`
+	Total           int64          `json:"total"`
+	Discount        int64          `json:"discount"`
+	Currency        string         `json:"currency"`
+	CouponID        *int64         `json:"couponId,omitempty"`
+	PaymentProvider string         `json:"paymentProvider,omitempty"`
+	Items           []OrderItemDTO `json:"items,omitempty"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+}
+
+type EntitlementDTO struct {
+	UserID    int64     `json:"userId"`
+	BookID    int64     `json:"bookId"`
+	Source    string    `json:"source" enums:"purchase,grant"`
+	OrderID   *int64    `json:"orderId,omitempty"`
+	GrantedBy *int64    `json:"grantedBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type PlanDTO struct {
+	ID             int64     `json:"id"`
+	Name           string    `json:"name"`
+	Price          int64     `json:"price"`
+	Currency       string    `json:"currency"`
+	IntervalMonths int       `json:"intervalMonths"`
+	IsActive       bool      `json:"isActive"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type SubscriptionDTO struct {
+	ID                 int64      `json:"id"`
+	UserID             int64      `json:"userId"`
+	PlanID             int64      `json:"planId"`
+	Status             string     `json:"status" enums:"incomplete,active,past_due,cancelled,expired"`
+	CurrentPeriodStart *time.Time `json:"currentPeriodStart"`
+	CurrentPeriodEnd   *time.Time `json:"currentPeriodEnd"`
+	GraceUntil         *time.Time `json:"graceUntil,omitempty"`
+	PaymentProvider    string     `json:"paymentProvider,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+}
+
+type CouponDTO struct {
+	ID             int64      `json:"id"`
+	Code           string     `json:"code"`
+	Kind           string     `json:"kind" enums:"percent,fixed"`
+	Amount         int64      `json:"amount"`
+	Currency       string     `json:"currency,omitempty"`
+	BookID         *int64     `json:"bookId"`
+	CreatedBy      int64      `json:"createdBy"`
+	MaxUses        *int       `json:"maxUses"`
+	MaxUsesPerUser *int       `json:"maxUsesPerUser"`
+	StartsAt       time.Time  `json:"startsAt"`
+	EndsAt         *time.Time `json:"endsAt"`
+	Redemptions    int        `json:"redemptions"`
+	TotalDiscount  int64      `json:"totalDiscount"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+type BookEarningsDTO struct {
+	BookID   *int64 `json:"bookId"`
+	Title    string `json:"title"`
+	Currency string `json:"currency"`
+	Sales    int    `json:"sales"`
+	Earned   int64  `json:"earned"`
+	Refunded int64  `json:"refunded"`
+	Net      int64  `json:"net"`
+}
+
+type PeriodEarningsDTO struct {
+	Period   time.Time `json:"period"`
+	Currency string    `json:"currency"`
+	Sales    int       `json:"sales"`
+	Earned   int64     `json:"earned"`
+	Refunded int64     `json:"refunded"`
+	Net      int64     `json:"net"`
+}
+
+type BalanceDTO struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+}
+
+type EarningsDTO struct {
+	ByBook   []BookEarningsDTO   `json:"byBook"`
+	ByPeriod []PeriodEarningsDTO `json:"byPeriod"`
+	Unpaid   []BalanceDTO        `json:"unpaid"`
+}
+
+type PayoutStatementLineDTO struct {
+	BookID *int64 `json:"bookId"`
+	Title  string `json:"title"`
+	Amount int64  `json:"amount"`
+}
+
+type PayoutStatementDTO struct {
+	ID             int64                    `json:"id"`
+	PayoutPeriodID int64                    `json:"payoutPeriodId"`
+	UserID         int64                    `json:"userId"`
+	Currency       string                   `json:"currency"`
+	Amount         int64                    `json:"amount"`
+	CreatedAt      time.Time                `json:"createdAt"`
+	Lines          []PayoutStatementLineDTO `json:"lines,omitempty"`
+}
+
+type PayoutPeriodDTO struct {
+	ID          int64                `json:"id"`
+	PeriodStart *time.Time           `json:"periodStart"`
+	PeriodEnd   time.Time            `json:"periodEnd"`
+	ClosedBy    *int64               `json:"closedBy"`
+	ClosedAt    time.Time            `json:"closedAt"`
+	Statements  []PayoutStatementDTO `json:"statements"`
+}
+
+type ReviewDTO struct {
+	ID        int64     `json:"id"`
+	BookID    int64     `json:"bookId"`
+	UserID    int64     `json:"userId"`
+	UserName  string    `json:"userName"`
+	Rating    int       `json:"rating"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CommentDTO struct {
+	ID         int64      `json:"id"`
+	ChapterID  int64      `json:"chapterId"`
+	ParentID   *int64     `json:"parentId"`
+	UserID     int64      `json:"userId"`
+	UserName   string     `json:"userName"`
+	Body       string     `json:"body"`
+	ReplyCount int        `json:"replyCount"`
+	Pinned     bool       `json:"pinned"`
+	Deleted    bool       `json:"deleted"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	EditedAt   *time.Time `json:"editedAt"`
+}
+
+type ShelfDTO struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"userId"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind" enums:"want-to-read,currently-reading,finished,custom"`
+	IsPublic  bool      `json:"isPublic"`
+	BookCount int       `json:"bookCount"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ShelfBookDTO struct {
+	Book    BookResponseDTO `json:"book"`
+	AddedAt time.Time       `json:"addedAt"`
+}
+
+type ReadingProgressDTO struct {
+	BookID    int64     `json:"bookId"`
+	ChapterID int64     `json:"chapterId"`
+	Position  *int      `json:"position"`
+	Percent   *float64  `json:"percent"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ChapterRefDTO struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	ChapterNo int64  `json:"chapterNo"`
+}
+
+type ContinueReadingDTO struct {
+	BookID       int64          `json:"bookId"`
+	Title        string         `json:"title"`
+	CoverPicture string         `json:"coverPicture"`
+	Chapter      ChapterRefDTO  `json:"chapter"`
+	NextChapter  *ChapterRefDTO `json:"nextChapter"`
+	Position     *int           `json:"position"`
+	Percent      *float64       `json:"percent"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+}
+
+type AnnotationDTO struct {
+	ID           int64     `json:"id"`
+	UserID       int64     `json:"userId"`
+	BookID       int64     `json:"bookId"`
+	ChapterID    int64     `json:"chapterId"`
+	ChapterTitle string    `json:"chapterTitle,omitempty"`
+	Kind         string    `json:"kind" enums:"highlight,bookmark,note"`
+	StartOffset  int       `json:"startOffset"`
+	EndOffset    int       `json:"endOffset"`
+	Quote        string    `json:"quote"`
+	Note         string    `json:"note"`
+	Color        string    `json:"color"`
+	Orphaned     bool      `json:"orphaned"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type ChapterRevisionDTO struct {
+	ID            int64     `json:"id"`
+	ChapterID     int64     `json:"chapterId"`
+	Revision      int       `json:"revision"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	Content       string    `json:"content,omitempty"`
+	ContentFormat string    `json:"contentFormat" enums:"markdown,text"`
+	Access        string    `json:"access" enums:"free,premium"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type DiffOpDTO struct {
+	Op   string `json:"op" enums:"equal,insert,delete"`
+	Text string `json:"text"`
+}
+
+type RevisionDiffDTO struct {
+	From    int         `json:"from"`
+	To      int         `json:"to"`
+	Format  string      `json:"format" enums:"unified,words"`
+	Unified string      `json:"unified,omitempty"`
+	Ops     []DiffOpDTO `json:"ops,omitempty"`
+}
+
+type MergeConflictDTO struct {
+	Field    string `json:"field"`
+	BaseLine int    `json:"baseLine,omitempty"`
+	Base     string `json:"base"`
+	Yours    string `json:"yours"`
+	Current  string `json:"current"`
+}
+
+type MergeConflictErrorDTO struct {
+	Message        string             `json:"message"`
+	CurrentVersion int                `json:"currentVersion"`
+	Conflicts      []MergeConflictDTO `json:"conflicts"`
+}
+
+type AutosaveDTO struct {
+	ChapterID     int64     `json:"chapterId"`
+	BaseVersion   int       `json:"baseVersion"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	Content       string    `json:"content"`
+	ContentFormat string    `json:"contentFormat" enums:"markdown,text"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type ChapterOpResultDTO struct {
+	Index   int                 `json:"index"`
+	Op      string              `json:"op" enums:"create,update,delete"`
+	Status  string              `json:"status" enums:"created,updated,deleted,failed,rolledBack"`
+	ID      int64               `json:"id,omitempty"`
+	Chapter *ChapterResponseDTO `json:"chapter,omitempty"`
+	Errors  map[string]string   `json:"errors,omitempty"`
+}
+
+type BatchChaptersErrorDTO struct {
+	Message string               `json:"message"`
+	Results []ChapterOpResultDTO `json:"results"`
 }
 
 // Requests Parts
@@ -65,25 +425,177 @@ type LoginRequestBody struct {
 
 type CreateBookBody struct {
 	Title        string `json:"title"`
+	Description  string `json:"description"`
 	CoverPicture string `json:"coverPicture"`
+	Price        int64  `json:"price"`
+	Currency     string `json:"currency" enums:"USD,EUR,GBP,SGD,THB"`
 }
 
 type UpdateBookBody struct {
 	Title        string     `json:"title"`
+	Description  string     `json:"description"`
 	CoverPicture string     `json:"coverPicture"`
 	IsPublished  bool       `json:"isPublished"`
 	PublishedAt  *time.Time `json:"publishedAt"`
+	Price        *int64     `json:"price"`
+	Currency     *string    `json:"currency" enums:"USD,EUR,GBP,SGD,THB"`
 }
 
 type CreateChapterBody struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	BookID      string `json:"bookId"`
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	BookID        string `json:"bookId"`
+	Access        string `json:"access" enums:"free,premium"`
+	ContentFormat string `json:"contentFormat" enums:"markdown,text"`
 }
 type UpdateChapterBody struct {
+	Title         string `json:"title"`
+	Description   string `json:"coverPicture"`
+	Content       string `json:"content"`
+	ContentFormat string `json:"contentFormat" enums:"markdown,text"`
+	Access        string `json:"access" enums:"free,premium"`
+	BaseVersion   int    `json:"baseVersion"`
+}
+
+type SaveAutosaveBody struct {
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	Content       string `json:"content"`
+	ContentFormat string `json:"contentFormat" enums:"markdown,text"`
+	BaseVersion   int    `json:"baseVersion"`
+}
+
+type ChapterOpBody struct {
+	Op            string `json:"op" enums:"create,update,delete"`
+	ID            int64  `json:"id"`
+	Version       int    `json:"version"`
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	Content       string `json:"content"`
+	ContentFormat string `json:"contentFormat" enums:"markdown,text"`
+	Access        string `json:"access" enums:"free,premium"`
+}
+
+type BatchChaptersBody struct {
+	Operations []ChapterOpBody `json:"operations"`
+}
+
+type ReorderChaptersBody struct {
+	ChapterIDs []int64 `json:"chapterIds"`
+}
+
+type InviteCollaboratorBody struct {
+	Email string `json:"email"`
+	Role  string `json:"role" enums:"owner,editor,viewer"`
+}
+
+type CreateSeriesBody struct {
 	Title       string `json:"title"`
-	Description string `json:"coverPicture"`
-	Content     string `json:"content"`
+	Description string `json:"description"`
+}
+
+type SetSeriesBooksBody struct {
+	BookIDs []int64 `json:"bookIds"`
+}
+
+type GrantEntitlementBody struct {
+	UserID int64 `json:"userId"`
+}
+
+type CreatePlanBody struct {
+	Name           string `json:"name"`
+	Price          int64  `json:"price"`
+	Currency       string `json:"currency" enums:"USD,EUR,GBP,SGD,THB"`
+	IntervalMonths int    `json:"intervalMonths"`
+}
+
+type SubscribeBody struct {
+	PlanID int64 `json:"planId"`
+}
+
+type SubscriptionParticipationBody struct {
+	Participating bool `json:"participating"`
+}
+
+type CheckoutBody struct {
+	CouponCode string `json:"couponCode"`
+}
+
+type CreateCouponBody struct {
+	Code           string     `json:"code"`
+	Kind           string     `json:"kind" enums:"percent,fixed"`
+	Amount         int64      `json:"amount"`
+	Currency       string     `json:"currency" enums:"USD,EUR,GBP,SGD,THB"`
+	BookID         *int64     `json:"bookId"`
+	MaxUses        *int       `json:"maxUses"`
+	MaxUsesPerUser *int       `json:"maxUsesPerUser"`
+	StartsAt       *time.Time `json:"startsAt"`
+	EndsAt         *time.Time `json:"endsAt"`
+}
+
+type ClosePayoutPeriodBody struct {
+	PeriodEnd time.Time `json:"periodEnd"`
+}
+
+type CreateReviewBody struct {
+	Rating int    `json:"rating" minimum:"1" maximum:"5"`
+	Body   string `json:"body"`
+}
+
+type UpdateReviewBody struct {
+	Rating *int    `json:"rating" minimum:"1" maximum:"5"`
+	Body   *string `json:"body"`
+}
+
+type CreateCommentBody struct {
+	Body     string `json:"body"`
+	ParentID *int64 `json:"parentId"`
+}
+
+type UpdateCommentBody struct {
+	Body string `json:"body"`
+}
+
+type CreateShelfBody struct {
+	Name     string `json:"name"`
+	IsPublic bool   `json:"isPublic"`
+}
+
+type UpdateShelfBody struct {
+	Name     *string `json:"name"`
+	IsPublic *bool   `json:"isPublic"`
+}
+
+type AddShelfBookBody struct {
+	BookID int64 `json:"bookId"`
+}
+
+type MoveShelfBookBody struct {
+	ShelfID int64 `json:"shelfId"`
+}
+
+type SaveProgressBody struct {
+	ChapterID int64      `json:"chapterId"`
+	Position  *int       `json:"position"`
+	Percent   *float64   `json:"percent" minimum:"0" maximum:"100"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+}
+
+type CreateAnnotationBody struct {
+	Kind        string `json:"kind" enums:"highlight,bookmark,note"`
+	StartOffset int    `json:"startOffset"`
+	EndOffset   int    `json:"endOffset"`
+	Note        string `json:"note"`
+	Color       string `json:"color" enums:"yellow,green,blue,pink,purple"`
+}
+
+type UpdateAnnotationBody struct {
+	Note  *string `json:"note"`
+	Color *string `json:"color" enums:"yellow,green,blue,pink,purple"`
+}
+
+type AddCartItemBody struct {
+	BookID int64 `json:"bookId"`
 }
 
 // Responses
@@ -134,9 +646,208 @@ type DeleteSuccessResponse struct {
 }
 
 type GetChaptersResponse struct {
-	Data []ChapterResponseDTO `json:"data"`
+	Data     []ChapterResponseDTO `json:"data"`
+	Metadata MetadataDto          `json:"metadata"`
+}
+
+type GetTOCResponse struct {
+	Data     []ChapterSummaryDTO `json:"data"`
+	Metadata MetadataDto         `json:"metadata"`
 }
 
 type GetChapterResponse struct {
 	Data ChapterResponseDTO `json:"data"`
 }
+
+type SearchResponse struct {
+	Data     []SearchResultDTO `json:"data"`
+	Metadata MetadataDto       `json:"metadata"`
+}
+
+type SuggestResponse struct {
+	Data []SuggestionDTO `json:"data"`
+}
+
+type TrashDTO struct {
+	Books    []BookResponseDTO    `json:"books"`
+	Chapters []ChapterResponseDTO `json:"chapters"`
+}
+
+type TrashResponse struct {
+	Data TrashDTO `json:"data"`
+}
+
+type GetCollaboratorsResponse struct {
+	Data []CollaboratorDTO `json:"data"`
+}
+
+type CollaboratorResponse struct {
+	Data CollaboratorDTO `json:"data"`
+}
+
+type SeriesResponse struct {
+	Data SeriesDTO `json:"data"`
+}
+
+type CartResponse struct {
+	Data []CartItemDTO `json:"data"`
+}
+
+type CheckoutResponse struct {
+	Data        OrderDTO `json:"data"`
+	CheckoutURL string   `json:"checkoutUrl,omitempty"`
+}
+
+type GetOrdersResponse struct {
+	Data     []OrderDTO  `json:"data"`
+	Metadata MetadataDto `json:"metadata"`
+}
+
+type OrderResponse struct {
+	Data OrderDTO `json:"data"`
+}
+
+type GetEntitlementsResponse struct {
+	Data []EntitlementDTO `json:"data"`
+}
+
+type EntitlementResponse struct {
+	Data EntitlementDTO `json:"data"`
+}
+
+type GetPlansResponse struct {
+	Data []PlanDTO `json:"data"`
+}
+
+type PlanResponse struct {
+	Data PlanDTO `json:"data"`
+}
+
+type SubscriptionResponse struct {
+	Data SubscriptionDTO `json:"data"`
+}
+
+type SubscribeResponse struct {
+	Data        SubscriptionDTO `json:"data"`
+	CheckoutURL string          `json:"checkoutUrl"`
+}
+
+type SubscriptionParticipationResponse struct {
+	Data struct {
+		Participating bool `json:"participating"`
+	} `json:"data"`
+}
+
+type GetCouponsResponse struct {
+	Data []CouponDTO `json:"data"`
+}
+
+type CouponResponse struct {
+	Data CouponDTO `json:"data"`
+}
+
+type EarningsResponse struct {
+	Data EarningsDTO `json:"data"`
+}
+
+type GetPayoutStatementsResponse struct {
+	Data []PayoutStatementDTO `json:"data"`
+}
+
+type PayoutPeriodResponse struct {
+	Data PayoutPeriodDTO `json:"data"`
+}
+
+type GetReviewsResponse struct {
+	Data     []ReviewDTO `json:"data"`
+	Metadata MetadataDto `json:"metadata"`
+}
+
+type ReviewResponse struct {
+	Data ReviewDTO `json:"data"`
+}
+
+type GetCommentsResponse struct {
+	Data     []CommentDTO `json:"data"`
+	Pinned   []CommentDTO `json:"pinned,omitempty"`
+	Metadata MetadataDto  `json:"metadata"`
+}
+
+type GetCommentRepliesResponse struct {
+	Data     []CommentDTO `json:"data"`
+	Metadata MetadataDto  `json:"metadata"`
+}
+
+type CommentResponse struct {
+	Data CommentDTO `json:"data"`
+}
+
+type GetShelvesResponse struct {
+	Data []ShelfDTO `json:"data"`
+}
+
+type ShelfResponse struct {
+	Data ShelfDTO `json:"data"`
+}
+
+type GetShelfBooksResponse struct {
+	Data     []ShelfBookDTO `json:"data"`
+	Shelf    ShelfDTO       `json:"shelf"`
+	Metadata MetadataDto    `json:"metadata"`
+}
+
+type ProgressResponse struct {
+	Data ReadingProgressDTO `json:"data"`
+}
+
+type SaveProgressResponse struct {
+	Data    ReadingProgressDTO `json:"data"`
+	Applied bool               `json:"applied"`
+}
+
+type ContinueReadingResponse struct {
+	Data     []ContinueReadingDTO `json:"data"`
+	Metadata MetadataDto          `json:"metadata"`
+}
+
+type GetAnnotationsResponse struct {
+	Data []AnnotationDTO `json:"data"`
+}
+
+type AnnotationResponse struct {
+	Data AnnotationDTO `json:"data"`
+}
+
+type GetChapterRevisionsResponse struct {
+	Data     []ChapterRevisionDTO `json:"data"`
+	Metadata MetadataDto          `json:"metadata"`
+}
+
+type ChapterRevisionResponse struct {
+	Data ChapterRevisionDTO `json:"data"`
+}
+
+type RevisionDiffResponse struct {
+	Data RevisionDiffDTO `json:"data"`
+}
+
+type UpdateChapterResponse struct {
+	Data   ChapterResponseDTO `json:"data"`
+	Merged bool               `json:"merged"`
+}
+
+type MergeConflictResponse struct {
+	Error MergeConflictErrorDTO `json:"error"`
+}
+
+type AutosaveResponse struct {
+	Data AutosaveDTO `json:"data"`
+}
+
+type BatchChaptersResponse struct {
+	Data []ChapterOpResultDTO `json:"data"`
+}
+
+type BatchChaptersErrorResponse struct {
+	Error BatchChaptersErrorDTO `json:"error"`
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// readEarningsRange reads the from and to query parameters, defaulting to
+// the last twelve months.
+func (app *application) readEarningsRange(r *http.Request, v *validator.Validator) (time.Time, time.Time) {
+	qs := r.URL.Query()
+
+	to := app.readDate(qs, "to", time.Now(), v)
+	from := app.readDate(qs, "from", to.AddDate(-1, 0, 0), v)
+
+	return from, to
+}
+
+// GetEarnings godoc
+// @Summary Get my earnings
+// @Description Get the current user's royalties from book sales and refunds, broken down by book and by period, together with the balance not yet paid out. Amounts are in the currency's minor unit.
+// @Tags Earnings
+// @Produce  json
+// @Param        from    query     string  false  "Start date, inclusive (YYYY-MM-DD, default: a year before to)"
+// @Param        to      query     string  false  "End date, exclusive (YYYY-MM-DD, default: now)"
+// @Param        period  query     string  false  "Period breakdown (default: month)" Enums(day, week, month)
+// @Success 200 {object} EarningsResponse "Fetched earnings successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/me/earnings [get]
+func (app *application) getEarningsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	v := validator.New()
+
+	from, to := app.readEarningsRange(r, v)
+	period := app.readString(r.URL.Query(), "period", "month")
+
+	if data.ValidateEarningsQuery(v, from, to, period); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	earnings, err := app.models.Ledger.Earnings(user.ID, from, to, period)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": earnings}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// ExportEarnings godoc
+// @Summary Export my earnings
+// @Description Download the current user's individual royalty movements as CSV
+// @Tags Earnings
+// @Produce  text/csv
+// @Param        from    query     string  false  "Start date, inclusive (YYYY-MM-DD, default: a year before to)"
+// @Param        to      query     string  false  "End date, exclusive (YYYY-MM-DD, default: now)"
+// @Success 200 {string} string "CSV with date, kind, orderId, bookId, title, amount and currency columns"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/me/earnings/export [get]
+func (app *application) exportEarningsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	v := validator.New()
+
+	from, to := app.readEarningsRange(r, v)
+
+	if data.ValidateEarningsQuery(v, from, to, "month"); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	entries, err := app.models.Ledger.EarningsEntries(user.ID, from, to)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("earnings-%s-%s.csv", from.Format(time.DateOnly), to.Format(time.DateOnly))
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"date", "kind", "orderId", "bookId", "title", "amount", "currency"})
+
+	optionalID := func(id *int64) string {
+		if id == nil {
+			return ""
+		}
+
+		return strconv.FormatInt(*id, 10)
+	}
+
+	for _, entry := range entries {
+		cw.Write([]string{
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			entry.Kind,
+			optionalID(entry.OrderID),
+			optionalID(entry.BookID),
+			entry.Title,
+			strconv.FormatInt(entry.Amount, 10),
+			entry.Currency,
+		})
+	}
+
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		app.logError(r, err)
+	}
+}
+
+// GetMyPayouts godoc
+// @Summary Get my payout statements
+// @Description Get the payout statements issued to the current user, newest first
+// @Tags Earnings
+// @Produce  json
+// @Success 200 {object} GetPayoutStatementsResponse "Fetched payout statements successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Router /v1/me/payouts [get]
+func (app *application) getMyPayoutsHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	statements, err := app.models.Ledger.GetStatementsByUser(user.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": statements}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// ClosePayoutPeriod godoc
+// @Summary Close payout period
+// @Description Close the payout period ending at periodEnd and issue a payout statement to every author with a positive unpaid balance earned before it. Admin only.
+// @Tags Earnings
+// @Param request body ClosePayoutPeriodBody true "End of the period to close"
+// @Produce  json
+// @Success 201 {object} PayoutPeriodResponse "Closed payout period successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/payouts [post]
+func (app *application) closePayoutPeriodHandler(w http.ResponseWriter, r *http.Request) {
+	admin := app.contextGetUser(r)
+
+	var input struct {
+		PeriodEnd time.Time `json:"periodEnd"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if v.Check(!input.PeriodEnd.IsZero(), "periodEnd", "must be provided"); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	period, err := app.models.Ledger.ClosePayoutPeriod(input.PeriodEnd, admin.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidPayoutPeriod):
+			v.AddError("periodEnd", "must be after the end of the last closed period and not in the future")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/payouts/%d", period.ID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": period}, headers)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetPayoutPeriod godoc
+// @Summary Get payout period
+// @Description Get a closed payout period with its statements. Admin only.
+// @Tags Earnings
+// @Produce  json
+// @Param id path int true "Payout period ID"
+// @Success 200 {object} PayoutPeriodResponse "Fetched payout period successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Payout period not found"
+// @Router /v1/payouts/{id} [get]
+func (app *application) getPayoutPeriodHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	period, err := app.models.Ledger.GetPayoutPeriod(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": period}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
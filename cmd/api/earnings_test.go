@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newEarningsTestApplication returns a test application where MockAuthor
+// sold Dune twice in March 2026 and refunded one of the sales in April, and
+// MockReader sold a book of their own in March.
+func newEarningsTestApplication(t *testing.T) (*application, *mockData.LedgerModel) {
+	app := newTestApplication(t)
+
+	orderID, dune, emma := int64(7), int64(1), int64(2)
+
+	entry := func(userID int64, kind string, bookID *int64, title string, amount int64, at string) *mockData.LedgerEntry {
+		createdAt, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &mockData.LedgerEntry{
+			UserID: userID,
+			EarningsEntry: data.EarningsEntry{
+				CreatedAt: createdAt,
+				Kind:      kind,
+				OrderID:   &orderID,
+				BookID:    bookID,
+				Title:     title,
+				Amount:    amount,
+				Currency:  "USD",
+			},
+		}
+	}
+
+	ledger := &mockData.LedgerModel{
+		Entries: []*mockData.LedgerEntry{
+			entry(mockData.MockAuthor.ID, data.LedgerSale, &dune, "Dune", 699, "2026-03-02T10:00:00Z"),
+			entry(mockData.MockAuthor.ID, data.LedgerSale, &dune, "Dune", 699, "2026-03-20T10:00:00Z"),
+			entry(mockData.MockAuthor.ID, data.LedgerRefund, &dune, "Dune", -699, "2026-04-01T10:00:00Z"),
+			entry(mockData.MockReader.ID, data.LedgerSale, &emma, "Emma", 350, "2026-03-05T10:00:00Z"),
+		},
+		Statements: []*data.PayoutStatement{
+			{ID: 1, PayoutPeriodID: 1, UserID: mockData.MockAuthor.ID, Currency: "USD", Amount: 699},
+			{ID: 2, PayoutPeriodID: 1, UserID: mockData.MockReader.ID, Currency: "USD", Amount: 350},
+		},
+	}
+
+	app.models.Ledger = ledger
+
+	return app, ledger
+}
+
+// download sends an authenticated GET and returns the raw response body.
+func (ts *testServer) download(t *testing.T, urlPath, token string) (int, http.Header, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+urlPath, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	rs, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	defer rs.Body.Close()
+
+	body, err := io.ReadAll(rs.Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+
+	return rs.StatusCode, rs.Header, string(body)
+}
+
+func TestGetEarnings(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantError  map[string]any
+		wantNet    float64
+	}{
+		{name: "Sales and refund", query: "?from=2026-03-01&to=2026-05-01", wantStatus: http.StatusOK, wantNet: 699},
+		{name: "Before the refund", query: "?from=2026-03-01&to=2026-04-01&period=week", wantStatus: http.StatusOK, wantNet: 1398},
+		{
+			name:       "Unknown period",
+			query:      "?period=year",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"period": "must be day, week or month"},
+		},
+		{
+			name:       "Reversed range",
+			query:      "?from=2026-04-01&to=2026-03-01",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"to": "must be after from"},
+		},
+		{
+			name:       "Range too long",
+			query:      "?from=2019-01-01&to=2026-01-01",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"to": "range must not be longer than 5 years"},
+		},
+		{
+			name:       "Malformed date",
+			query:      "?from=March",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"from": "must be a date (YYYY-MM-DD) or an RFC 3339 timestamp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newEarningsTestApplication(t)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, body := ts.request(t, http.MethodGet, "/v1/me/earnings"+tt.query, newTestToken(t, app, mockData.MockAuthor), nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+				return
+			}
+
+			earnings, _ := body["data"].(map[string]any)
+			byBook, _ := earnings["byBook"].([]any)
+
+			if assert.Len(t, byBook, 1, "only the signed in author's books are reported") {
+				book, _ := byBook[0].(map[string]any)
+				assert.Equal(t, "Dune", book["title"])
+				assert.Equal(t, tt.wantNet, book["net"])
+			}
+		})
+	}
+}
+
+func TestExportEarnings(t *testing.T) {
+	app, _ := newEarningsTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	status, headers, body := ts.download(t, "/v1/me/earnings/export?from=2026-03-01&to=2026-05-01", newTestToken(t, app, mockData.MockAuthor))
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "text/csv; charset=utf-8", headers.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="earnings-2026-03-01-2026-05-01.csv"`, headers.Get("Content-Disposition"))
+
+	records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, [][]string{
+			{"date", "kind", "orderId", "bookId", "title", "amount", "currency"},
+			{"2026-03-02T10:00:00Z", "sale", "7", "1", "Dune", "699", "USD"},
+			{"2026-03-20T10:00:00Z", "sale", "7", "1", "Dune", "699", "USD"},
+			{"2026-04-01T10:00:00Z", "refund", "7", "1", "Dune", "-699", "USD"},
+		}, records)
+	}
+
+	status, _, _ = ts.download(t, "/v1/me/earnings/export?from=2026-05-01&to=2026-03-01", newTestToken(t, app, mockData.MockAuthor))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+}
+
+func TestGetMyPayouts(t *testing.T) {
+	app, _ := newEarningsTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	status, _, body := ts.request(t, http.MethodGet, "/v1/me/payouts", newTestToken(t, app, mockData.MockReader), nil)
+
+	assert.Equal(t, http.StatusOK, status)
+
+	statements, _ := body["data"].([]any)
+
+	if assert.Len(t, statements, 1) {
+		statement, _ := statements[0].(map[string]any)
+		assert.Equal(t, float64(mockData.MockReader.ID), statement["userId"])
+		assert.Equal(t, float64(350), statement["amount"])
+	}
+}
+
+func TestClosePayoutPeriod(t *testing.T) {
+	app, ledger := newEarningsTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	admin := newTestToken(t, app, mockData.MockAdmin)
+	endOfMarch := time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	status, _, _ := ts.request(t, http.MethodPost, "/v1/payouts", newTestToken(t, app, mockData.MockAuthor), map[string]any{"periodEnd": endOfMarch})
+	assert.Equal(t, http.StatusForbidden, status, "only admins close payout periods")
+	assert.Empty(t, ledger.Periods)
+
+	status, _, body := ts.request(t, http.MethodPost, "/v1/payouts", admin, map[string]any{})
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, map[string]any{"periodEnd": "must be provided"}, body["error"])
+
+	status, headers, _ := ts.request(t, http.MethodPost, "/v1/payouts", admin, map[string]any{"periodEnd": endOfMarch})
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "/v1/payouts/1", headers.Get("Location"))
+
+	if assert.Len(t, ledger.Periods, 1) {
+		assert.Equal(t, mockData.MockAdmin.ID, *ledger.Periods[0].ClosedBy)
+	}
+
+	for _, periodEnd := range []time.Time{endOfMarch, endOfMarch.AddDate(0, 0, -1), time.Now().Add(time.Hour)} {
+		status, _, body = ts.request(t, http.MethodPost, "/v1/payouts", admin, map[string]any{"periodEnd": periodEnd})
+		assert.Equal(t, http.StatusUnprocessableEntity, status, periodEnd)
+		assert.Equal(t, map[string]any{"periodEnd": "must be after the end of the last closed period and not in the future"}, body["error"])
+	}
+
+	status, _, body = ts.request(t, http.MethodGet, "/v1/payouts/1", admin, nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	period, _ := body["data"].(map[string]any)
+	assert.Equal(t, "2026-04-01T00:00:00Z", period["periodEnd"])
+
+	status, _, _ = ts.request(t, http.MethodGet, "/v1/payouts/2", admin, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
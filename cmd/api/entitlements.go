@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// GetEntitlements godoc
+// @Summary Get Book Entitlements
+// @Description List the users entitled to a book's premium chapters through a purchase or a grant. Admin only.
+// @Tags Entitlements
+// @Produce  json
+// @Param id path int true "Book ID"
+// @Success 200 {object} GetEntitlementsResponse "Fetched entitlements successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not found"
+// @Router /v1/books/{id}/entitlements [get]
+func (app *application) getEntitlementsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	entitlements, err := app.models.Entitlements.GetByBook(book.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": entitlements}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GrantEntitlement godoc
+// @Summary Grant Entitlement
+// @Description Give a user free access to a book's premium chapters. Admin only.
+// @Tags Entitlements
+// @Param request body GrantEntitlementBody true "User to grant access to"
+// @Param id path int true "Book ID"
+// @Produce  json
+// @Success 201 {object} EntitlementResponse "Granted entitlement successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/books/{id}/entitlements [post]
+func (app *application) grantEntitlementHandler(w http.ResponseWriter, r *http.Request) {
+	admin := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	var input struct {
+		UserID int64 `json:"userId"`
+	}
+
+	err = app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if v.Check(input.UserID > 0, "userId", "must be provided"); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	_, err = app.models.Users.GetByID(input.UserID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("userId", "user not found")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	entitlement := &data.Entitlement{
+		UserID:    input.UserID,
+		BookID:    book.ID,
+		GrantedBy: &admin.ID,
+	}
+
+	err = app.models.Entitlements.Grant(entitlement)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateEntitlement):
+			v.AddError("userId", "user is already entitled to this book")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/books/%d/entitlements", book.ID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": entitlement}, headers)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// RevokeEntitlement godoc
+// @Summary Revoke Entitlement
+// @Description Revoke a granted entitlement. Purchased entitlements are revoked by refunding their order instead. Admin only.
+// @Tags Entitlements
+// @Produce  json
+// @Param id path int true "Book ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} DeleteSuccessResponse "Revoked entitlement successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Grant not found"
+// @Router /v1/books/{id}/entitlements/{userId} [delete]
+func (app *application) revokeEntitlementHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	userID, err := app.readParamInt(r, "userId")
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.Entitlements.Revoke(userID, id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "entitlement revoked"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newPremiumTestApplication returns a test application with a published book
+// by MockAuthor that has a free chapter (1) and a premium chapter (2).
+func newPremiumTestApplication(t *testing.T) (*application, *mockData.EntitlementModel) {
+	app := newTestApplication(t)
+
+	content := "It was a dark and stormy night."
+
+	app.models.Books = &mockData.BookModel{Books: []*data.Book{
+		{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, IsPublished: true, Price: 500, Currency: "USD"},
+	}}
+
+	app.models.Chapters = &mockData.ChapterModel{Chapters: []*data.Chapter{
+		{ID: 1, ChapterNo: 1, Title: "One", Content: &content, BookID: 1, UserID: mockData.MockAuthor.ID, Access: data.ChapterAccessFree, IsPublished: true},
+		{ID: 2, ChapterNo: 2, Title: "Two", Content: &content, BookID: 1, UserID: mockData.MockAuthor.ID, Access: data.ChapterAccessPremium, IsPublished: true},
+	}}
+
+	entitlements := &mockData.EntitlementModel{}
+	app.models.Entitlements = entitlements
+
+	return app, entitlements
+}
+
+func TestGetPremiumChapter(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		entitled   bool
+		chapterID  string
+		wantLocked bool
+	}{
+		{name: "Free chapter, anonymous", chapterID: "1"},
+		{name: "Premium chapter, anonymous", chapterID: "2", wantLocked: true},
+		{name: "Premium chapter, not entitled", user: mockData.MockReader, chapterID: "2", wantLocked: true},
+		{name: "Premium chapter, entitled", user: mockData.MockReader, entitled: true, chapterID: "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, entitlements := newPremiumTestApplication(t)
+
+			if tt.entitled {
+				entitlements.Entitlements = []*data.Entitlement{
+					{UserID: tt.user.ID, BookID: 1, Source: data.EntitlementSourcePurchase},
+				}
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			token := ""
+			if tt.user != nil {
+				token = newTestToken(t, app, tt.user)
+			}
+
+			status, _, body := ts.request(t, http.MethodGet, "/v1/chapters/"+tt.chapterID, token, nil)
+
+			assert.Equal(t, http.StatusOK, status)
+
+			chapter, _ := body["data"].(map[string]any)
+
+			if tt.wantLocked {
+				assert.Equal(t, true, chapter["locked"])
+				assert.Nil(t, chapter["content"])
+			} else {
+				assert.NotContains(t, chapter, "locked")
+				assert.Equal(t, "It was a dark and stormy night.", chapter["content"])
+			}
+		})
+	}
+}
+
+func TestListPremiumChapters(t *testing.T) {
+	app, _ := newPremiumTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	status, _, body := ts.request(t, http.MethodGet, "/v1/books/1/chapters?include=content", newTestToken(t, app, mockData.MockReader), nil)
+
+	assert.Equal(t, http.StatusOK, status)
+
+	chapters, _ := body["data"].([]any)
+
+	if assert.Len(t, chapters, 2) {
+		assert.Equal(t, "It was a dark and stormy night.", chapters[0].(map[string]any)["content"])
+		assert.Nil(t, chapters[1].(map[string]any)["content"])
+		assert.Equal(t, true, chapters[1].(map[string]any)["locked"])
+	}
+}
+
+func TestGrantEntitlement(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		wantStatus int
+	}{
+		{name: "Admin", user: mockData.MockAdmin, wantStatus: http.StatusCreated},
+		{name: "Book author", user: mockData.MockAuthor, wantStatus: http.StatusForbidden},
+		{name: "Reader", user: mockData.MockReader, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, entitlements := newPremiumTestApplication(t)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			payload := map[string]int64{"userId": mockData.MockReader.ID}
+
+			status, _, _ := ts.request(t, http.MethodPost, "/v1/books/1/entitlements", newTestToken(t, app, tt.user), payload)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			has, _ := entitlements.Has(mockData.MockReader.ID, 1)
+			assert.Equal(t, tt.wantStatus == http.StatusCreated, has)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"net/http"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
 )
 
 func (app *application) logError(r *http.Request, err error) {
@@ -53,6 +55,25 @@ func (app *application) editConflictResponse(w http.ResponseWriter, r *http.Requ
 	app.errorResponse(w, r, http.StatusConflict, message)
 }
 
+func (app *application) mergeConflictResponse(w http.ResponseWriter, r *http.Request, version int, conflicts []data.MergeConflict) {
+	message := envelope{
+		"message":        "the chapter was changed since your base version and the changes could not be merged",
+		"currentVersion": version,
+		"conflicts":      conflicts,
+	}
+
+	app.errorResponse(w, r, http.StatusConflict, message)
+}
+
+func (app *application) failedBatchResponse(w http.ResponseWriter, r *http.Request, results []*data.ChapterOpResult) {
+	message := envelope{
+		"message": "no changes were saved because some operations failed",
+		"results": results,
+	}
+
+	app.errorResponse(w, r, http.StatusUnprocessableEntity, message)
+}
+
 func (app *application) invalidCredentialsResponse(w http.ResponseWriter, r *http.Request) {
 	message := "invalid authentication credentials"
 	app.errorResponse(w, r, http.StatusUnauthorized, message)
@@ -78,3 +99,8 @@ func (app *application) notPermittedResponse(w http.ResponseWriter, r *http.Requ
 	message := "your user account doesn't have the necessary permissions to access this resource"
 	app.errorResponse(w, r, http.StatusForbidden, message)
 }
+
+func (app *application) rateLimitExceededResponse(w http.ResponseWriter, r *http.Request) {
+	message := "rate limit exceeded"
+	app.errorResponse(w, r, http.StatusTooManyRequests, message)
+}
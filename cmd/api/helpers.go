@@ -9,7 +9,9 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
 	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
 	"github.com/julienschmidt/httprouter"
 )
@@ -50,8 +52,12 @@ func (app *application) writeJSON(w http.ResponseWriter, status int, data envelo
 }
 
 func (app *application) readJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	return app.readJSONLimit(w, r, dst, 1_048_576)
+}
 
-	maxBytes := 1_048_576
+// readJSONLimit is readJSON for endpoints that take larger bodies than the
+// default 1MB.
+func (app *application) readJSONLimit(w http.ResponseWriter, r *http.Request, dst any, maxBytes int) error {
 
 	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
 
@@ -129,6 +135,39 @@ func (app *application) background(fn func()) {
 
 }
 
+// runPeriodic calls fn every interval until the server shuts down. A panic
+// in fn is logged and only skips that run. The job is not tracked by wg:
+// it never finishes on its own, so waiting on it would block shutdown.
+func (app *application) runPeriodic(interval time.Duration, name string, fn func()) {
+	if interval <= 0 {
+		return
+	}
+
+	run := func() {
+		defer func() {
+			if err := recover(); err != nil {
+				app.logger.Error("periodic job panicked", "job", name, "error", err)
+			}
+		}()
+
+		fn()
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-app.shutdown:
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
+
 func (app *application) readParamInt(r *http.Request, key string) (int64, error) {
 	params := httprouter.ParamsFromContext(r.Context())
 
@@ -180,3 +219,51 @@ func (app *application) readInt(qs url.Values, key string, defaultValue int, v *
 	return i
 
 }
+
+// readDate reads a date given as YYYY-MM-DD or an RFC 3339 timestamp. Plain
+// dates are taken as midnight UTC.
+func (app *application) readDate(qs url.Values, key string, defaultValue time.Time, v *validator.Validator) time.Time {
+	s := qs.Get(key)
+
+	if s == "" {
+		return defaultValue
+	}
+
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+
+	if err != nil {
+		v.AddError(key, "must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+		return defaultValue
+	}
+
+	return t
+}
+
+// paginationLinks builds RFC 8288 Link headers pointing at the next and
+// previous pages of a cursor paginated listing.
+func (app *application) paginationLinks(r *http.Request, metadata *data.Metadata) http.Header {
+	headers := make(http.Header)
+
+	link := func(cursor, rel string) {
+		qs := r.URL.Query()
+		qs.Del("page")
+		qs.Set("cursor", cursor)
+
+		u := url.URL{Path: r.URL.Path, RawQuery: qs.Encode()}
+		headers.Add("Link", fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel))
+	}
+
+	if metadata.NextCursor != "" {
+		link(metadata.NextCursor, "next")
+	}
+
+	if metadata.PrevCursor != "" {
+		link(metadata.PrevCursor, "prev")
+	}
+
+	return headers
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
 	"github.com/Kaungmyatkyaw2/book-store-api/internal/mailer"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments/fake"
 	"github.com/hashicorp/go-hclog"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -41,6 +43,35 @@ type config struct {
 		clientID     string
 		clientSecret string
 	}
+	payments struct {
+		provider      string
+		webhookSecret string
+	}
+	orders struct {
+		pendingTTL     time.Duration
+		expireInterval time.Duration
+	}
+	trash struct {
+		retention     time.Duration
+		purgeInterval time.Duration
+	}
+	subscriptions struct {
+		gracePeriod time.Duration
+	}
+	revisions struct {
+		keep          int
+		retention     time.Duration
+		pruneInterval time.Duration
+	}
+	autosaves struct {
+		ttl           time.Duration
+		purgeInterval time.Duration
+	}
+	comments struct {
+		editWindow time.Duration
+		rateLimit  int
+		rateWindow time.Duration
+	}
 }
 
 type application struct {
@@ -48,7 +79,9 @@ type application struct {
 	logger      hclog.Logger
 	models      data.Models
 	mailer      mailer.IMailer
+	payments    payments.Provider
 	wg          sync.WaitGroup
+	shutdown    chan struct{}
 	googleOauth *oauth2.Config
 }
 
@@ -87,14 +120,45 @@ func main() {
 		Endpoint:     google.Endpoint,
 	}
 
+	// Webhooks grant paid access, so they must never be accepted unsigned.
+	if cfg.payments.webhookSecret == "" {
+		logger.Error("a payment webhook secret must be configured")
+		return
+	}
+
+	var paymentProvider payments.Provider
+
+	switch cfg.payments.provider {
+	case "fake":
+		if cfg.env != "development" && cfg.env != "test" {
+			logger.Error("the fake payment provider is only available in development and test", "env", cfg.env)
+			return
+		}
+
+		paymentProvider = fake.New(cfg.payments.webhookSecret)
+	case "":
+		logger.Error("a payment provider must be configured")
+		return
+	default:
+		logger.Error("unsupported payment provider", "provider", cfg.payments.provider)
+		return
+	}
+
 	app := &application{
 		config:      cfg,
 		logger:      logger,
 		models:      data.NewModels(db),
 		mailer:      mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender),
+		payments:    paymentProvider,
+		shutdown:    make(chan struct{}),
 		googleOauth: &googleOauthConfig,
 	}
 
+	app.runPeriodic(cfg.orders.expireInterval, "expire orders", app.expireOrders)
+	app.runPeriodic(cfg.trash.purgeInterval, "purge trash", app.purgeTrash)
+	app.runPeriodic(cfg.revisions.pruneInterval, "prune revisions", app.pruneRevisions)
+	app.runPeriodic(cfg.autosaves.purgeInterval, "purge autosaves", app.purgeAutosaves)
+
 	err = app.serve()
 
 	if err != nil {
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// Checkout godoc
+// @Summary Checkout
+// @Description Turn the current user's cart into a pending order and start a payment for it. An optional coupon code is applied to the order. Free orders are paid immediately. If the payment cannot be started the order is cancelled and the cart is left as it was.
+// @Tags Store
+// @Param request body CheckoutBody false "Coupon to apply"
+// @Produce  json
+// @Success 201 {object} CheckoutResponse "Order created successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/orders [post]
+func (app *application) checkoutHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	var input struct {
+		CouponCode string `json:"couponCode"`
+	}
+
+	if r.ContentLength != 0 {
+		err := app.readJSON(w, r, &input)
+
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
+	v := validator.New()
+
+	order, err := app.models.Orders.CreateFromCart(user.ID, strings.TrimSpace(input.CouponCode), app.config.orders.pendingTTL)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEmptyCart):
+			v.AddError("cart", "must contain at least one book you don't own yet")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrMixedCurrencies):
+			v.AddError("cart", "must only contain books priced in the same currency")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrInvalidCoupon):
+			v.AddError("couponCode", "is not a valid coupon")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrCouponExhausted):
+			v.AddError("couponCode", "has reached its usage limit")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrCouponNotApplicable):
+			v.AddError("couponCode", "does not apply to any book in your cart")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/orders/%d", order.ID))
+
+	if order.Total == 0 {
+		err = app.models.Orders.Transition(order, data.OrderPaid)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		err = app.writeJSON(w, http.StatusCreated, envelope{"data": order}, headers)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	payment, err := app.payments.CreatePayment(ctx, payments.PaymentRequest{
+		OrderID:     order.ID,
+		Amount:      order.Total,
+		Currency:    order.Currency,
+		Description: fmt.Sprintf("Book Store order #%d", order.ID),
+	})
+
+	if err != nil {
+		if cancelErr := app.models.Orders.Transition(order, data.OrderCancelled); cancelErr != nil {
+			app.logError(r, cancelErr)
+		}
+
+		// The cart was emptied into the order, so put its books back for the
+		// user to try again.
+		for _, item := range order.Items {
+			if cartErr := app.models.Cart.Add(user.ID, *item.BookID); cartErr != nil {
+				app.logError(r, cartErr)
+			}
+		}
+
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	order.PaymentProvider = app.payments.Name()
+	order.PaymentRef = payment.Ref
+
+	err = app.models.Orders.SetPayment(order)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": order, "checkoutUrl": payment.CheckoutURL}, headers)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetOrders godoc
+// @Summary Get my orders
+// @Description Get the current user's orders, newest first
+// @Tags Store
+// @Produce  json
+// @Param        page   query     int     false  "Page number (default: 1)"
+// @Param        limit  query     int     false  "Items per page (default: 20)"
+// @Success 200 {object} GetOrdersResponse "Fetched orders successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/orders [get]
+func (app *application) getOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	var input struct {
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "limit", 20, v)
+
+	input.Filters.Sort = "-created_at"
+	input.Filters.SortSafelist = []string{"-created_at"}
+
+	if data.ValidateFilter(v, input.Filters); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	orders, metadata, err := app.models.Orders.GetAllByUser(user.ID, input.Filters)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": orders, "metadata": metadata}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetOrderById godoc
+// @Summary Get Order By ID
+// @Description Get one of the current user's orders with its items
+// @Tags Store
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Success 200 {object} OrderResponse "Fetched order successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Order not found"
+// @Router /v1/orders/{id} [get]
+func (app *application) getOrderByIDHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	order, err := app.models.Orders.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if order.UserID != user.ID && !user.IsAdmin {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": order}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// CancelOrder godoc
+// @Summary Cancel Order
+// @Description Cancel one of the current user's pending orders and void its payment at the provider. A payment that completes anyway is refunded.
+// @Tags Store
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Success 200 {object} OrderResponse "Cancelled order successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Order not found"
+// @Failure 409 {object} GeneralErrorResponse "Order can no longer be cancelled"
+// @Router /v1/orders/{id}/cancel [post]
+func (app *application) cancelOrderHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	order, err := app.models.Orders.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if order.UserID != user.ID {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.Orders.Transition(order, data.OrderCancelled)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidTransition):
+			app.errorResponse(w, r, http.StatusConflict, fmt.Sprintf("a %s order cannot be cancelled", order.Status))
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	// The order is cancelled either way; if voiding fails and the buyer
+	// completes the payment, the webhook refunds it.
+	if order.PaymentRef != "" {
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		if err := app.payments.CancelPayment(ctx, order.PaymentRef); err != nil {
+			app.logError(r, err)
+		}
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": order}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// RefundOrder godoc
+// @Summary Refund Order
+// @Description Ask the payment provider to refund a paid order. The order becomes refunded once the provider confirms through the webhook. Admin only.
+// @Tags Store
+// @Produce  json
+// @Param id path int true "Order ID"
+// @Success 202 {object} OrderResponse "Refund requested successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Order not found"
+// @Failure 409 {object} GeneralErrorResponse "Order cannot be refunded"
+// @Router /v1/orders/{id}/refund [post]
+func (app *application) refundOrderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	order, err := app.models.Orders.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if !data.CanTransition(order.Status, data.OrderRefunded) || order.PaymentRef == "" {
+		app.errorResponse(w, r, http.StatusConflict, fmt.Sprintf("a %s order cannot be refunded", order.Status))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	err = app.payments.Refund(ctx, order.PaymentRef)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusAccepted, envelope{"data": order}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// expireOrders cancels orders left unpaid for longer than the pending TTL so
+// the coupon uses they hold are given back, and voids their payments at the
+// provider. A payment that completes anyway is refunded by the webhook. It
+// runs periodically for the lifetime of the server.
+func (app *application) expireOrders() {
+	before := time.Now().Add(-app.config.orders.pendingTTL)
+
+	expired, err := app.models.Orders.ExpirePending(before)
+	if err != nil {
+		app.logger.Error("expiring pending orders", "error", err)
+		return
+	}
+
+	for _, order := range expired {
+		if order.PaymentRef == "" {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+
+		err := app.payments.CancelPayment(ctx, order.PaymentRef)
+		if err != nil {
+			app.logger.Error("voiding payment of expired order", "order", order.ID, "error", err)
+		}
+
+		cancel()
+	}
+
+	if len(expired) > 0 {
+		app.logger.Info("expired pending orders", "orders", len(expired))
+	}
+}
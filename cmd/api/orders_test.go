@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments/fake"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		orderID    string
+		wantStatus int
+		wantState  string
+		wantVoids  []string
+	}{
+		{name: "Pending order", user: mockData.MockReader, orderID: "1", wantStatus: http.StatusOK, wantState: data.OrderCancelled, wantVoids: []string{"fake_pay_1"}},
+		{name: "Paid order", user: mockData.MockReader, orderID: "2", wantStatus: http.StatusConflict, wantState: data.OrderPaid},
+		{name: "Other user's order", user: mockData.MockAuthor, orderID: "1", wantStatus: http.StatusNotFound, wantState: data.OrderPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, provider := newStoreTestApplication(t)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, _ := ts.request(t, http.MethodPost, "/v1/orders/"+tt.orderID+"/cancel", newTestToken(t, app, tt.user), nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			order, err := app.models.Orders.GetByPaymentRef("fake_pay_" + tt.orderID)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantState, order.Status)
+			}
+
+			assert.Equal(t, tt.wantVoids, provider.Voids)
+		})
+	}
+}
+
+// failingProvider is a payment provider that cannot start payments.
+type failingProvider struct {
+	*fake.Provider
+}
+
+func (p failingProvider) CreatePayment(ctx context.Context, req payments.PaymentRequest) (*payments.Payment, error) {
+	return nil, errors.New("provider unavailable")
+}
+
+func TestCheckout(t *testing.T) {
+	tests := []struct {
+		name       string
+		cart       []int64
+		failing    bool
+		wantStatus int
+		wantCart   []int64
+		wantState  string
+	}{
+		{name: "Payment started", cart: []int64{1, 2}, wantStatus: http.StatusCreated, wantState: data.OrderPending},
+		{name: "Provider failing", cart: []int64{1, 2}, failing: true, wantStatus: http.StatusInternalServerError, wantCart: []int64{1, 2}, wantState: data.OrderCancelled},
+		{name: "Empty cart", wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, provider := newStoreTestApplication(t)
+
+			if tt.failing {
+				app.payments = failingProvider{provider}
+			}
+
+			for _, bookID := range tt.cart {
+				app.models.Cart.Add(mockData.MockReader.ID, bookID)
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, body := ts.request(t, http.MethodPost, "/v1/orders", newTestToken(t, app, mockData.MockReader), nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			cart := []int64{}
+
+			items, _ := app.models.Cart.Get(mockData.MockReader.ID)
+
+			for _, item := range items {
+				cart = append(cart, item.BookID)
+			}
+
+			if tt.wantCart == nil {
+				tt.wantCart = []int64{}
+			}
+
+			assert.Equal(t, tt.wantCart, cart)
+
+			if tt.wantState == "" {
+				return
+			}
+
+			order, err := app.models.Orders.Get(4)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantState, order.Status)
+				assert.Equal(t, int64(1499), order.Total)
+			}
+
+			if tt.wantStatus == http.StatusCreated {
+				assert.NotEmpty(t, body["checkoutUrl"])
+				assert.NotEmpty(t, order.PaymentRef)
+			}
+		})
+	}
+}
+
+func TestExpireOrders(t *testing.T) {
+	app, provider := newStoreTestApplication(t)
+
+	app.config.orders.pendingTTL = time.Hour
+
+	orders := app.models.Orders.(*mockData.OrderModel)
+
+	orders.Orders = append(orders.Orders, &data.Order{
+		ID:         4,
+		UserID:     mockData.MockReader.ID,
+		Status:     data.OrderPending,
+		Total:      500,
+		Currency:   "USD",
+		PaymentRef: "fake_pay_4",
+		CreatedAt:  time.Now().Add(-2 * time.Hour),
+		Version:    1,
+	})
+
+	app.expireOrders()
+
+	wantStates := map[int64]string{1: data.OrderPending, 2: data.OrderPaid, 3: data.OrderCancelled, 4: data.OrderCancelled}
+
+	for id, state := range wantStates {
+		order, err := app.models.Orders.Get(id)
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, state, order.Status, "order %d", id)
+		}
+	}
+
+	assert.Equal(t, []string{"fake_pay_4"}, provider.Voids)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments"
+)
+
+// paymentEventTransitions maps provider events to the order status they move
+// an order to.
+var paymentEventTransitions = map[string]string{
+	payments.EventPaymentSucceeded: data.OrderPaid,
+	payments.EventPaymentFailed:    data.OrderCancelled,
+	payments.EventRefundSucceeded:  data.OrderRefunded,
+}
+
+// PaymentWebhook godoc
+// @Summary Payment provider webhook
+// @Description Receives signed payment events from the payment provider and advances the matching order or subscription. A payment that succeeds for a cancelled order is refunded.
+// @Tags Store
+// @Produce  json
+// @Success 200 {object} DeleteSuccessResponse "Event processed"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 401 {object} GeneralErrorResponse "Invalid signature"
+// @Failure 409 {object} GeneralErrorResponse "Edit conflict, retry later"
+// @Router /v1/payments/webhook [post]
+func (app *application) paymentWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1_048_576))
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	event, err := app.payments.ParseWebhook(payload, r.Header)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, payments.ErrInvalidSignature):
+			app.errorResponse(w, r, http.StatusUnauthorized, "invalid webhook signature")
+		default:
+			app.badRequestResponse(w, r, err)
+		}
+
+		return
+	}
+
+	switch event.Type {
+	case payments.EventSubscriptionRenewed, payments.EventSubscriptionRenewalFailed, payments.EventSubscriptionCancelled:
+		app.handleSubscriptionEvent(w, r, event)
+		return
+	}
+
+	status, ok := paymentEventTransitions[event.Type]
+
+	if !ok {
+		app.writeJSON(w, http.StatusOK, envelope{"message": "event ignored"}, nil)
+		return
+	}
+
+	order, err := app.models.Orders.GetByPaymentRef(event.PaymentRef)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	// A payment can still complete after its order was cancelled, as voiding
+	// it at the provider may lose the race with the buyer. The order bought
+	// nothing, so the money is given back rather than the event dropped.
+	if event.Type == payments.EventPaymentSucceeded && order.Status == data.OrderCancelled {
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		err = app.payments.Refund(ctx, order.PaymentRef)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		err = app.writeJSON(w, http.StatusOK, envelope{"message": "payment refunded"}, nil)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	// Providers deliver events at least once, so an order that already moved
+	// on is acknowledged rather than reported as an error.
+	if order.Status == status || !data.CanTransition(order.Status, status) {
+		app.writeJSON(w, http.StatusOK, envelope{"message": "event ignored"}, nil)
+		return
+	}
+
+	err = app.models.Orders.Transition(order, status)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "event processed"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// handleSubscriptionEvent applies a renewal, failed renewal or provider-side
+// cancellation to the matching subscription. Like order events, replays and
+// events that no longer apply are acknowledged and ignored.
+func (app *application) handleSubscriptionEvent(w http.ResponseWriter, r *http.Request, event *payments.Event) {
+	subscription, err := app.models.Subscriptions.GetByPaymentRef(event.PaymentRef)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	switch event.Type {
+	case payments.EventSubscriptionRenewed:
+		err = subscription.Renew(time.Now(), event.PeriodEnd)
+	case payments.EventSubscriptionRenewalFailed:
+		err = subscription.MarkPastDue(app.config.subscriptions.gracePeriod)
+	case payments.EventSubscriptionCancelled:
+		err = subscription.Cancel()
+	}
+
+	if errors.Is(err, data.ErrInvalidTransition) {
+		app.writeJSON(w, http.StatusOK, envelope{"message": "event ignored"}, nil)
+		return
+	}
+
+	err = app.models.Subscriptions.Update(subscription)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "event processed"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments/fake"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStoreTestApplication returns a test application where MockAuthor sells
+// two books and MockReader has a pending (1), a paid (2) and a cancelled (3)
+// order, each with a payment at the fake provider.
+func newStoreTestApplication(t *testing.T) (*application, *fake.Provider) {
+	app := newTestApplication(t)
+
+	books := &mockData.BookModel{Books: []*data.Book{
+		{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, IsPublished: true, Price: 999, Currency: "USD"},
+		{ID: 2, Title: "Emma", UserID: mockData.MockAuthor.ID, IsPublished: true, Price: 500, Currency: "USD"},
+	}}
+
+	cart := &mockData.CartModel{Books: books}
+
+	bookID := int64(1)
+
+	orders := &mockData.OrderModel{Cart: cart}
+
+	for i, status := range []string{data.OrderPending, data.OrderPaid, data.OrderCancelled} {
+		orders.Orders = append(orders.Orders, &data.Order{
+			ID:              int64(i + 1),
+			UserID:          mockData.MockReader.ID,
+			Status:          status,
+			Total:           999,
+			Currency:        "USD",
+			PaymentProvider: "fake",
+			PaymentRef:      fmt.Sprintf("fake_pay_%d", i+1),
+			Items:           []*data.OrderItem{{BookID: &bookID, Title: "Dune", Price: 999}},
+			CreatedAt:       time.Now(),
+			Version:         1,
+		})
+	}
+
+	provider := fake.New("test-secret")
+
+	app.models.Books = books
+	app.models.Cart = cart
+	app.models.Orders = orders
+	app.payments = provider
+
+	return app, provider
+}
+
+// postWebhook sends event to the webhook, signed by provider unless
+// signature is set.
+func (ts *testServer) postWebhook(t *testing.T, provider *fake.Provider, event payments.Event, signature string) (int, map[string]any) {
+	t.Helper()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/v1/payments/webhook", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	if signature == "" {
+		signature = provider.Sign(payload)
+	}
+
+	req.Header.Set(fake.SignatureHeader, signature)
+
+	rs, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+
+	defer rs.Body.Close()
+
+	var body map[string]any
+	if err := json.NewDecoder(rs.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	return rs.StatusCode, body
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	tests := []struct {
+		name        string
+		event       payments.Event
+		signature   string
+		wantStatus  int
+		wantMessage string
+		wantOrder   int64
+		wantState   string
+		wantRefunds []string
+	}{
+		{
+			name:        "Payment for pending order",
+			event:       payments.Event{ID: "evt_1", Type: payments.EventPaymentSucceeded, PaymentRef: "fake_pay_1"},
+			wantStatus:  http.StatusOK,
+			wantMessage: "event processed",
+			wantOrder:   1,
+			wantState:   data.OrderPaid,
+		},
+		{
+			name:        "Payment for cancelled order",
+			event:       payments.Event{ID: "evt_2", Type: payments.EventPaymentSucceeded, PaymentRef: "fake_pay_3"},
+			wantStatus:  http.StatusOK,
+			wantMessage: "payment refunded",
+			wantOrder:   3,
+			wantState:   data.OrderCancelled,
+			wantRefunds: []string{"fake_pay_3"},
+		},
+		{
+			name:        "Replayed payment",
+			event:       payments.Event{ID: "evt_3", Type: payments.EventPaymentSucceeded, PaymentRef: "fake_pay_2"},
+			wantStatus:  http.StatusOK,
+			wantMessage: "event ignored",
+			wantOrder:   2,
+			wantState:   data.OrderPaid,
+		},
+		{
+			name:        "Failed payment for cancelled order",
+			event:       payments.Event{ID: "evt_4", Type: payments.EventPaymentFailed, PaymentRef: "fake_pay_3"},
+			wantStatus:  http.StatusOK,
+			wantMessage: "event ignored",
+			wantOrder:   3,
+			wantState:   data.OrderCancelled,
+		},
+		{
+			name:       "Bad signature",
+			event:      payments.Event{ID: "evt_5", Type: payments.EventPaymentSucceeded, PaymentRef: "fake_pay_1"},
+			signature:  "00",
+			wantStatus: http.StatusUnauthorized,
+			wantOrder:  1,
+			wantState:  data.OrderPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, provider := newStoreTestApplication(t)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, body := ts.postWebhook(t, provider, tt.event, tt.signature)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantMessage != "" {
+				assert.Equal(t, tt.wantMessage, body["message"])
+			}
+
+			order, err := app.models.Orders.Get(tt.wantOrder)
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantState, order.Status)
+			}
+
+			assert.Equal(t, tt.wantRefunds, provider.Refunds)
+		})
+	}
+}
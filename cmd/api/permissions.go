@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+// bookRole returns the role the user holds on the book: owner for the
+// book's creator, the collaborator role for accepted collaborators, and an
+// empty string for everyone else.
+func (app *application) bookRole(user *data.User, book *data.Book) (string, error) {
+	if user == nil {
+		return "", nil
+	}
+
+	if book.UserID == user.ID {
+		return data.RoleOwner, nil
+	}
+
+	role, err := app.models.Collaborators.GetRole(book.ID, user.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			return "", nil
+		default:
+			return "", err
+		}
+	}
+
+	return role, nil
+}
+
+// hasBookRole reports whether the user holds at least the given role on the
+// book.
+func (app *application) hasBookRole(user *data.User, book *data.Book, role string) (bool, error) {
+	have, err := app.bookRole(user, book)
+
+	if err != nil {
+		return false, err
+	}
+
+	return data.RoleAllows(have, role), nil
+}
+
+// hasChapterRole reports whether the user holds at least the given role on
+// the book the chapter belongs to.
+func (app *application) hasChapterRole(user *data.User, chapter *data.Chapter, role string) (bool, error) {
+	book, err := app.models.Books.Get(chapter.BookID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	return app.hasBookRole(user, book, role)
+}
+
+// withholdPremiumContent strips the content from premium chapters the user
+// is not entitled to read and marks them as locked. Entitlements are looked
+// up once per book.
+func (app *application) withholdPremiumContent(user *data.User, chapters ...*data.Chapter) error {
+	entitled := make(map[int64]bool)
+
+	for _, chapter := range chapters {
+		if chapter.Access != data.ChapterAccessPremium {
+			continue
+		}
+
+		has, ok := entitled[chapter.BookID]
+
+		if !ok && user != nil {
+			var err error
+
+			has, err = app.models.Entitlements.Has(user.ID, chapter.BookID)
+
+			if err != nil {
+				return err
+			}
+
+			entitled[chapter.BookID] = has
+		}
+
+		if !has {
+			chapter.Content = nil
+			chapter.Locked = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// GetProgress godoc
+// @Summary Get Reading Progress
+// @Description Get where the current user left off in a book
+// @Tags Reading
+// @Produce  json
+// @Param id path int true "Book ID"
+// @Success 200 {object} ProgressResponse "Fetched progress successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "No progress recorded"
+// @Router /v1/books/{id}/progress [get]
+func (app *application) getProgressHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	progress, err := app.models.Progress.Get(user.ID, id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": progress}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// SaveProgress godoc
+// @Summary Save Reading Progress
+// @Description Record the chapter and in-chapter position or percentage the current user reached in a book. When devices disagree the update with the most recent updatedAt wins; a stale update is ignored and the stored progress is returned with applied set to false.
+// @Tags Reading
+// @Param request body SaveProgressBody true "Progress to record"
+// @Param id path int true "Book ID"
+// @Produce  json
+// @Success 200 {object} SaveProgressResponse "Saved progress successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/books/{id}/progress [put]
+func (app *application) saveProgressHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	collaborator, err := app.hasBookRole(user, book, data.RoleViewer)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !book.IsPublished && !collaborator {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		ChapterID int64      `json:"chapterId"`
+		Position  *int       `json:"position"`
+		Percent   *float64   `json:"percent"`
+		UpdatedAt *time.Time `json:"updatedAt"`
+	}
+
+	err = app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	progress := &data.ReadingProgress{
+		BookID:    book.ID,
+		ChapterID: input.ChapterID,
+		Position:  input.Position,
+		Percent:   input.Percent,
+		UpdatedAt: data.ProgressTime(input.UpdatedAt, time.Now()),
+	}
+
+	v := validator.New()
+
+	if data.ValidateReadingProgress(v, progress); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	chapter, err := app.models.Chapters.Get(progress.ChapterID)
+
+	if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if chapter == nil || chapter.BookID != book.ID || (!chapter.IsPublished && !collaborator) {
+		v.AddError("chapterId", "must be a chapter of this book")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	applied, err := app.models.Progress.Save(user.ID, progress)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": progress, "applied": applied}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// ContinueReading godoc
+// @Summary Continue reading
+// @Description List the books the current user is part way through, most recently read first, with the next chapter to read
+// @Tags Reading
+// @Produce  json
+// @Param        page   query     int     false  "Page number (default: 1)"
+// @Param        limit  query     int     false  "Items per page (default: 20)"
+// @Success 200 {object} ContinueReadingResponse "Fetched books successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/me/continue-reading [get]
+func (app *application) continueReadingHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	var input struct {
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "limit", 20, v)
+
+	input.Filters.Sort = "-updated_at"
+	input.Filters.SortSafelist = []string{"-updated_at"}
+
+	if data.ValidateFilter(v, input.Filters); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	items, metadata, err := app.models.Progress.ContinueReading(user.ID, input.Filters)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": items, "metadata": metadata}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveProgress(t *testing.T) {
+	tests := []struct {
+		name       string
+		viewer     bool
+		path       string
+		payload    map[string]any
+		wantStatus int
+		wantError  map[string]any
+	}{
+		{name: "Published chapter", path: "/v1/books/1/progress", payload: map[string]any{"chapterId": 1, "position": 120, "percent": 40.5}, wantStatus: http.StatusOK},
+		{name: "Draft chapter, viewer", viewer: true, path: "/v1/books/1/progress", payload: map[string]any{"chapterId": 2}, wantStatus: http.StatusOK},
+		{name: "Draft book, viewer", viewer: true, path: "/v1/books/2/progress", payload: map[string]any{"chapterId": 3}, wantStatus: http.StatusOK},
+		{name: "Draft book, stranger", path: "/v1/books/2/progress", payload: map[string]any{"chapterId": 3}, wantStatus: http.StatusNotFound},
+		{name: "Missing book", path: "/v1/books/9/progress", payload: map[string]any{"chapterId": 1}, wantStatus: http.StatusNotFound},
+		{
+			name:       "Draft chapter, stranger",
+			path:       "/v1/books/1/progress",
+			payload:    map[string]any{"chapterId": 2},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"chapterId": "must be a chapter of this book"},
+		},
+		{
+			name:       "Chapter of another book",
+			viewer:     true,
+			path:       "/v1/books/1/progress",
+			payload:    map[string]any{"chapterId": 3},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"chapterId": "must be a chapter of this book"},
+		},
+		{
+			name:       "Out of range position",
+			path:       "/v1/books/1/progress",
+			payload:    map[string]any{"chapterId": 1, "position": -1, "percent": 101},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"position": "must not be negative", "percent": "must be between 0 and 100"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newChapterTestApplication(t, tt.viewer)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, body := ts.request(t, http.MethodPut, tt.path, newTestToken(t, app, mockData.MockReader), tt.payload)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, true, body["applied"])
+			}
+		})
+	}
+}
+
+// TestProgressSync plays back updates from two devices arriving out of
+// order: the one read most recently must win.
+func TestProgressSync(t *testing.T) {
+	app := newChapterTestApplication(t, true)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	token := newTestToken(t, app, mockData.MockReader)
+
+	phoneReadAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	tabletReadAt := phoneReadAt.Add(-time.Hour)
+
+	status, _, _ := ts.request(t, http.MethodPut, "/v1/books/1/progress", token, map[string]any{"chapterId": 2, "position": 10, "updatedAt": phoneReadAt})
+	assert.Equal(t, http.StatusOK, status)
+
+	status, _, body := ts.request(t, http.MethodPut, "/v1/books/1/progress", token, map[string]any{"chapterId": 1, "position": 900, "updatedAt": tabletReadAt})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, false, body["applied"], "an update read before the stored one is stale")
+
+	progress, _ := body["data"].(map[string]any)
+	assert.Equal(t, float64(2), progress["chapterId"], "a stale update gets the stored progress back")
+	assert.Equal(t, float64(10), progress["position"])
+
+	// A clock running ahead is recorded as now, so it cannot pin the
+	// position against later reads.
+	status, _, body = ts.request(t, http.MethodPut, "/v1/books/1/progress", token, map[string]any{"chapterId": 1, "position": 5, "updatedAt": time.Now().Add(24 * time.Hour)})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, true, body["applied"])
+
+	status, _, body = ts.request(t, http.MethodPut, "/v1/books/1/progress", token, map[string]any{"chapterId": 2, "position": 50})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, true, body["applied"])
+
+	status, _, body = ts.request(t, http.MethodGet, "/v1/books/1/progress", token, nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	progress, _ = body["data"].(map[string]any)
+	assert.Equal(t, float64(2), progress["chapterId"])
+	assert.Equal(t, float64(50), progress["position"])
+
+	status, _, _ = ts.request(t, http.MethodGet, "/v1/books/1/progress", newTestToken(t, app, mockData.MockAuthor), nil)
+	assert.Equal(t, http.StatusNotFound, status, "progress is kept per user")
+}
+
+func TestContinueReading(t *testing.T) {
+	app := newTestApplication(t)
+
+	now := time.Now()
+
+	app.models.Progress = &mockData.ProgressModel{}
+
+	for i, readAt := range []time.Time{now.Add(-3 * time.Hour), now.Add(-time.Hour), now.Add(-2 * time.Hour)} {
+		app.models.Progress.Save(mockData.MockReader.ID, &data.ReadingProgress{BookID: int64(i + 1), ChapterID: 1, UpdatedAt: readAt})
+	}
+
+	app.models.Progress.Save(mockData.MockAuthor.ID, &data.ReadingProgress{BookID: 4, ChapterID: 1, UpdatedAt: now})
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	token := newTestToken(t, app, mockData.MockReader)
+
+	tests := []struct {
+		name         string
+		query        string
+		wantStatus   int
+		wantBookIDs  []float64
+		wantLastPage float64
+	}{
+		{name: "Most recent first", wantStatus: http.StatusOK, wantBookIDs: []float64{2, 3, 1}, wantLastPage: 1},
+		{name: "Second page", query: "?page=2&limit=2", wantStatus: http.StatusOK, wantBookIDs: []float64{1}, wantLastPage: 2},
+		{name: "Invalid limit", query: "?limit=0", wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, body := ts.request(t, http.MethodGet, "/v1/me/continue-reading"+tt.query, token, nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			bookIDs := []float64{}
+
+			items, _ := body["data"].([]any)
+
+			for _, item := range items {
+				bookIDs = append(bookIDs, item.(map[string]any)["bookId"].(float64))
+			}
+
+			assert.Equal(t, tt.wantBookIDs, bookIDs)
+
+			metadata, _ := body["metadata"].(map[string]any)
+			assert.Equal(t, tt.wantLastPage, metadata["lastPage"])
+		})
+	}
+}
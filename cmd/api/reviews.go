@@ -0,0 +1,373 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// GetReviews godoc
+// @Summary Get Book Reviews
+// @Description Get the reviews of a published book
+// @Tags Reviews
+// @Produce  json
+// @Param id path int true "Book ID"
+// @Param        page   query     int     false  "Page number (default: 1)"
+// @Param        limit  query     int     false  "Items per page (default: 20)"
+// @Param        sort   query     string  false  "Sort by created_at or rating, e.g. '-rating' for descending (default: -created_at)"
+// @Success 200 {object} GetReviewsResponse "Fetched reviews successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/books/{id}/reviews [get]
+func (app *application) getReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if !book.IsPublished {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "limit", 20, v)
+
+	input.Filters.Sort = app.readString(qs, "sort", "-created_at")
+	input.Filters.SortSafelist = []string{"created_at", "rating", "-created_at", "-rating"}
+
+	if data.ValidateFilter(v, input.Filters); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	reviews, metadata, err := app.models.Reviews.GetByBook(book.ID, input.Filters)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": reviews, "metadata": metadata}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// CreateReview godoc
+// @Summary Create Review
+// @Description Rate a published book from 1 to 5 stars with an optional review text. Each user can review a book once, and authors cannot review their own books.
+// @Tags Reviews
+// @Param request body CreateReviewBody true "Review data to create"
+// @Param id path int true "Book ID"
+// @Produce  json
+// @Success 201 {object} ReviewResponse "Created review successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/books/{id}/reviews [post]
+func (app *application) createReviewHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if !app.checkReviewable(w, r, user, book) {
+		return
+	}
+
+	var input struct {
+		Rating int    `json:"rating"`
+		Body   string `json:"body"`
+	}
+
+	err = app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	review := &data.Review{
+		BookID:   book.ID,
+		UserID:   user.ID,
+		UserName: user.Name,
+		Rating:   input.Rating,
+		Body:     strings.TrimSpace(input.Body),
+	}
+
+	v := validator.New()
+
+	if data.ValidateReview(v, review); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Reviews.Insert(review)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateReview):
+			v.AddError("book", "you have already reviewed this book")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/reviews/%d", review.ID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": review}, headers)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// UpdateReview godoc
+// @Summary Update Review
+// @Description Change the rating or text of one of the current user's reviews
+// @Tags Reviews
+// @Param request body UpdateReviewBody true "Review data to update"
+// @Param id path int true "Review ID"
+// @Produce  json
+// @Success 200 {object} ReviewResponse "Updated review successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Review not found"
+// @Failure 409 {object} GeneralErrorResponse "Edit Conflict"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/reviews/{id} [patch]
+func (app *application) updateReviewHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	review, ok := app.readOwnReview(w, r)
+
+	if !ok {
+		return
+	}
+
+	// Admins may remove any review but only its author can change it.
+	if review.UserID != user.ID {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.Get(review.BookID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if !app.checkReviewable(w, r, user, book) {
+		return
+	}
+
+	var input struct {
+		Rating *int    `json:"rating"`
+		Body   *string `json:"body"`
+	}
+
+	err = app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Rating != nil {
+		review.Rating = *input.Rating
+	}
+
+	if input.Body != nil {
+		review.Body = strings.TrimSpace(*input.Body)
+	}
+
+	v := validator.New()
+
+	if data.ValidateReview(v, review); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Reviews.Update(review)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": review}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// DeleteReview godoc
+// @Summary Delete Review
+// @Description Delete one of the current user's reviews. Admins can delete any review.
+// @Tags Reviews
+// @Produce  json
+// @Param id path int true "Review ID"
+// @Success 200 {object} DeleteSuccessResponse "Deleted review successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Review not found"
+// @Router /v1/reviews/{id} [delete]
+func (app *application) deleteReviewHandler(w http.ResponseWriter, r *http.Request) {
+	review, ok := app.readOwnReview(w, r)
+
+	if !ok {
+		return
+	}
+
+	err := app.models.Reviews.Delete(review)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "review deleted"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// readOwnReview loads the review named by the id parameter if the current
+// user wrote it or is an admin, and writes a not found response otherwise.
+func (app *application) readOwnReview(w http.ResponseWriter, r *http.Request) (*data.Review, bool) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	review, err := app.models.Reviews.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return nil, false
+	}
+
+	if review.UserID != user.ID && !user.IsAdmin {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	return review, true
+}
+
+// checkReviewable writes an error response and returns false unless the user
+// may review the book: it must be published, and neither its owner nor its
+// collaborators may rate it.
+func (app *application) checkReviewable(w http.ResponseWriter, r *http.Request, user *data.User, book *data.Book) bool {
+	if !book.IsPublished {
+		v := validator.New()
+		v.AddError("book", "only published books can be reviewed")
+		app.failedValidationResponse(w, r, v.Errors)
+		return false
+	}
+
+	role, err := app.bookRole(user, book)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return false
+	}
+
+	if role != "" {
+		app.notPermittedResponse(w, r)
+		return false
+	}
+
+	return true
+}
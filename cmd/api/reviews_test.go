@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newReviewTestApplication returns a test application with a published book
+// (1) and a draft book (2) by MockAuthor, and a review of book 1 by
+// MockReader.
+func newReviewTestApplication(t *testing.T) (*application, *mockData.ReviewModel) {
+	app := newTestApplication(t)
+
+	app.models.Books = &mockData.BookModel{Books: []*data.Book{
+		{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, IsPublished: true},
+		{ID: 2, Title: "Emma", UserID: mockData.MockAuthor.ID},
+	}}
+
+	reviews := &mockData.ReviewModel{Reviews: []*data.Review{
+		{ID: 1, BookID: 1, UserID: mockData.MockReader.ID, UserName: mockData.MockReader.Name, Rating: 4, Body: "Good", Version: 1},
+	}}
+
+	app.models.Reviews = reviews
+
+	return app, reviews
+}
+
+func TestCreateReview(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		role       string
+		bookID     string
+		wantStatus int
+		wantError  any
+	}{
+		{name: "Reader who has not reviewed", user: mockData.MockAdmin, bookID: "1", wantStatus: http.StatusCreated},
+		{name: "Author", user: mockData.MockAuthor, bookID: "1", wantStatus: http.StatusForbidden},
+		{name: "Collaborator", user: mockData.MockAdmin, role: data.RoleViewer, bookID: "1", wantStatus: http.StatusForbidden},
+		{
+			name:       "Draft book",
+			user:       mockData.MockAdmin,
+			bookID:     "2",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"book": "only published books can be reviewed"},
+		},
+		{
+			name:       "Second review",
+			user:       mockData.MockReader,
+			bookID:     "1",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"book": "you have already reviewed this book"},
+		},
+		{name: "Anonymous", bookID: "1", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, reviews := newReviewTestApplication(t)
+
+			if tt.role != "" {
+				app.models.Collaborators = &mockData.CollaboratorModel{Collaborators: []*data.Collaborator{
+					{BookID: 1, UserID: tt.user.ID, Role: tt.role, Status: data.InvitationAccepted},
+				}}
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			token := ""
+			if tt.user != nil {
+				token = newTestToken(t, app, tt.user)
+			}
+
+			payload := map[string]any{"rating": 5, "body": "Loved it"}
+
+			status, _, body := ts.request(t, http.MethodPost, "/v1/books/"+tt.bookID+"/reviews", token, payload)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+			}
+
+			if tt.wantStatus == http.StatusCreated {
+				assert.Len(t, reviews.Reviews, 2)
+			} else {
+				assert.Len(t, reviews.Reviews, 1)
+			}
+		})
+	}
+}
+
+func TestUpdateReview(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		wantStatus int
+	}{
+		{name: "Reviewer", user: mockData.MockReader, wantStatus: http.StatusOK},
+		{name: "Book author", user: mockData.MockAuthor, wantStatus: http.StatusNotFound},
+		{name: "Admin", user: mockData.MockAdmin, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, reviews := newReviewTestApplication(t)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			payload := map[string]any{"rating": 1}
+
+			status, _, _ := ts.request(t, http.MethodPatch, "/v1/reviews/1", newTestToken(t, app, tt.user), payload)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			review, _ := reviews.Get(1)
+
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, 1, review.Rating)
+			} else {
+				assert.Equal(t, 4, review.Rating)
+			}
+		})
+	}
+}
+
+func TestDeleteReview(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		wantStatus int
+	}{
+		{name: "Reviewer", user: mockData.MockReader, wantStatus: http.StatusOK},
+		{name: "Admin", user: mockData.MockAdmin, wantStatus: http.StatusOK},
+		{name: "Book author", user: mockData.MockAuthor, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, reviews := newReviewTestApplication(t)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, _ := ts.request(t, http.MethodDelete, "/v1/reviews/1", newTestToken(t, app, tt.user), nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantStatus == http.StatusOK {
+				assert.Empty(t, reviews.Reviews)
+			} else {
+				assert.Len(t, reviews.Reviews, 1)
+			}
+		})
+	}
+}
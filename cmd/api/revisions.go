@@ -0,0 +1,325 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// GetChapterRevisions godoc
+// @Summary Get Chapter Revisions
+// @Description List the saved versions of a chapter, newest first, without their content. Available to the book's owner and collaborators.
+// @Tags Chapters
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Param        page   query     int     false  "Page number (default: 1)"
+// @Param        limit  query     int     false  "Items per page (default: 20)"
+// @Success 200 {object} GetChapterRevisionsResponse "Fetched revisions successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Chapter not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/chapters/{id}/revisions [get]
+func (app *application) getChapterRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	chapter, ok := app.readRevisionChapter(w, r, data.RoleViewer)
+
+	if !ok {
+		return
+	}
+
+	var input struct {
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "limit", 20, v)
+
+	input.Filters.Sort = "-revision"
+	input.Filters.SortSafelist = []string{"-revision"}
+
+	if data.ValidateFilter(v, input.Filters); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	revisions, metadata, err := app.models.Revisions.GetAll(chapter.ID, input.Filters)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": revisions, "metadata": metadata}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetChapterRevision godoc
+// @Summary Get Chapter Revision
+// @Description Get a saved version of a chapter with its content
+// @Tags Chapters
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} ChapterRevisionResponse "Fetched revision successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Revision not found"
+// @Router /v1/chapters/{id}/revisions/{rev} [get]
+func (app *application) getChapterRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	chapter, ok := app.readRevisionChapter(w, r, data.RoleViewer)
+
+	if !ok {
+		return
+	}
+
+	revision, ok := app.readRevision(w, r, chapter)
+
+	if !ok {
+		return
+	}
+
+	err := app.writeJSON(w, http.StatusOK, envelope{"data": revision}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// DiffChapterRevisions godoc
+// @Summary Diff Chapter Revisions
+// @Description Compare the content of two saved versions of a chapter. The unified format returns a diff -u style patch; the words format returns the equal, inserted and deleted runs of words.
+// @Tags Chapters
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Param        from   query     int     true   "Revision to compare from"
+// @Param        to     query     int     false  "Revision to compare to (default: the current content)"
+// @Param        format query     string  false  "unified or words (default: unified)"
+// @Success 200 {object} RevisionDiffResponse "Compared revisions successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Revision not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/chapters/{id}/diff [get]
+func (app *application) diffChapterRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	chapter, ok := app.readRevisionChapter(w, r, data.RoleViewer)
+
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	from := app.readInt(qs, "from", 0, v)
+	to := app.readInt(qs, "to", 0, v)
+	format := app.readString(qs, "format", "unified")
+
+	v.Check(from > 0, "from", "must be provided")
+	v.Check(to >= 0, "to", "must be a positive integer")
+	v.Check(validator.In(format, "unified", "words"), "format", "must be unified or words")
+
+	if !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	var contents [2]string
+
+	labels := [2]string{fmt.Sprintf("revision %d", from), fmt.Sprintf("revision %d", to)}
+
+	// Without a target revision the diff runs against the chapter as it is
+	// now; publishing bumps the version without saving a revision.
+	if to == 0 {
+		labels[1] = "current"
+
+		if chapter.Content != nil {
+			contents[1] = *chapter.Content
+		}
+	}
+
+	for i, rev := range []int{from, to} {
+		if rev == 0 {
+			continue
+		}
+
+		revision, err := app.models.Revisions.Get(chapter.ID, rev)
+
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				app.notFoundResponse(w, r)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+
+			return
+		}
+
+		if revision.Content != nil {
+			contents[i] = *revision.Content
+		}
+	}
+
+	diff := data.RevisionDiff{From: from, To: to, Format: format}
+
+	switch format {
+	case "words":
+		diff.Ops = data.WordDiff(contents[0], contents[1])
+	default:
+		diff.Unified = data.UnifiedDiff(labels[0], labels[1], contents[0], contents[1], 3)
+	}
+
+	err := app.writeJSON(w, http.StatusOK, envelope{"data": diff}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// RestoreChapterRevision godoc
+// @Summary Restore Chapter Revision
+// @Description Bring back the title, description, content, content format and access of a saved version. The restore is saved as a new revision, so it can itself be undone.
+// @Tags Chapters
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Param rev path int true "Revision number"
+// @Success 200 {object} GetChapterResponse "Restored revision successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Revision not found"
+// @Failure 409 {object} GeneralErrorResponse "Edit Conflict"
+// @Router /v1/chapters/{id}/revisions/{rev}/restore [post]
+func (app *application) restoreChapterRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	chapter, ok := app.readRevisionChapter(w, r, data.RoleEditor)
+
+	if !ok {
+		return
+	}
+
+	revision, ok := app.readRevision(w, r, chapter)
+
+	if !ok {
+		return
+	}
+
+	chapter.Title = revision.Title
+	chapter.Description = revision.Description
+	chapter.Content = revision.Content
+	chapter.ContentFormat = revision.ContentFormat
+	chapter.Access = revision.Access
+
+	err := app.models.Chapters.Update(chapter)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": chapter}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// readRevisionChapter loads the chapter named by the id parameter and checks
+// the current user holds at least role on its book. It writes the error
+// response itself when it returns false.
+func (app *application) readRevisionChapter(w http.ResponseWriter, r *http.Request, role string) (*data.Chapter, bool) {
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	chapter, err := app.models.Chapters.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return nil, false
+	}
+
+	permitted, err := app.hasChapterRole(app.contextGetUser(r), chapter, role)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return nil, false
+	}
+
+	if !permitted {
+		app.notPermittedResponse(w, r)
+		return nil, false
+	}
+
+	return chapter, true
+}
+
+func (app *application) readRevision(w http.ResponseWriter, r *http.Request, chapter *data.Chapter) (*data.ChapterRevision, bool) {
+	rev, err := app.readParamInt(r, "rev")
+
+	if err != nil || rev < 1 {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	revision, err := app.models.Revisions.Get(chapter.ID, int(rev))
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return nil, false
+	}
+
+	return revision, true
+}
+
+// pruneRevisions deletes chapter revisions older than the configured
+// retention, keeping the newest ones of every chapter regardless of age. It
+// runs periodically for the lifetime of the server.
+func (app *application) pruneRevisions() {
+	before := time.Now().Add(-app.config.revisions.retention)
+
+	pruned, err := app.models.Revisions.Prune(app.config.revisions.keep, before)
+	if err != nil {
+		app.logger.Error("pruning chapter revisions", "error", err)
+		return
+	}
+
+	if pruned > 0 {
+		app.logger.Info("pruned chapter revisions", "revisions", pruned)
+	}
+}
@@ -27,6 +27,7 @@ func (app *application) routes() http.Handler {
 
 	router.HandlerFunc(http.MethodGet, "/v1/users/:id", app.getUser)
 	router.HandlerFunc(http.MethodGet, "/v1/users/:id/books", app.getBooksByUser)
+	router.HandlerFunc(http.MethodGet, "/v1/users/:id/shelves", app.getUserShelvesHandler)
 
 	router.HandlerFunc(http.MethodGet, "/v1/books", app.getBooksHandler)
 	router.HandlerFunc(http.MethodGet, "/v1/books/:id", app.getBookByIDHandler)
@@ -34,11 +35,106 @@ func (app *application) routes() http.Handler {
 	router.HandlerFunc(http.MethodPatch, "/v1/books/:id", app.requireActivatedUser(app.updateBookHandler))
 	router.HandlerFunc(http.MethodDelete, "/v1/books/:id", app.requireActivatedUser(app.deleteBookHandler))
 	router.HandlerFunc(http.MethodGet, "/v1/books/:id/chapters", app.getChaptersByBookHandler)
+	router.HandlerFunc(http.MethodPut, "/v1/books/:id/chapters/order", app.requireActivatedUser(app.reorderChaptersHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/books/:id/chapters/batch", app.requireActivatedUser(app.batchChaptersHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/books/:id/restore", app.requireActivatedUser(app.restoreBookHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/books/:id/collaborators", app.requireActivatedUser(app.getCollaboratorsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/books/:id/collaborators", app.requireActivatedUser(app.inviteCollaboratorHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/books/:id/collaborators/:userId", app.requireActivatedUser(app.removeCollaboratorHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/books/:id/entitlements", app.requireAdminUser(app.getEntitlementsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/books/:id/entitlements", app.requireAdminUser(app.grantEntitlementHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/books/:id/entitlements/:userId", app.requireAdminUser(app.revokeEntitlementHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/books/:id/annotations/export", app.requireActivatedUser(app.exportAnnotationsHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/books/:id/progress", app.requireActivatedUser(app.getProgressHandler))
+	router.HandlerFunc(http.MethodPut, "/v1/books/:id/progress", app.requireActivatedUser(app.saveProgressHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/books/:id/reviews", app.getReviewsHandler)
+	router.HandlerFunc(http.MethodPost, "/v1/books/:id/reviews", app.requireActivatedUser(app.createReviewHandler))
+
+	router.HandlerFunc(http.MethodGet, "/v1/search", app.searchHandler)
+	router.HandlerFunc(http.MethodGet, "/v1/search/suggest", app.searchSuggestHandler)
+
+	router.HandlerFunc(http.MethodPost, "/v1/series", app.requireActivatedUser(app.createSeriesHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/series/:id", app.getSeriesHandler)
+	router.HandlerFunc(http.MethodPut, "/v1/series/:id/books", app.requireActivatedUser(app.setSeriesBooksHandler))
 
 	router.HandlerFunc(http.MethodPost, "/v1/chapters", app.requireActivatedUser(app.createChapterHandler))
 	router.HandlerFunc(http.MethodGet, "/v1/chapters/:id", app.getChapterByIDHandler)
 	router.HandlerFunc(http.MethodPatch, "/v1/chapters/:id", app.requireActivatedUser(app.updateChapterHandler))
 	router.HandlerFunc(http.MethodDelete, "/v1/chapters/:id", app.requireActivatedUser(app.deleteChapterHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/chapters/:id/restore", app.requireActivatedUser(app.restoreChapterHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/chapters/:id/publish", app.requireActivatedUser(app.publishChapterHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/chapters/:id/unpublish", app.requireActivatedUser(app.unpublishChapterHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/chapters/:id/revisions", app.requireActivatedUser(app.getChapterRevisionsHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/chapters/:id/revisions/:rev", app.requireActivatedUser(app.getChapterRevisionHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/chapters/:id/revisions/:rev/restore", app.requireActivatedUser(app.restoreChapterRevisionHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/chapters/:id/diff", app.requireActivatedUser(app.diffChapterRevisionsHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/chapters/:id/autosave", app.requireActivatedUser(app.getAutosaveHandler))
+	router.HandlerFunc(http.MethodPut, "/v1/chapters/:id/autosave", app.requireActivatedUser(app.saveAutosaveHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/chapters/:id/autosave", app.requireActivatedUser(app.discardAutosaveHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/chapters/:id/autosave/commit", app.requireActivatedUser(app.commitAutosaveHandler))
+
+	router.HandlerFunc(http.MethodPatch, "/v1/shelves/:id", app.requireActivatedUser(app.updateShelfHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/shelves/:id", app.requireActivatedUser(app.deleteShelfHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/shelves/:id/books", app.getShelfBooksHandler)
+	router.HandlerFunc(http.MethodPost, "/v1/shelves/:id/books", app.requireActivatedUser(app.addShelfBookHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/shelves/:id/books/:bookId", app.requireActivatedUser(app.removeShelfBookHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/shelves/:id/books/:bookId/move", app.requireActivatedUser(app.moveShelfBookHandler))
+
+	router.HandlerFunc(http.MethodGet, "/v1/chapters/:id/annotations", app.requireActivatedUser(app.getChapterAnnotationsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/chapters/:id/annotations", app.requireActivatedUser(app.createAnnotationHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/annotations/:id", app.requireActivatedUser(app.updateAnnotationHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/annotations/:id", app.requireActivatedUser(app.deleteAnnotationHandler))
+
+	router.HandlerFunc(http.MethodGet, "/v1/chapters/:id/comments", app.getCommentsHandler)
+	router.HandlerFunc(http.MethodPost, "/v1/chapters/:id/comments", app.requireActivatedUser(app.createCommentHandler))
+
+	router.HandlerFunc(http.MethodGet, "/v1/comments/:id/replies", app.getCommentRepliesHandler)
+	router.HandlerFunc(http.MethodPatch, "/v1/comments/:id", app.requireActivatedUser(app.updateCommentHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/comments/:id", app.requireActivatedUser(app.deleteCommentHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/comments/:id/pin", app.requireActivatedUser(app.pinCommentHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/comments/:id/unpin", app.requireActivatedUser(app.unpinCommentHandler))
+
+	router.HandlerFunc(http.MethodPatch, "/v1/reviews/:id", app.requireActivatedUser(app.updateReviewHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/reviews/:id", app.requireActivatedUser(app.deleteReviewHandler))
+
+	router.HandlerFunc(http.MethodGet, "/v1/me/trash", app.requireActivatedUser(app.getTrashHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/me/invitations", app.requireActivatedUser(app.getInvitationsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/me/invitations/:id/accept", app.requireActivatedUser(app.acceptInvitationHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/me/invitations/:id/decline", app.requireActivatedUser(app.declineInvitationHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/me/continue-reading", app.requireActivatedUser(app.continueReadingHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/me/shelves", app.requireActivatedUser(app.getMyShelvesHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/me/shelves", app.requireActivatedUser(app.createShelfHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/me/cart", app.requireActivatedUser(app.getCartHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/me/cart", app.requireActivatedUser(app.addCartItemHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/me/cart/:id", app.requireActivatedUser(app.removeCartItemHandler))
+
+	router.HandlerFunc(http.MethodGet, "/v1/orders", app.requireActivatedUser(app.getOrdersHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/orders", app.requireActivatedUser(app.checkoutHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/orders/:id", app.requireActivatedUser(app.getOrderByIDHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/orders/:id/cancel", app.requireActivatedUser(app.cancelOrderHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/orders/:id/refund", app.requireAdminUser(app.refundOrderHandler))
+
+	router.HandlerFunc(http.MethodGet, "/v1/me/subscription", app.requireActivatedUser(app.getSubscriptionHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/me/subscription", app.requireActivatedUser(app.subscribeHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/me/subscription/cancel", app.requireActivatedUser(app.cancelSubscriptionHandler))
+	router.HandlerFunc(http.MethodPut, "/v1/me/subscription-participation", app.requireActivatedUser(app.setSubscriptionParticipationHandler))
+
+	router.HandlerFunc(http.MethodGet, "/v1/me/earnings", app.requireActivatedUser(app.getEarningsHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/me/earnings/export", app.requireActivatedUser(app.exportEarningsHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/me/payouts", app.requireActivatedUser(app.getMyPayoutsHandler))
+
+	router.HandlerFunc(http.MethodPost, "/v1/payouts", app.requireAdminUser(app.closePayoutPeriodHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/payouts/:id", app.requireAdminUser(app.getPayoutPeriodHandler))
+
+	router.HandlerFunc(http.MethodGet, "/v1/coupons", app.requireActivatedUser(app.getCouponsHandler))
+	router.HandlerFunc(http.MethodPost, "/v1/coupons", app.requireActivatedUser(app.createCouponHandler))
+	router.HandlerFunc(http.MethodGet, "/v1/coupons/:id", app.requireActivatedUser(app.getCouponByIDHandler))
+	router.HandlerFunc(http.MethodDelete, "/v1/coupons/:id", app.requireActivatedUser(app.deleteCouponHandler))
+
+	router.HandlerFunc(http.MethodGet, "/v1/plans", app.getPlansHandler)
+	router.HandlerFunc(http.MethodPost, "/v1/plans", app.requireAdminUser(app.createPlanHandler))
+
+	router.HandlerFunc(http.MethodPost, "/v1/payments/webhook", app.paymentWebhookHandler)
 
 	return app.authenticate(router)
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// Search godoc
+// @Summary Search books and chapters
+// @Description Ranked full-text search across published books and their chapters. Supports quoted phrases, "or" and "-" exclusions. Headlines are HTML-escaped text with the matching words wrapped in <mark> tags. Premium chapters only match on their title and description.
+// @Tags Search
+// @Produce  json
+// @Param        q      query     string  true   "Search query, e.g. '\"dark forest\" -sequel'"
+// @Param        page   query     int     false  "Page number (default: 1)"
+// @Param        limit  query     int     false  "Items per page (default: 20)"
+// @Success 200 {object} SearchResponse "Fetched search results successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/search [get]
+func (app *application) searchHandler(w http.ResponseWriter, r *http.Request) {
+
+	var input struct {
+		Query string
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Query = app.readString(qs, "q", "")
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "limit", 20, v)
+
+	input.Filters.Sort = "-rank"
+	input.Filters.SortSafelist = []string{"-rank"}
+
+	data.ValidateSearchQuery(v, input.Query)
+
+	if data.ValidateFilter(v, input.Filters); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	results, metadata, err := app.models.Search.Search(input.Query, input.Filters)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": results, "metadata": metadata}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+
+}
+
+// SearchSuggest godoc
+// @Summary Autocomplete suggestions
+// @Description Typo-tolerant prefix suggestions for published book titles and author names
+// @Tags Search
+// @Produce  json
+// @Param        q      query     string  true   "Partial title or author name"
+// @Param        limit  query     int     false  "Maximum number of suggestions (default: 5, max: 20)"
+// @Success 200 {object} SuggestResponse "Fetched suggestions successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/search/suggest [get]
+func (app *application) searchSuggestHandler(w http.ResponseWriter, r *http.Request) {
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	query := strings.TrimSpace(app.readString(qs, "q", ""))
+	limit := app.readInt(qs, "limit", 5, v)
+
+	if data.ValidateSuggestQuery(v, query, limit); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	suggestions, err := app.models.Search.Suggest(query, limit)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": suggestions}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+
+}
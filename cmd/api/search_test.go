@@ -0,0 +1,181 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	app := newTestApplication(t)
+
+	search := &mockData.SearchModel{Results: []*data.SearchResult{
+		{Type: "book", ID: 1, BookID: 1, Title: "Dune", Headline: "<b>Dune</b> &lt;script&gt;", Rank: 0.9},
+		{Type: "chapter", ID: 7, BookID: 1, Title: "Arrakis", Headline: "the <b>dune</b> sea", Rank: 0.5},
+	}}
+
+	app.models.Search = search
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantQuery  string
+		wantTitles []any
+		wantError  map[string]any
+	}{
+		{
+			name:       "Match",
+			query:      "q=" + url.QueryEscape(`"desert planet" -water`),
+			wantStatus: http.StatusOK,
+			wantQuery:  `"desert planet" -water`,
+			wantTitles: []any{"Dune", "Arrakis"},
+		},
+		{
+			name:       "Second page",
+			query:      "q=dune&page=2&limit=1",
+			wantStatus: http.StatusOK,
+			wantQuery:  "dune",
+			wantTitles: []any{"Arrakis"},
+		},
+		{
+			name:       "Missing query",
+			query:      "",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"q": "must be provided"},
+		},
+		{
+			name:       "Query too long",
+			query:      "q=" + strings.Repeat("a", 201),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"q": "must not be more than 200 bytes long"},
+		},
+		{
+			name:       "Limit too large",
+			query:      "q=dune&limit=101",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"page_size": "must be a maximum of 100"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search.Query = ""
+
+			status, _, body := ts.request(t, http.MethodGet, "/v1/search?"+tt.query, "", nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+				assert.Empty(t, search.Query, "an invalid search must not reach the database")
+				return
+			}
+
+			assert.Equal(t, tt.wantQuery, search.Query)
+
+			titles := []any{}
+
+			results, _ := body["data"].([]any)
+
+			for _, result := range results {
+				titles = append(titles, result.(map[string]any)["title"])
+			}
+
+			assert.Equal(t, tt.wantTitles, titles)
+			assert.Contains(t, body, "metadata")
+		})
+	}
+}
+
+func TestSearchSuggest(t *testing.T) {
+	app := newTestApplication(t)
+
+	search := &mockData.SearchModel{Suggestions: []*data.Suggestion{
+		{Type: "book", ID: 1, Text: "Harry Potter", Score: 0.8},
+		{Type: "author", ID: 2, Text: "Harriet Vane", Score: 0.4},
+	}}
+
+	app.models.Search = search
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantQuery  string
+		wantTexts  []any
+		wantError  map[string]any
+	}{
+		{
+			name:       "Misspelled query",
+			query:      "q=" + url.QueryEscape("  hary poter "),
+			wantStatus: http.StatusOK,
+			wantQuery:  "hary poter",
+			wantTexts:  []any{"Harry Potter", "Harriet Vane"},
+		},
+		{
+			name:       "Limit",
+			query:      "q=har&limit=1",
+			wantStatus: http.StatusOK,
+			wantQuery:  "har",
+			wantTexts:  []any{"Harry Potter"},
+		},
+		{
+			name:       "Blank query",
+			query:      "q=" + url.QueryEscape("   "),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"q": "must be provided"},
+		},
+		{
+			name:       "Query too long",
+			query:      "q=" + strings.Repeat("a", 101),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"q": "must not be more than 100 bytes long"},
+		},
+		{
+			name:       "Limit too large",
+			query:      "q=har&limit=21",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"limit": "must be a maximum of 20"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search.Query = ""
+
+			status, _, body := ts.request(t, http.MethodGet, "/v1/search/suggest?"+tt.query, "", nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+				assert.Empty(t, search.Query, "an invalid query must not reach the database")
+				return
+			}
+
+			assert.Equal(t, tt.wantQuery, search.Query)
+
+			texts := []any{}
+
+			suggestions, _ := body["data"].([]any)
+
+			for _, suggestion := range suggestions {
+				texts = append(texts, suggestion.(map[string]any)["text"])
+			}
+
+			assert.Equal(t, tt.wantTexts, texts)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// CreateSeries godoc
+// @Summary Create Series
+// @Description Create an empty series. Add volumes with PUT /v1/series/{id}/books.
+// @Tags Series
+// @Param request body CreateSeriesBody true "Series data to create"
+// @Produce  json
+// @Success 201 {object} SeriesResponse "Series creation success"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/series [post]
+func (app *application) createSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	var input struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	series := &data.Series{
+		Title:       input.Title,
+		Description: input.Description,
+		UserID:      user.ID,
+	}
+
+	v := validator.New()
+
+	if data.ValidateSeries(v, series); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Series.Insert(series)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	series.Books = []*data.Book{}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/series/%d", series.ID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": series}, headers)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetSeries godoc
+// @Summary Get Series By ID
+// @Description Get a series with its volumes in reading order
+// @Tags Series
+// @Produce  json
+// @Param id path int true "Series ID"
+// @Success 200 {object} SeriesResponse "Fetched series successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 404 {object} GeneralErrorResponse "Series not found"
+// @Router /v1/series/{id} [get]
+func (app *application) getSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	series, err := app.models.Series.Get(id, false)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if user := app.contextGetUser(r); user != nil && user.ID == series.UserID {
+		series, err = app.models.Series.Get(id, true)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": series}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// SetSeriesBooks godoc
+// @Summary Reorder Series Volumes
+// @Description Replace the volumes of a series with the given books, in reading order. Books left out are removed from the series.
+// @Tags Series
+// @Param request body SetSeriesBooksBody true "Ordered book ids"
+// @Param id path int true "Series ID"
+// @Produce  json
+// @Success 200 {object} SeriesResponse "Reordered series successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Series not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/series/{id}/books [put]
+func (app *application) setSeriesBooksHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	series, err := app.models.Series.Get(id, true)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if series.UserID != user.ID {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	var input struct {
+		BookIDs []int64 `json:"bookIds"`
+	}
+
+	err = app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if data.ValidateSeriesBookIDs(v, input.BookIDs); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	for _, bookID := range input.BookIDs {
+		book, err := app.models.Books.Get(bookID)
+
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				v.AddError("bookIds", "must only contain your own books")
+				app.failedValidationResponse(w, r, v.Errors)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+
+			return
+		}
+
+		permitted, err := app.hasBookRole(user, book, data.RoleOwner)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+
+		if !permitted {
+			v.AddError("bookIds", "must only contain your own books")
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	err = app.models.Series.SetBooks(series.ID, input.BookIDs)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidSeriesBook):
+			v.AddError("bookIds", "must only contain your own books")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrBookInOtherSeries):
+			v.AddError("bookIds", "a book already belongs to another series")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	series, err = app.models.Series.Get(id, true)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": series}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetSeriesBooks(t *testing.T) {
+	deletedAt := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		user        *data.User
+		bookIDs     []int64
+		wantStatus  int
+		wantVolumes []int64
+		wantError   map[string]any
+	}{
+		{name: "Own books", user: mockData.MockAuthor, bookIDs: []int64{2, 1}, wantStatus: http.StatusOK, wantVolumes: []int64{2, 1}},
+		{name: "Book the author co-owns", user: mockData.MockAuthor, bookIDs: []int64{1, 3}, wantStatus: http.StatusOK, wantVolumes: []int64{1, 3}},
+		{
+			name:        "Book the author only edits",
+			user:        mockData.MockAuthor,
+			bookIDs:     []int64{1, 4},
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantVolumes: []int64{1},
+			wantError:   map[string]any{"bookIds": "must only contain your own books"},
+		},
+		{
+			name:        "Book in the trash",
+			user:        mockData.MockAuthor,
+			bookIDs:     []int64{1, 5},
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantVolumes: []int64{1},
+			wantError:   map[string]any{"bookIds": "must only contain your own books"},
+		},
+		{
+			name:        "Book in another series",
+			user:        mockData.MockAuthor,
+			bookIDs:     []int64{1, 6},
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantVolumes: []int64{1},
+			wantError:   map[string]any{"bookIds": "a book already belongs to another series"},
+		},
+		{name: "Not the series owner", user: mockData.MockReader, bookIDs: []int64{3}, wantStatus: http.StatusForbidden, wantVolumes: []int64{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			app.models.Books = &mockData.BookModel{Books: []*data.Book{
+				{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, IsPublished: true},
+				{ID: 2, Title: "Dune Messiah", UserID: mockData.MockAuthor.ID, IsPublished: true},
+				{ID: 3, Title: "Children of Dune", UserID: mockData.MockReader.ID, IsPublished: true},
+				{ID: 4, Title: "God Emperor of Dune", UserID: mockData.MockReader.ID, IsPublished: true},
+				{ID: 5, Title: "Heretics of Dune", UserID: mockData.MockAuthor.ID, DeletedAt: &deletedAt},
+				{ID: 6, Title: "Emma", UserID: mockData.MockAuthor.ID, IsPublished: true},
+			}}
+
+			app.models.Collaborators = &mockData.CollaboratorModel{Collaborators: []*data.Collaborator{
+				{BookID: 3, UserID: mockData.MockAuthor.ID, Role: data.RoleOwner, Status: data.InvitationAccepted},
+				{BookID: 4, UserID: mockData.MockAuthor.ID, Role: data.RoleEditor, Status: data.InvitationAccepted},
+			}}
+
+			series := &mockData.SeriesModel{
+				Series: []*data.Series{
+					{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID},
+					{ID: 2, Title: "Austen", UserID: mockData.MockAuthor.ID},
+				},
+				Volumes: map[int64][]int64{1: {1}, 2: {6}},
+			}
+
+			app.models.Series = series
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			payload := map[string][]int64{"bookIds": tt.bookIDs}
+
+			status, _, body := ts.request(t, http.MethodPut, "/v1/series/1/books", newTestToken(t, app, tt.user), payload)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+			}
+
+			assert.Equal(t, tt.wantVolumes, series.Volumes[1])
+		})
+	}
+}
+
+func TestBookSeriesInResponses(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "Book list", path: "/v1/books"},
+		{name: "User's books", path: "/v1/users/2/books"},
+		{name: "Single book", path: "/v1/books/1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			app.models.Books = &mockData.BookModel{Books: []*data.Book{
+				{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, IsPublished: true},
+				{ID: 2, Title: "Emma", UserID: mockData.MockAuthor.ID, IsPublished: true},
+			}}
+
+			app.models.Series = &mockData.SeriesModel{
+				Series:  []*data.Series{{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID}},
+				Volumes: map[int64][]int64{1: {1}},
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, body := ts.request(t, http.MethodGet, tt.path, "", nil)
+
+			assert.Equal(t, http.StatusOK, status)
+
+			series := map[float64]any{}
+
+			books, ok := body["data"].([]any)
+			if !ok {
+				books = []any{body["data"]}
+			}
+
+			for _, book := range books {
+				b := book.(map[string]any)
+				series[b["id"].(float64)] = b["series"]
+			}
+
+			if assert.Contains(t, series, float64(1)) {
+				s, _ := series[1].(map[string]any)
+				assert.Equal(t, float64(1), s["id"])
+				assert.Equal(t, float64(1), s["position"])
+			}
+
+			if s, ok := series[2]; ok {
+				assert.Nil(t, s, "a book outside any series has no series")
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
@@ -16,6 +20,33 @@ func (app *application) serve() error {
 		WriteTimeout: 30 * time.Second,
 	}
 
+	shutdownError := make(chan error)
+
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+		s := <-quit
+
+		app.logger.Info("shutting down server", "signal", s.String())
+
+		// Periodic jobs stop after their current run, if any. One-off
+		// background tasks in wg are waited for once requests have drained.
+		close(app.shutdown)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		err := srv.Shutdown(ctx)
+		if err != nil {
+			shutdownError <- err
+			return
+		}
+
+		app.wg.Wait()
+		shutdownError <- nil
+	}()
+
 	app.logger.Info("starting server", map[string]interface{}{
 		"addr": srv.Addr,
 		"env":  app.config.env,
@@ -27,5 +58,13 @@ func (app *application) serve() error {
 		return err
 	}
 
+	err = <-shutdownError
+
+	if err != nil {
+		return err
+	}
+
+	app.logger.Info("stopped server", "addr", srv.Addr)
+
 	return nil
 }
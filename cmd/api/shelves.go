@@ -0,0 +1,601 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// GetMyShelves godoc
+// @Summary Get my shelves
+// @Description Get the current user's shelves: the want-to-read, currently-reading and finished status shelves followed by custom shelves
+// @Tags Shelves
+// @Produce  json
+// @Success 200 {object} GetShelvesResponse "Fetched shelves successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Router /v1/me/shelves [get]
+func (app *application) getMyShelvesHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	err := app.models.Shelves.EnsureDefaults(user.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	shelves, err := app.models.Shelves.GetAllByUser(user.ID, false)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": shelves}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetUserShelves godoc
+// @Summary Get User Shelves
+// @Description Get the shelves a user has made public on their profile
+// @Tags Shelves
+// @Produce  json
+// @Param id path int true "User ID"
+// @Success 200 {object} GetShelvesResponse "Fetched shelves successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 404 {object} GeneralErrorResponse "User not found"
+// @Router /v1/users/{id}/shelves [get]
+func (app *application) getUserShelvesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	_, err = app.models.Users.GetByID(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	shelves, err := app.models.Shelves.GetAllByUser(id, true)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": shelves}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// CreateShelf godoc
+// @Summary Create Shelf
+// @Description Create a custom named shelf for the current user
+// @Tags Shelves
+// @Param request body CreateShelfBody true "Shelf data to create"
+// @Produce  json
+// @Success 201 {object} ShelfResponse "Created shelf successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/me/shelves [post]
+func (app *application) createShelfHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	var input struct {
+		Name     string `json:"name"`
+		IsPublic bool   `json:"isPublic"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	shelf := &data.Shelf{
+		UserID:   user.ID,
+		Name:     strings.TrimSpace(input.Name),
+		Kind:     data.ShelfCustom,
+		IsPublic: input.IsPublic,
+	}
+
+	v := validator.New()
+
+	if data.ValidateShelf(v, shelf); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// The status shelves are created first so a custom shelf can never take
+	// one of their names.
+	err = app.models.Shelves.EnsureDefaults(user.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Shelves.Insert(shelf)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateShelfName):
+			v.AddError("name", "you already have a shelf with this name")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("/v1/shelves/%d/books", shelf.ID))
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": shelf}, headers)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// UpdateShelf godoc
+// @Summary Update Shelf
+// @Description Rename a custom shelf or change whether a shelf is shown on the owner's profile. Status shelves cannot be renamed.
+// @Tags Shelves
+// @Param request body UpdateShelfBody true "Shelf data to update"
+// @Param id path int true "Shelf ID"
+// @Produce  json
+// @Success 200 {object} ShelfResponse "Updated shelf successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 404 {object} GeneralErrorResponse "Shelf not found"
+// @Failure 409 {object} GeneralErrorResponse "Edit Conflict"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/shelves/{id} [patch]
+func (app *application) updateShelfHandler(w http.ResponseWriter, r *http.Request) {
+	shelf, ok := app.readOwnShelf(w, r)
+
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name     *string `json:"name"`
+		IsPublic *bool   `json:"isPublic"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+
+		if shelf.IsStatus() && name != shelf.Name {
+			v.AddError("name", "status shelves cannot be renamed")
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+
+		shelf.Name = name
+	}
+
+	if input.IsPublic != nil {
+		shelf.IsPublic = *input.IsPublic
+	}
+
+	if data.ValidateShelf(v, shelf); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Shelves.Update(shelf)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateShelfName):
+			v.AddError("name", "you already have a shelf with this name")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": shelf}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// DeleteShelf godoc
+// @Summary Delete Shelf
+// @Description Delete one of the current user's custom shelves. Status shelves cannot be deleted.
+// @Tags Shelves
+// @Produce  json
+// @Param id path int true "Shelf ID"
+// @Success 200 {object} DeleteSuccessResponse "Deleted shelf successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Shelf not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/shelves/{id} [delete]
+func (app *application) deleteShelfHandler(w http.ResponseWriter, r *http.Request) {
+	shelf, ok := app.readOwnShelf(w, r)
+
+	if !ok {
+		return
+	}
+
+	if shelf.IsStatus() {
+		v := validator.New()
+		v.AddError("shelf", "status shelves cannot be deleted")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err := app.models.Shelves.Delete(shelf.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "shelf deleted"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetShelfBooks godoc
+// @Summary Get Shelf Books
+// @Description Get the books on a shelf, most recently added first. Other users can only see public shelves.
+// @Tags Shelves
+// @Produce  json
+// @Param id path int true "Shelf ID"
+// @Param        page   query     int     false  "Page number (default: 1)"
+// @Param        limit  query     int     false  "Items per page (default: 20)"
+// @Success 200 {object} GetShelfBooksResponse "Fetched shelf books successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 404 {object} GeneralErrorResponse "Shelf not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/shelves/{id}/books [get]
+func (app *application) getShelfBooksHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	shelf, err := app.models.Shelves.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if !shelf.IsPublic && (user == nil || user.ID != shelf.UserID) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "limit", 20, v)
+
+	input.Filters.Sort = "-added_at"
+	input.Filters.SortSafelist = []string{"-added_at"}
+
+	if data.ValidateFilter(v, input.Filters); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	books, metadata, err := app.models.Shelves.GetBooks(shelf.ID, input.Filters)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": books, "shelf": shelf, "metadata": metadata}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// AddShelfBook godoc
+// @Summary Add Book To Shelf
+// @Description Put a published book on one of the current user's shelves. Adding a book to a status shelf takes it off the other status shelves.
+// @Tags Shelves
+// @Param request body AddShelfBookBody true "Book to add"
+// @Param id path int true "Shelf ID"
+// @Produce  json
+// @Success 201 {object} ShelfResponse "Added book successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 404 {object} GeneralErrorResponse "Shelf not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/shelves/{id}/books [post]
+func (app *application) addShelfBookHandler(w http.ResponseWriter, r *http.Request) {
+	shelf, ok := app.readOwnShelf(w, r)
+
+	if !ok {
+		return
+	}
+
+	var input struct {
+		BookID int64 `json:"bookId"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	book, err := app.models.Books.Get(input.BookID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("bookId", "book not found")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if !book.IsPublished {
+		v.AddError("bookId", "book not found")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Shelves.AddBook(shelf, book.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateShelfBook):
+			v.AddError("bookId", "book is already on this shelf")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	shelf.BookCount++
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": shelf}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// RemoveShelfBook godoc
+// @Summary Remove Book From Shelf
+// @Description Take a book off one of the current user's shelves
+// @Tags Shelves
+// @Produce  json
+// @Param id path int true "Shelf ID"
+// @Param bookId path int true "Book ID"
+// @Success 200 {object} DeleteSuccessResponse "Removed book successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "Shelf or book not found"
+// @Router /v1/shelves/{id}/books/{bookId} [delete]
+func (app *application) removeShelfBookHandler(w http.ResponseWriter, r *http.Request) {
+	shelf, ok := app.readOwnShelf(w, r)
+
+	if !ok {
+		return
+	}
+
+	bookID, err := app.readParamInt(r, "bookId")
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	err = app.models.Shelves.RemoveBook(shelf.ID, bookID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "book removed from shelf"}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// MoveShelfBook godoc
+// @Summary Move Book To Another Shelf
+// @Description Move a book from one of the current user's shelves to another, e.g. from currently-reading to finished
+// @Tags Shelves
+// @Param request body MoveShelfBookBody true "Shelf to move the book to"
+// @Param id path int true "Shelf ID"
+// @Param bookId path int true "Book ID"
+// @Produce  json
+// @Success 200 {object} ShelfResponse "Moved book successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 404 {object} GeneralErrorResponse "Shelf or book not found"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/shelves/{id}/books/{bookId}/move [post]
+func (app *application) moveShelfBookHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	from, ok := app.readOwnShelf(w, r)
+
+	if !ok {
+		return
+	}
+
+	bookID, err := app.readParamInt(r, "bookId")
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input struct {
+		ShelfID int64 `json:"shelfId"`
+	}
+
+	err = app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	to, err := app.models.Shelves.Get(input.ShelfID)
+
+	if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if to == nil || to.UserID != user.ID || to.ID == from.ID {
+		v.AddError("shelfId", "must be another one of your shelves")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Shelves.MoveBook(from, to, bookID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateShelfBook):
+			v.AddError("shelfId", "book is already on this shelf")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	to.BookCount++
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": to}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// readOwnShelf loads the shelf named by the id parameter if it belongs to the
+// current user, and writes a not found response otherwise.
+func (app *application) readOwnShelf(w http.ResponseWriter, r *http.Request) (*data.Shelf, bool) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	shelf, err := app.models.Shelves.Get(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return nil, false
+	}
+
+	if shelf.UserID != user.ID {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	return shelf, true
+}
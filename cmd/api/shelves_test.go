@@ -0,0 +1,192 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// newShelfTestApplication returns a test application where MockReader has a
+// private want-to-read shelf (1) holding book 1, a public custom shelf (2)
+// and a private custom shelf (3). MockAuthor has a shelf of their own (4).
+func newShelfTestApplication(t *testing.T) (*application, *mockData.ShelfModel) {
+	app := newTestApplication(t)
+
+	app.models.Books = &mockData.BookModel{Books: []*data.Book{
+		{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, IsPublished: true},
+		{ID: 2, Title: "Emma", UserID: mockData.MockAuthor.ID},
+	}}
+
+	shelves := &mockData.ShelfModel{
+		Shelves: []*data.Shelf{
+			{ID: 1, UserID: mockData.MockReader.ID, Name: "Want to Read", Kind: data.ShelfWantToRead, Version: 1},
+			{ID: 2, UserID: mockData.MockReader.ID, Name: "Favourites", Kind: data.ShelfCustom, IsPublic: true, Version: 1},
+			{ID: 3, UserID: mockData.MockReader.ID, Name: "Guilty pleasures", Kind: data.ShelfCustom, Version: 1},
+			{ID: 4, UserID: mockData.MockAuthor.ID, Name: "Favourites", Kind: data.ShelfCustom, Version: 1},
+		},
+		Books: map[int64][]int64{1: {1}},
+	}
+
+	app.models.Shelves = shelves
+
+	return app, shelves
+}
+
+func TestGetShelfBooks(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *data.User
+		shelfID    string
+		wantStatus int
+	}{
+		{name: "Public shelf, anonymous", shelfID: "2", wantStatus: http.StatusOK},
+		{name: "Private shelf, anonymous", shelfID: "1", wantStatus: http.StatusNotFound},
+		{name: "Private shelf, other user", user: mockData.MockAuthor, shelfID: "1", wantStatus: http.StatusNotFound},
+		{name: "Private shelf, owner", user: mockData.MockReader, shelfID: "1", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newShelfTestApplication(t)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			token := ""
+			if tt.user != nil {
+				token = newTestToken(t, app, tt.user)
+			}
+
+			status, _, _ := ts.request(t, http.MethodGet, "/v1/shelves/"+tt.shelfID+"/books", token, nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestGetUserShelves(t *testing.T) {
+	app, _ := newShelfTestApplication(t)
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	status, _, body := ts.request(t, http.MethodGet, "/v1/users/3/shelves", newTestToken(t, app, mockData.MockReader), nil)
+
+	assert.Equal(t, http.StatusOK, status)
+
+	shelves, _ := body["data"].([]any)
+
+	if assert.Len(t, shelves, 1, "only public shelves are listed, even to their owner") {
+		assert.Equal(t, float64(2), shelves[0].(map[string]any)["id"])
+	}
+}
+
+func TestChangeOtherUsersShelf(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		payload any
+	}{
+		{name: "Update", method: http.MethodPatch, path: "/v1/shelves/2", payload: map[string]any{"name": "Mine now"}},
+		{name: "Delete", method: http.MethodDelete, path: "/v1/shelves/2"},
+		{name: "Add book", method: http.MethodPost, path: "/v1/shelves/2/books", payload: map[string]any{"bookId": 1}},
+		{name: "Remove book", method: http.MethodDelete, path: "/v1/shelves/1/books/1"},
+		{name: "Move book", method: http.MethodPost, path: "/v1/shelves/1/books/1/move", payload: map[string]any{"shelfId": 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, shelves := newShelfTestApplication(t)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, _ := ts.request(t, tt.method, tt.path, newTestToken(t, app, mockData.MockAuthor), tt.payload)
+
+			assert.Equal(t, http.StatusNotFound, status)
+
+			assert.Len(t, shelves.Shelves, 4)
+			assert.Equal(t, "Favourites", shelves.Shelves[1].Name)
+			assert.Equal(t, map[int64][]int64{1: {1}}, shelves.Books)
+		})
+	}
+}
+
+func TestShelfRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		payload    any
+		wantStatus int
+		wantError  map[string]any
+	}{
+		{
+			name:       "Rename status shelf",
+			method:     http.MethodPatch,
+			path:       "/v1/shelves/1",
+			payload:    map[string]any{"name": "Someday"},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"name": "status shelves cannot be renamed"},
+		},
+		{
+			name:       "Delete status shelf",
+			method:     http.MethodDelete,
+			path:       "/v1/shelves/1",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"shelf": "status shelves cannot be deleted"},
+		},
+		{
+			name:       "Add draft book",
+			method:     http.MethodPost,
+			path:       "/v1/shelves/2/books",
+			payload:    map[string]any{"bookId": 2},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"bookId": "book not found"},
+		},
+		{
+			name:       "Add book twice",
+			method:     http.MethodPost,
+			path:       "/v1/shelves/1/books",
+			payload:    map[string]any{"bookId": 1},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"bookId": "book is already on this shelf"},
+		},
+		{
+			name:       "Move to another user's shelf",
+			method:     http.MethodPost,
+			path:       "/v1/shelves/1/books/1/move",
+			payload:    map[string]any{"shelfId": 4},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"shelfId": "must be another one of your shelves"},
+		},
+		{
+			name:       "Move to own shelf",
+			method:     http.MethodPost,
+			path:       "/v1/shelves/1/books/1/move",
+			payload:    map[string]any{"shelfId": 3},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newShelfTestApplication(t)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, body := ts.request(t, tt.method, tt.path, newTestToken(t, app, mockData.MockReader), tt.payload)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// GetPlans godoc
+// @Summary Get subscription plans
+// @Description Get the subscription plans readers can currently sign up for
+// @Tags Subscriptions
+// @Produce  json
+// @Success 200 {object} GetPlansResponse "Fetched plans successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Router /v1/plans [get]
+func (app *application) getPlansHandler(w http.ResponseWriter, r *http.Request) {
+	plans, err := app.models.Plans.GetAllActive()
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": plans}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// CreatePlan godoc
+// @Summary Create subscription plan
+// @Description Create a subscription plan. Admin only.
+// @Tags Subscriptions
+// @Param request body CreatePlanBody true "Plan data to create"
+// @Produce  json
+// @Success 201 {object} PlanResponse "Created plan successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/plans [post]
+func (app *application) createPlanHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name           string `json:"name"`
+		Price          int64  `json:"price"`
+		Currency       string `json:"currency"`
+		IntervalMonths int    `json:"intervalMonths"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	plan := &data.Plan{
+		Name:           input.Name,
+		Price:          input.Price,
+		Currency:       input.Currency,
+		IntervalMonths: input.IntervalMonths,
+	}
+
+	if plan.Currency == "" {
+		plan.Currency = data.DefaultCurrency
+	}
+
+	if plan.IntervalMonths == 0 {
+		plan.IntervalMonths = 1
+	}
+
+	v := validator.New()
+
+	if data.ValidatePlan(v, plan); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.Plans.Insert(plan)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": plan}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// GetSubscription godoc
+// @Summary Get my subscription
+// @Description Get the current user's subscription, including a cancelled one that is still within its paid period
+// @Tags Subscriptions
+// @Produce  json
+// @Success 200 {object} SubscriptionResponse "Fetched subscription successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "No subscription"
+// @Router /v1/me/subscription [get]
+func (app *application) getSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	subscription, err := app.models.Subscriptions.GetCurrentByUser(user.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": subscription}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// Subscribe godoc
+// @Summary Subscribe
+// @Description Start a subscription to a plan. The subscription becomes active once the payment provider confirms the first payment through the webhook. An unfinished earlier checkout, or a subscription that lapsed after failed renewals, is abandoned.
+// @Tags Subscriptions
+// @Param request body SubscribeBody true "Plan to subscribe to"
+// @Produce  json
+// @Success 201 {object} SubscribeResponse "Subscription created successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Failure 422 {object} ValidationErrorResponse "Validation Error"
+// @Router /v1/me/subscription [post]
+func (app *application) subscribeHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	var input struct {
+		PlanID int64 `json:"planId"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	if v.Check(input.PlanID > 0, "planId", "must be provided"); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	plan, err := app.models.Plans.Get(input.PlanID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("planId", "plan not found")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if v.Check(plan.IsActive, "planId", "plan is no longer offered"); !v.IsValid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	current, err := app.models.Subscriptions.GetCurrentByUser(user.ID)
+
+	switch {
+	case errors.Is(err, data.ErrRecordNotFound):
+	case err != nil:
+		app.serverErrorResponse(w, r, err)
+		return
+	case current.Status == data.SubscriptionIncomplete, current.Status != data.SubscriptionCancelled && !current.IsLive(time.Now()):
+		// An unfinished checkout, or a subscription that lapsed without the
+		// provider telling us, is stopped at the provider before starting over.
+		err = app.abandonSubscription(ctx, current)
+
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	case current.Status != data.SubscriptionCancelled:
+		v.AddError("planId", "you already have a subscription")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	subscription := &data.Subscription{
+		UserID: user.ID,
+		PlanID: plan.ID,
+	}
+
+	err = app.models.Subscriptions.Insert(subscription)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrActiveSubscription):
+			v.AddError("planId", "you already have a subscription")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	payment, err := app.payments.CreateSubscription(ctx, payments.SubscriptionRequest{
+		SubscriptionID: subscription.ID,
+		Amount:         plan.Price,
+		Currency:       plan.Currency,
+		IntervalMonths: plan.IntervalMonths,
+		Description:    fmt.Sprintf("Book Store %s subscription", plan.Name),
+	})
+
+	if err != nil {
+		if cancelErr := subscription.Cancel(); cancelErr == nil {
+			if updateErr := app.models.Subscriptions.Update(subscription); updateErr != nil {
+				app.logError(r, updateErr)
+			}
+		}
+
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	subscription.PaymentProvider = app.payments.Name()
+	subscription.PaymentRef = payment.Ref
+
+	err = app.models.Subscriptions.Update(subscription)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"data": subscription, "checkoutUrl": payment.CheckoutURL}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// CancelSubscription godoc
+// @Summary Cancel my subscription
+// @Description Stop renewing the current user's subscription. Access continues until the end of the period already paid for.
+// @Tags Subscriptions
+// @Produce  json
+// @Success 200 {object} SubscriptionResponse "Cancelled subscription successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 404 {object} GeneralErrorResponse "No subscription"
+// @Failure 409 {object} GeneralErrorResponse "Subscription already cancelled"
+// @Router /v1/me/subscription/cancel [post]
+func (app *application) cancelSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	subscription, err := app.models.Subscriptions.GetCurrentByUser(user.ID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	if subscription.Status == data.SubscriptionCancelled {
+		app.errorResponse(w, r, http.StatusConflict, "subscription is already cancelled")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	err = app.abandonSubscription(ctx, subscription)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": subscription}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// abandonSubscription cancels the subscription with the payment provider and
+// records the cancellation.
+func (app *application) abandonSubscription(ctx context.Context, subscription *data.Subscription) error {
+	if subscription.PaymentRef != "" {
+		err := app.payments.CancelSubscription(ctx, subscription.PaymentRef)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err := subscription.Cancel()
+
+	if err != nil {
+		return err
+	}
+
+	return app.models.Subscriptions.Update(subscription)
+}
+
+// SetSubscriptionParticipation godoc
+// @Summary Join or leave the subscription catalogue
+// @Description Opt the current user's premium chapters in or out of reader subscriptions
+// @Tags Subscriptions
+// @Param request body SubscriptionParticipationBody true "Whether to participate"
+// @Produce  json
+// @Success 200 {object} SubscriptionParticipationResponse "Updated participation successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 400 {object} GeneralErrorResponse "Bad Request Error"
+// @Router /v1/me/subscription-participation [put]
+func (app *application) setSubscriptionParticipationHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	var input struct {
+		Participating bool `json:"participating"`
+	}
+
+	err := app.readJSON(w, r, &input)
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	err = app.models.Subscriptions.SetAuthorParticipation(user.ID, input.Participating)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": envelope{"participating": input.Participating}}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
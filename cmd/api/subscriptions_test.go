@@ -0,0 +1,279 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments/fake"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSubscriptionTestApplication returns a test application offering an
+// active monthly plan (1) and a retired one (2). When status is not empty
+// MockReader already holds a subscription in that state, paid through
+// periodEnd and known to the provider as fake_sub_0.
+func newSubscriptionTestApplication(t *testing.T, status string, periodEnd time.Time) (*application, *fake.Provider, *mockData.SubscriptionModel) {
+	app := newTestApplication(t)
+
+	app.models.Plans = &mockData.PlanModel{Plans: []*data.Plan{
+		{ID: 1, Name: "Monthly", Price: 499, Currency: "USD", IntervalMonths: 1, IsActive: true},
+		{ID: 2, Name: "Founders", Price: 199, Currency: "USD", IntervalMonths: 1},
+	}}
+
+	subscriptions := &mockData.SubscriptionModel{}
+
+	if status != "" {
+		subscription := &data.Subscription{
+			ID:              1,
+			UserID:          mockData.MockReader.ID,
+			PlanID:          1,
+			Status:          status,
+			PaymentProvider: "fake",
+			PaymentRef:      "fake_sub_0",
+			Version:         1,
+		}
+
+		if status != data.SubscriptionIncomplete {
+			start := periodEnd.AddDate(0, -1, 0)
+			subscription.CurrentPeriodStart = &start
+			subscription.CurrentPeriodEnd = &periodEnd
+		}
+
+		subscriptions.Subscriptions = []*data.Subscription{subscription}
+	}
+
+	provider := fake.New("test-secret")
+
+	app.models.Subscriptions = subscriptions
+	app.payments = provider
+	app.config.subscriptions.gracePeriod = 72 * time.Hour
+
+	return app, provider, subscriptions
+}
+
+func TestSubscribe(t *testing.T) {
+	nextMonth := time.Now().AddDate(0, 1, 0)
+
+	tests := []struct {
+		name              string
+		current           string
+		periodEnd         time.Time
+		planID            int64
+		wantStatus        int
+		wantError         map[string]any
+		wantCancellations []string
+	}{
+		{name: "First subscription", planID: 1, wantStatus: http.StatusCreated},
+		{
+			name:              "Unfinished checkout",
+			current:           data.SubscriptionIncomplete,
+			planID:            1,
+			wantStatus:        http.StatusCreated,
+			wantCancellations: []string{"fake_sub_0"},
+		},
+		{name: "Cancelled but still paid for", current: data.SubscriptionCancelled, periodEnd: nextMonth, planID: 1, wantStatus: http.StatusCreated},
+		{
+			name:              "Lapsed after failed renewals",
+			current:           data.SubscriptionPastDue,
+			periodEnd:         time.Now().AddDate(0, -1, 0),
+			planID:            1,
+			wantStatus:        http.StatusCreated,
+			wantCancellations: []string{"fake_sub_0"},
+		},
+		{
+			name:       "Already subscribed",
+			current:    data.SubscriptionActive,
+			periodEnd:  nextMonth,
+			planID:     1,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"planId": "you already have a subscription"},
+		},
+		{
+			name:       "Retired plan",
+			planID:     2,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"planId": "plan is no longer offered"},
+		},
+		{
+			name:       "Unknown plan",
+			planID:     9,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  map[string]any{"planId": "plan not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, provider, _ := newSubscriptionTestApplication(t, tt.current, tt.periodEnd)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			token := newTestToken(t, app, mockData.MockReader)
+
+			status, _, body := ts.request(t, http.MethodPost, "/v1/me/subscription", token, map[string]int64{"planId": tt.planID})
+
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantCancellations, provider.Cancellations)
+
+			if tt.wantError != nil {
+				assert.Equal(t, tt.wantError, body["error"])
+				assert.Empty(t, provider.Subscriptions)
+				return
+			}
+
+			assert.NotEmpty(t, body["checkoutUrl"])
+			assert.Len(t, provider.Subscriptions, 1)
+
+			_, _, body = ts.request(t, http.MethodGet, "/v1/me/subscription", token, nil)
+
+			subscription, _ := body["data"].(map[string]any)
+			assert.Equal(t, data.SubscriptionIncomplete, subscription["status"], "a subscription stays incomplete until the provider confirms the payment")
+		})
+	}
+}
+
+func TestCancelSubscription(t *testing.T) {
+	nextMonth := time.Now().AddDate(0, 1, 0)
+
+	tests := []struct {
+		name              string
+		current           string
+		wantStatus        int
+		wantCancellations []string
+	}{
+		{name: "Active", current: data.SubscriptionActive, wantStatus: http.StatusOK, wantCancellations: []string{"fake_sub_0"}},
+		{name: "Past due", current: data.SubscriptionPastDue, wantStatus: http.StatusOK, wantCancellations: []string{"fake_sub_0"}},
+		{name: "Already cancelled", current: data.SubscriptionCancelled, wantStatus: http.StatusConflict},
+		{name: "No subscription", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, provider, _ := newSubscriptionTestApplication(t, tt.current, nextMonth)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, body := ts.request(t, http.MethodPost, "/v1/me/subscription/cancel", newTestToken(t, app, mockData.MockReader), nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantCancellations, provider.Cancellations)
+
+			if tt.wantStatus == http.StatusOK {
+				subscription, _ := body["data"].(map[string]any)
+				assert.Equal(t, data.SubscriptionCancelled, subscription["status"])
+				assert.Equal(t, nextMonth.UTC().Format(time.RFC3339Nano), subscription["currentPeriodEnd"], "access already paid for must be kept")
+			}
+		})
+	}
+}
+
+func TestSubscriptionWebhook(t *testing.T) {
+	periodEnd := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		current     string
+		event       payments.Event
+		wantMessage string
+		wantState   string
+		wantEnd     time.Time
+		wantGrace   bool
+	}{
+		{
+			name:        "First payment",
+			current:     data.SubscriptionIncomplete,
+			event:       payments.Event{ID: "evt_1", Type: payments.EventSubscriptionRenewed, PeriodEnd: periodEnd.AddDate(0, 1, 0)},
+			wantMessage: "event processed",
+			wantState:   data.SubscriptionActive,
+			wantEnd:     periodEnd.AddDate(0, 1, 0),
+		},
+		{
+			name:        "Renewal",
+			current:     data.SubscriptionActive,
+			event:       payments.Event{ID: "evt_2", Type: payments.EventSubscriptionRenewed, PeriodEnd: periodEnd.AddDate(0, 1, 0)},
+			wantMessage: "event processed",
+			wantState:   data.SubscriptionActive,
+			wantEnd:     periodEnd.AddDate(0, 1, 0),
+		},
+		{
+			name:        "Replayed renewal",
+			current:     data.SubscriptionActive,
+			event:       payments.Event{ID: "evt_3", Type: payments.EventSubscriptionRenewed, PeriodEnd: periodEnd},
+			wantMessage: "event ignored",
+			wantState:   data.SubscriptionActive,
+			wantEnd:     periodEnd,
+		},
+		{
+			name:        "Failed renewal",
+			current:     data.SubscriptionActive,
+			event:       payments.Event{ID: "evt_4", Type: payments.EventSubscriptionRenewalFailed},
+			wantMessage: "event processed",
+			wantState:   data.SubscriptionPastDue,
+			wantEnd:     periodEnd,
+			wantGrace:   true,
+		},
+		{
+			name:        "Renewal after cancelling",
+			current:     data.SubscriptionCancelled,
+			event:       payments.Event{ID: "evt_5", Type: payments.EventSubscriptionRenewed, PeriodEnd: periodEnd.AddDate(0, 1, 0)},
+			wantMessage: "event ignored",
+			wantState:   data.SubscriptionCancelled,
+			wantEnd:     periodEnd,
+		},
+		{
+			name:        "Cancelled by the provider",
+			current:     data.SubscriptionPastDue,
+			event:       payments.Event{ID: "evt_6", Type: payments.EventSubscriptionCancelled},
+			wantMessage: "event processed",
+			wantState:   data.SubscriptionCancelled,
+			wantEnd:     periodEnd,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, provider, subscriptions := newSubscriptionTestApplication(t, tt.current, periodEnd)
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			tt.event.PaymentRef = "fake_sub_0"
+
+			status, body := ts.postWebhook(t, provider, tt.event, "")
+
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, tt.wantMessage, body["message"])
+
+			subscription := subscriptions.Subscriptions[0]
+
+			assert.Equal(t, tt.wantState, subscription.Status)
+
+			if assert.NotNil(t, subscription.CurrentPeriodEnd) {
+				assert.True(t, tt.wantEnd.Equal(*subscription.CurrentPeriodEnd), "period ends %v, want %v", *subscription.CurrentPeriodEnd, tt.wantEnd)
+			}
+
+			if tt.wantGrace {
+				assert.Equal(t, periodEnd.Add(72*time.Hour), *subscription.GraceUntil)
+			} else {
+				assert.Nil(t, subscription.GraceUntil)
+			}
+		})
+	}
+}
+
+func TestSubscriptionWebhookUnknownRef(t *testing.T) {
+	app, provider, _ := newSubscriptionTestApplication(t, "", time.Time{})
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	status, _ := ts.postWebhook(t, provider, payments.Event{ID: "evt_1", Type: payments.EventSubscriptionRenewed, PaymentRef: "fake_sub_9", PeriodEnd: time.Now()}, "")
+
+	assert.Equal(t, http.StatusNotFound, status)
+}
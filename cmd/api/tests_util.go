@@ -3,26 +3,67 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
 	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
 	mockMailer "github.com/Kaungmyatkyaw2/book-store-api/internal/mailer/mock"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments/fake"
 	"github.com/hashicorp/go-hclog"
 )
 
 func newTestApplication(t *testing.T) *application {
 
-	return &application{
+	books := &mockData.BookModel{}
+	cart := &mockData.CartModel{Books: books}
+
+	app := &application{
 		logger: hclog.Default(),
 		models: data.Models{
-			Users:  &mockData.UserModel{},
-			Tokens: &mockData.TokenModel{},
+			Users:         &mockData.UserModel{},
+			Tokens:        &mockData.TokenModel{},
+			Books:         books,
+			Cart:          cart,
+			Orders:        &mockData.OrderModel{Cart: cart},
+			Chapters:      &mockData.ChapterModel{},
+			Collaborators: &mockData.CollaboratorModel{},
+			Entitlements:  &mockData.EntitlementModel{},
+			Reviews:       &mockData.ReviewModel{},
+			Comments:      &mockData.CommentModel{},
+			Series:        &mockData.SeriesModel{},
+			Search:        &mockData.SearchModel{},
+			Plans:         &mockData.PlanModel{},
+			Subscriptions: &mockData.SubscriptionModel{},
+			Ledger:        &mockData.LedgerModel{},
+			Shelves:       &mockData.ShelfModel{},
+			Progress:      &mockData.ProgressModel{},
+			Annotations:   &mockData.AnnotationModel{},
+			Revisions:     &mockData.ChapterRevisionModel{},
+			Autosaves:     &mockData.AutosaveModel{},
 		},
-		mailer: mockMailer.Mailer{},
+		mailer:   mockMailer.Mailer{},
+		payments: fake.New("test-secret"),
+	}
+
+	app.config.jwt.secret = "test-secret"
+
+	return app
+}
+
+// newTestToken returns an access token that signs the user in to app.
+func newTestToken(t *testing.T, app *application, user *data.User) string {
+	t.Helper()
+
+	token, err := app.createJWTToken(user.ID, time.Now().Add(time.Hour).Unix())
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
 	}
+
+	return token
 }
 
 type testServer struct {
@@ -83,6 +124,49 @@ func (ts *testServer) post(t *testing.T, urlPath string, body interface{}) (int,
 	return rs.StatusCode, rs.Header, data
 }
 
+// request sends a request with an optional JSON body, authenticated with
+// token unless it is empty, and decodes the JSON response.
+func (ts *testServer) request(t *testing.T, method, urlPath, token string, body any) (int, http.Header, map[string]any) {
+	t.Helper()
+
+	var reqBody io.Reader
+
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to marshal request body: %v", err)
+		}
+
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequest(method, ts.URL+urlPath, reqBody)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rs, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	defer rs.Body.Close()
+
+	var data map[string]any
+	if err := json.NewDecoder(rs.Body).Decode(&data); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	return rs.StatusCode, rs.Header, data
+}
+
 func structToMap(in interface{}) map[string]interface{} {
 	b, _ := json.Marshal(in)
 
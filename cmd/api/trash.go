@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+// GetTrash godoc
+// @Summary Get my trash
+// @Description Get books and chapters deleted by the current user that can still be restored
+// @Tags Trash
+// @Produce  json
+// @Success 200 {object} TrashResponse "Fetched trash successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Router /v1/me/trash [get]
+func (app *application) getTrashHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	books, err := app.models.Books.GetTrashedByUser(user.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	chapters, err := app.models.Chapters.GetTrashedByUser(user.ID)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": envelope{"books": books, "chapters": chapters}}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// RestoreBook godoc
+// @Summary Restore Book
+// @Description Restore a book from the trash together with its chapters
+// @Tags Trash
+// @Produce  json
+// @Param id path int true "Book ID"
+// @Success 200 {object} BookResponse "Restored book successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Book not found in trash"
+// @Router /v1/books/{id}/restore [post]
+func (app *application) restoreBookHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	book, err := app.models.Books.GetTrashed(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	permitted, err := app.hasBookRole(user, book, data.RoleOwner)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	err = app.models.Books.Restore(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	book.DeletedAt = nil
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": book}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// RestoreChapter godoc
+// @Summary Restore Chapter
+// @Description Restore a chapter from the trash. The chapter's book must not be in the trash.
+// @Tags Trash
+// @Produce  json
+// @Param id path int true "Chapter ID"
+// @Success 200 {object} GetChapterResponse "Restored chapter successfully"
+// @Failure 500 {object} InternalServerErrorResponse "Internal Server Error"
+// @Failure 401 {object} GeneralErrorResponse "Unauthenticated Error"
+// @Failure 403 {object} GeneralErrorResponse "Permission Error"
+// @Failure 404 {object} GeneralErrorResponse "Chapter not found in trash"
+// @Failure 409 {object} GeneralErrorResponse "Book is in the trash"
+// @Router /v1/chapters/{id}/restore [post]
+func (app *application) restoreChapterHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+
+	id, err := app.readIDParam(r)
+
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	chapter, err := app.models.Chapters.GetTrashed(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	book, err := app.models.Books.Get(chapter.BookID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.errorResponse(w, r, http.StatusConflict, "the chapter's book is in the trash, restore the book first")
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+
+		return
+	}
+
+	permitted, err := app.hasBookRole(user, book, data.RoleEditor)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !permitted {
+		app.notPermittedResponse(w, r)
+		return
+	}
+
+	err = app.models.Chapters.Restore(id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	chapter.DeletedAt = nil
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"data": chapter}, nil)
+
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// purgeTrash permanently deletes books and chapters that have been in the
+// trash longer than the configured retention period. It runs periodically
+// for the lifetime of the server.
+func (app *application) purgeTrash() {
+	before := time.Now().Add(-app.config.trash.retention)
+
+	books, err := app.models.Books.Purge(before)
+	if err != nil {
+		app.logger.Error("purging trashed books", "error", err)
+		return
+	}
+
+	chapters, err := app.models.Chapters.Purge(before)
+	if err != nil {
+		app.logger.Error("purging trashed chapters", "error", err)
+		return
+	}
+
+	if books > 0 || chapters > 0 {
+		app.logger.Info("purged trash", "books", books, "chapters", chapters)
+	}
+}
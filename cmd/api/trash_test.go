@@ -0,0 +1,159 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	mockData "github.com/Kaungmyatkyaw2/book-store-api/internal/data/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreBook(t *testing.T) {
+	deletedAt := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		user       *data.User
+		role       string
+		bookID     string
+		wantStatus int
+	}{
+		{name: "Owner", user: mockData.MockAuthor, bookID: "1", wantStatus: http.StatusOK},
+		{name: "Owner collaborator", user: mockData.MockReader, role: data.RoleOwner, bookID: "1", wantStatus: http.StatusOK},
+		{name: "Editor collaborator", user: mockData.MockReader, role: data.RoleEditor, bookID: "1", wantStatus: http.StatusForbidden},
+		{name: "Stranger", user: mockData.MockReader, bookID: "1", wantStatus: http.StatusForbidden},
+		{name: "Anonymous", bookID: "1", wantStatus: http.StatusUnauthorized},
+		{name: "Not in trash", user: mockData.MockAuthor, bookID: "2", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			books := &mockData.BookModel{Books: []*data.Book{
+				{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, DeletedAt: &deletedAt},
+				{ID: 2, Title: "Emma", UserID: mockData.MockAuthor.ID},
+			}}
+
+			app.models.Books = books
+
+			if tt.role != "" {
+				app.models.Collaborators = &mockData.CollaboratorModel{Collaborators: []*data.Collaborator{
+					{BookID: 1, UserID: tt.user.ID, Role: tt.role, Status: data.InvitationAccepted},
+				}}
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			token := ""
+			if tt.user != nil {
+				token = newTestToken(t, app, tt.user)
+			}
+
+			status, _, body := ts.request(t, http.MethodPost, "/v1/books/"+tt.bookID+"/restore", token, nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			_, err := books.Get(1)
+
+			if tt.wantStatus != http.StatusOK {
+				assert.ErrorIs(t, err, data.ErrRecordNotFound, "book should still be in the trash")
+				return
+			}
+
+			assert.NoError(t, err, "book should be out of the trash")
+
+			book, _ := body["data"].(map[string]any)
+			assert.NotContains(t, book, "deletedAt")
+		})
+	}
+}
+
+func TestRestoreChapter(t *testing.T) {
+	deletedAt := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		user        *data.User
+		role        string
+		bookTrashed bool
+		wantStatus  int
+	}{
+		{name: "Owner", user: mockData.MockAuthor, wantStatus: http.StatusOK},
+		{name: "Editor collaborator", user: mockData.MockReader, role: data.RoleEditor, wantStatus: http.StatusOK},
+		{name: "Viewer collaborator", user: mockData.MockReader, role: data.RoleViewer, wantStatus: http.StatusForbidden},
+		{name: "Stranger", user: mockData.MockReader, wantStatus: http.StatusForbidden},
+		{name: "Book in trash", user: mockData.MockAuthor, bookTrashed: true, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApplication(t)
+
+			book := &data.Book{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID}
+
+			if tt.bookTrashed {
+				book.DeletedAt = &deletedAt
+			}
+
+			chapters := &mockData.ChapterModel{Chapters: []*data.Chapter{
+				{ID: 1, ChapterNo: 1, Title: "Prologue", BookID: 1, UserID: mockData.MockAuthor.ID, DeletedAt: &deletedAt},
+			}}
+
+			app.models.Books = &mockData.BookModel{Books: []*data.Book{book}}
+			app.models.Chapters = chapters
+
+			if tt.role != "" {
+				app.models.Collaborators = &mockData.CollaboratorModel{Collaborators: []*data.Collaborator{
+					{BookID: 1, UserID: tt.user.ID, Role: tt.role, Status: data.InvitationAccepted},
+				}}
+			}
+
+			ts := newTestServer(t, app.routes())
+			defer ts.Close()
+
+			status, _, _ := ts.request(t, http.MethodPost, "/v1/chapters/1/restore", newTestToken(t, app, tt.user), nil)
+
+			assert.Equal(t, tt.wantStatus, status)
+
+			_, err := chapters.Get(1)
+
+			if tt.wantStatus == http.StatusOK {
+				assert.NoError(t, err, "chapter should be out of the trash")
+			} else {
+				assert.ErrorIs(t, err, data.ErrRecordNotFound, "chapter should still be in the trash")
+			}
+		})
+	}
+}
+
+func TestGetTrash(t *testing.T) {
+	deletedAt := time.Date(2025, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	app := newTestApplication(t)
+
+	app.models.Books = &mockData.BookModel{Books: []*data.Book{
+		{ID: 1, Title: "Dune", UserID: mockData.MockAuthor.ID, DeletedAt: &deletedAt},
+		{ID: 2, Title: "Emma", UserID: mockData.MockAuthor.ID},
+		{ID: 3, Title: "Ulysses", UserID: mockData.MockReader.ID, DeletedAt: &deletedAt},
+	}}
+
+	ts := newTestServer(t, app.routes())
+	defer ts.Close()
+
+	status, _, body := ts.request(t, http.MethodGet, "/v1/me/trash", newTestToken(t, app, mockData.MockAuthor), nil)
+
+	assert.Equal(t, http.StatusOK, status)
+
+	trash, _ := body["data"].(map[string]any)
+	books, _ := trash["books"].([]any)
+
+	if assert.Len(t, books, 1) {
+		assert.Equal(t, float64(1), books[0].(map[string]any)["id"])
+	}
+
+	assert.Empty(t, trash["chapters"])
+}
@@ -0,0 +1,490 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+const (
+	AnnotationHighlight = "highlight"
+	AnnotationBookmark  = "bookmark"
+	AnnotationNote      = "note"
+)
+
+var HighlightColors = []string{"yellow", "green", "blue", "pink", "purple"}
+
+var ErrInvalidAnchor = errors.New("anchor is outside the chapter content")
+
+// anchorContext is how many characters of surrounding text are kept on each
+// side of an anchor to tell repeated passages apart when re-locating it.
+const anchorContext = 32
+
+// Anchor pins an annotation to a chapter's text. Offsets count Unicode
+// characters, not bytes. The fingerprint hashes the quoted text, or the
+// surrounding context for a zero-width bookmark, and is what decides whether
+// the anchor still points at the passage it was made on.
+type Anchor struct {
+	Start       int    `json:"startOffset"`
+	End         int    `json:"endOffset"`
+	Quote       string `json:"quote"`
+	Prefix      string `json:"-"`
+	Suffix      string `json:"-"`
+	Fingerprint string `json:"-"`
+}
+
+// NewAnchor anchors the characters from start to end of content.
+func NewAnchor(content string, start, end int) (Anchor, error) {
+	text := []rune(content)
+
+	if start < 0 || start > end || end > len(text) {
+		return Anchor{}, ErrInvalidAnchor
+	}
+
+	return anchorAt(text, start, end), nil
+}
+
+// RelocateAnchor finds where the anchored passage is in a new version of the
+// content. An anchor whose fingerprint still matches at its offsets is kept.
+// Otherwise a ranged anchor moves to the occurrence of its quote whose
+// surroundings best match the old ones, and a bookmark moves to the position
+// whose surroundings match best. It reports false when the passage is gone.
+func RelocateAnchor(content string, a Anchor) (Anchor, bool) {
+	text := []rune(content)
+
+	if a.Start >= 0 && a.Start <= a.End && a.End <= len(text) && fingerprintAt(text, a.Start, a.End) == a.Fingerprint {
+		return anchorAt(text, a.Start, a.End), true
+	}
+
+	quote := []rune(a.Quote)
+	prefix := []rune(a.Prefix)
+	suffix := []rune(a.Suffix)
+
+	var candidates []int
+
+	if len(quote) > 0 {
+		candidates = occurrences(text, quote)
+	} else {
+		for p := 0; p <= len(text); p++ {
+			candidates = append(candidates, p)
+		}
+	}
+
+	best, bestScore, bestDistance := -1, -1, 0
+
+	for _, start := range candidates {
+		end := start + len(quote)
+
+		score := commonSuffix(prefix, text[max(0, start-len(prefix)):start]) +
+			commonPrefix(suffix, text[end:min(len(text), end+len(suffix))])
+
+		distance := start - a.Start
+		if distance < 0 {
+			distance = -distance
+		}
+
+		if score > bestScore || (score == bestScore && distance < bestDistance) {
+			best, bestScore, bestDistance = start, score, distance
+		}
+	}
+
+	// A bookmark has nothing but its surroundings to go on, so at least half
+	// of them must still be there.
+	if best < 0 || (len(quote) == 0 && bestScore*2 < len(prefix)+len(suffix)) {
+		return a, false
+	}
+
+	return anchorAt(text, best, best+len(quote)), true
+}
+
+func anchorAt(text []rune, start, end int) Anchor {
+	return Anchor{
+		Start:       start,
+		End:         end,
+		Quote:       string(text[start:end]),
+		Prefix:      string(text[max(0, start-anchorContext):start]),
+		Suffix:      string(text[end:min(len(text), end+anchorContext)]),
+		Fingerprint: fingerprintAt(text, start, end),
+	}
+}
+
+func fingerprintAt(text []rune, start, end int) string {
+	var sum [32]byte
+
+	if start < end {
+		sum = sha256.Sum256([]byte(string(text[start:end])))
+	} else {
+		around := string(text[max(0, start-anchorContext):start]) + "\x00" + string(text[start:min(len(text), start+anchorContext)])
+		sum = sha256.Sum256([]byte(around))
+	}
+
+	return hex.EncodeToString(sum[:])
+}
+
+func occurrences(text, needle []rune) []int {
+	var found []int
+
+	for i := 0; i+len(needle) <= len(text); i++ {
+		if string(text[i:i+len(needle)]) == string(needle) {
+			found = append(found, i)
+		}
+	}
+
+	return found
+}
+
+func commonPrefix(a, b []rune) int {
+	n := 0
+
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+
+	return n
+}
+
+func commonSuffix(a, b []rune) int {
+	n := 0
+
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+
+	return n
+}
+
+type Annotation struct {
+	ID           int64  `json:"id"`
+	UserID       int64  `json:"userId"`
+	BookID       int64  `json:"bookId"`
+	ChapterID    int64  `json:"chapterId"`
+	ChapterTitle string `json:"chapterTitle,omitempty"`
+	Kind         string `json:"kind"`
+	Anchor
+	Note      string    `json:"note"`
+	Color     string    `json:"color"`
+	Orphaned  bool      `json:"orphaned"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Version   int       `json:"-"`
+}
+
+func ValidateAnnotation(v *validator.Validator, annotation *Annotation) {
+	v.Check(validator.In(annotation.Kind, AnnotationHighlight, AnnotationBookmark, AnnotationNote), "kind", "must be highlight, bookmark or note")
+	v.Check(len(annotation.Note) <= 5000, "note", "must not be more than 5000 bytes long")
+
+	switch annotation.Kind {
+	case AnnotationHighlight:
+		v.Check(annotation.End > annotation.Start, "endOffset", "must be greater than startOffset")
+		v.Check(validator.In(annotation.Color, HighlightColors...), "color", "must be a supported highlight color")
+	case AnnotationNote:
+		v.Check(annotation.Note != "", "note", "must be provided")
+	}
+}
+
+// AnnotationsMarkdown renders a user's highlights and notes on a book as a
+// Markdown document, grouped by chapter in the order given. Bookmarks are
+// left out.
+func AnnotationsMarkdown(bookTitle string, annotations []*Annotation) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n", bookTitle)
+
+	chapterID := int64(0)
+
+	for _, annotation := range annotations {
+		if annotation.Kind == AnnotationBookmark {
+			continue
+		}
+
+		if annotation.ChapterID != chapterID {
+			chapterID = annotation.ChapterID
+			fmt.Fprintf(&b, "\n## %s\n", annotation.ChapterTitle)
+		}
+
+		b.WriteString("\n")
+
+		if annotation.Quote != "" {
+			for _, line := range strings.Split(annotation.Quote, "\n") {
+				fmt.Fprintf(&b, "> %s\n", line)
+			}
+		}
+
+		if annotation.Orphaned {
+			b.WriteString(">\n> *This passage has changed since it was highlighted.*\n")
+		}
+
+		if annotation.Note != "" {
+			if annotation.Quote != "" {
+				b.WriteString("\n")
+			}
+
+			fmt.Fprintf(&b, "%s\n", annotation.Note)
+		}
+	}
+
+	return b.String()
+}
+
+type AnnotationModel struct {
+	DB *sql.DB
+}
+
+const annotationColumns = `
+	a.id, a.user_id, a.book_id, a.chapter_id, c.title, a.kind, a.start_offset, a.end_offset, a.quote, a.prefix, a.suffix,
+	a.fingerprint, a.note, a.color, a.orphaned, a.created_at, a.updated_at, a.version`
+
+func scanAnnotation(row interface{ Scan(...any) error }, annotation *Annotation) error {
+	return row.Scan(
+		&annotation.ID,
+		&annotation.UserID,
+		&annotation.BookID,
+		&annotation.ChapterID,
+		&annotation.ChapterTitle,
+		&annotation.Kind,
+		&annotation.Start,
+		&annotation.End,
+		&annotation.Quote,
+		&annotation.Prefix,
+		&annotation.Suffix,
+		&annotation.Fingerprint,
+		&annotation.Note,
+		&annotation.Color,
+		&annotation.Orphaned,
+		&annotation.CreatedAt,
+		&annotation.UpdatedAt,
+		&annotation.Version,
+	)
+}
+
+func (m AnnotationModel) Insert(annotation *Annotation) error {
+	query := `
+		INSERT INTO annotations (user_id, book_id, chapter_id, kind, start_offset, end_offset, quote, prefix, suffix, fingerprint, note, color)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at, updated_at, version
+	`
+
+	args := []any{
+		annotation.UserID,
+		annotation.BookID,
+		annotation.ChapterID,
+		annotation.Kind,
+		annotation.Start,
+		annotation.End,
+		annotation.Quote,
+		annotation.Prefix,
+		annotation.Suffix,
+		annotation.Fingerprint,
+		annotation.Note,
+		annotation.Color,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&annotation.ID, &annotation.CreatedAt, &annotation.UpdatedAt, &annotation.Version)
+}
+
+func (m AnnotationModel) Get(id int64) (*Annotation, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT ` + annotationColumns + `
+		FROM annotations a
+		INNER JOIN chapters c ON c.id = a.chapter_id
+		WHERE a.id = $1
+	`
+
+	var annotation Annotation
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := scanAnnotation(m.DB.QueryRowContext(ctx, query, id), &annotation)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &annotation, nil
+}
+
+// GetByChapter lists the user's annotations on a chapter in reading order.
+func (m AnnotationModel) GetByChapter(userID, chapterID int64) ([]*Annotation, error) {
+	query := `SELECT ` + annotationColumns + `
+		FROM annotations a
+		INNER JOIN chapters c ON c.id = a.chapter_id
+		WHERE a.user_id = $1 AND a.chapter_id = $2
+		ORDER BY a.start_offset, a.end_offset, a.id
+	`
+
+	return m.query(query, userID, chapterID)
+}
+
+// GetByBook lists the user's annotations on a book's live chapters in
+// reading order.
+func (m AnnotationModel) GetByBook(userID, bookID int64) ([]*Annotation, error) {
+	query := `SELECT ` + annotationColumns + `
+		FROM annotations a
+		INNER JOIN chapters c ON c.id = a.chapter_id
+		WHERE a.user_id = $1 AND a.book_id = $2 AND c.deleted_at IS NULL
+		ORDER BY c.chapter_no, c.id, a.start_offset, a.end_offset, a.id
+	`
+
+	return m.query(query, userID, bookID)
+}
+
+func (m AnnotationModel) query(query string, args ...any) ([]*Annotation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	annotations := []*Annotation{}
+
+	for rows.Next() {
+		var annotation Annotation
+
+		if err := scanAnnotation(rows, &annotation); err != nil {
+			return nil, err
+		}
+
+		annotations = append(annotations, &annotation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return annotations, nil
+}
+
+// Update saves the annotation's note and color. Anchors are only moved by
+// relocateAnnotations.
+func (m AnnotationModel) Update(annotation *Annotation) error {
+	query := `
+		UPDATE annotations
+		SET note = $1, color = $2, updated_at = NOW(), version = version + 1
+		WHERE id = $3 AND version = $4
+		RETURNING updated_at, version
+	`
+
+	args := []any{annotation.Note, annotation.Color, annotation.ID, annotation.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&annotation.UpdatedAt, &annotation.Version)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m AnnotationModel) Delete(id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, `DELETE FROM annotations WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// relocateAnnotations re-anchors every annotation on the chapter against its
+// new content. Annotations whose passage is gone are marked orphaned rather
+// than deleted so the reader keeps their note.
+func relocateAnnotations(ctx context.Context, tx *sql.Tx, chapterID int64, content string) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, start_offset, end_offset, quote, prefix, suffix, fingerprint, orphaned
+		FROM annotations
+		WHERE chapter_id = $1
+		FOR UPDATE
+	`, chapterID)
+
+	if err != nil {
+		return err
+	}
+
+	type stored struct {
+		id       int64
+		anchor   Anchor
+		orphaned bool
+	}
+
+	var annotations []stored
+
+	for rows.Next() {
+		var s stored
+
+		err := rows.Scan(&s.id, &s.anchor.Start, &s.anchor.End, &s.anchor.Quote, &s.anchor.Prefix, &s.anchor.Suffix, &s.anchor.Fingerprint, &s.orphaned)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		annotations = append(annotations, s)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range annotations {
+		anchor, found := RelocateAnchor(content, s.anchor)
+
+		if anchor == s.anchor && found != s.orphaned {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE annotations
+			SET start_offset = $1, end_offset = $2, prefix = $3, suffix = $4, fingerprint = $5, orphaned = $6
+			WHERE id = $7
+		`, anchor.Start, anchor.End, anchor.Prefix, anchor.Suffix, anchor.Fingerprint, !found, s.id)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelocateAnchor(t *testing.T) {
+	content := "The cat sat on the mat. The cat ran off into the night."
+
+	anchor, err := NewAnchor(content, 24, 31)
+	assert.NoError(t, err)
+	assert.Equal(t, "The cat", anchor.Quote)
+
+	// Unchanged text keeps the anchor where it is.
+	same, ok := RelocateAnchor(content, anchor)
+	assert.True(t, ok)
+	assert.Equal(t, anchor, same)
+
+	// Inserting text earlier shifts the anchor onto the second "The cat",
+	// not the first, because its surroundings match.
+	edited := "Once upon a time. " + content
+	moved, ok := RelocateAnchor(edited, anchor)
+	assert.True(t, ok)
+	assert.Equal(t, 42, moved.Start)
+	assert.Equal(t, "The cat", moved.Quote)
+	assert.Equal(t, " ran off", moved.Suffix[:8])
+
+	// Removing the passage orphans it.
+	_, ok = RelocateAnchor("Nothing to see here.", anchor)
+	assert.False(t, ok)
+
+	_, err = NewAnchor(content, 10, 500)
+	assert.ErrorIs(t, err, ErrInvalidAnchor)
+}
+
+func TestRelocateBookmark(t *testing.T) {
+	content := "Chapter one begins here. It was a dark and stormy night."
+
+	bookmark, err := NewAnchor(content, 25, 25)
+	assert.NoError(t, err)
+
+	moved, ok := RelocateAnchor("Foreword. "+content, bookmark)
+	assert.True(t, ok)
+	assert.Equal(t, 35, moved.Start)
+	assert.Equal(t, moved.Start, moved.End)
+
+	_, ok = RelocateAnchor("Completely different text now.", bookmark)
+	assert.False(t, ok)
+}
+
+func TestAnnotationsMarkdown(t *testing.T) {
+	annotations := []*Annotation{
+		{ChapterID: 1, ChapterTitle: "Beginnings", Kind: AnnotationHighlight, Anchor: Anchor{Quote: "first line\nsecond line"}},
+		{ChapterID: 1, ChapterTitle: "Beginnings", Kind: AnnotationBookmark},
+		{ChapterID: 2, ChapterTitle: "Endings", Kind: AnnotationNote, Anchor: Anchor{Quote: "gone"}, Note: "Loved this.", Orphaned: true},
+	}
+
+	expected := "# My Book\n" +
+		"\n## Beginnings\n" +
+		"\n> first line\n> second line\n" +
+		"\n## Endings\n" +
+		"\n> gone\n>\n> *This passage has changed since it was highlighted.*\n" +
+		"\nLoved this.\n"
+
+	assert.Equal(t, expected, AnnotationsMarkdown("My Book", annotations))
+}
@@ -0,0 +1,132 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// ChapterAutosave is an editor's unsaved work on a chapter. It is kept apart
+// from the chapter so frequent autosaves neither bump the chapter's version
+// nor add revisions. BaseVersion is the chapter version the edit started
+// from.
+type ChapterAutosave struct {
+	ChapterID     int64     `json:"chapterId"`
+	BaseVersion   int       `json:"baseVersion"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	Content       string    `json:"content"`
+	ContentFormat string    `json:"contentFormat"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type AutosaveModel struct {
+	DB *sql.DB
+}
+
+func ValidateAutosave(v *validator.Validator, autosave *ChapterAutosave) {
+	v.Check(autosave.BaseVersion > 0, "baseVersion", "must be provided")
+	v.Check(len(autosave.Title) <= 200, "title", "must not be more than 200 bytes long")
+	v.Check(len(autosave.Description) <= 500, "description", "must not be more than 500 bytes long")
+	v.Check(validator.In(autosave.ContentFormat, ContentFormatMarkdown, ContentFormatText), "contentFormat", "must be markdown or text")
+}
+
+// Save stores the user's autosave for the chapter, replacing any earlier one.
+func (m AutosaveModel) Save(userID int64, autosave *ChapterAutosave) error {
+	query := `
+		INSERT INTO chapter_autosaves (user_id, chapter_id, base_version, title, description, content, content_format)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, chapter_id) DO UPDATE
+		SET base_version = EXCLUDED.base_version, title = EXCLUDED.title, description = EXCLUDED.description,
+			content = EXCLUDED.content, content_format = EXCLUDED.content_format, updated_at = NOW()
+		RETURNING updated_at
+	`
+
+	args := []any{userID, autosave.ChapterID, autosave.BaseVersion, autosave.Title, autosave.Description, autosave.Content, autosave.ContentFormat}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&autosave.UpdatedAt)
+}
+
+func (m AutosaveModel) Get(userID, chapterID int64) (*ChapterAutosave, error) {
+	query := `
+		SELECT chapter_id, base_version, title, description, content, content_format, updated_at
+		FROM chapter_autosaves
+		WHERE user_id = $1 AND chapter_id = $2
+	`
+
+	var autosave ChapterAutosave
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, userID, chapterID).Scan(
+		&autosave.ChapterID,
+		&autosave.BaseVersion,
+		&autosave.Title,
+		&autosave.Description,
+		&autosave.Content,
+		&autosave.ContentFormat,
+		&autosave.UpdatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &autosave, nil
+}
+
+func (m AutosaveModel) Delete(userID, chapterID int64) error {
+	query := `
+		DELETE FROM chapter_autosaves
+		WHERE user_id = $1 AND chapter_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, chapterID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Purge deletes autosaves that have not been touched since before.
+func (m AutosaveModel) Purge(before time.Time) (int64, error) {
+	query := `
+		DELETE FROM chapter_autosaves
+		WHERE updated_at < $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,24 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAutosave(t *testing.T) {
+	autosave := &ChapterAutosave{BaseVersion: 3, ContentFormat: ContentFormatMarkdown}
+
+	v := validator.New()
+	ValidateAutosave(v, autosave)
+	assert.True(t, v.IsValid(), "an untitled draft can be autosaved")
+
+	autosave.BaseVersion = 0
+	autosave.ContentFormat = "html"
+
+	v = validator.New()
+	ValidateAutosave(v, autosave)
+	assert.Contains(t, v.Errors, "baseVersion")
+	assert.Contains(t, v.Errors, "contentFormat")
+}
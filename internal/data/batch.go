@@ -0,0 +1,258 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+const (
+	BatchCreated    = "created"
+	BatchUpdated    = "updated"
+	BatchDeleted    = "deleted"
+	BatchFailed     = "failed"
+	BatchRolledBack = "rolledBack"
+)
+
+// MaxBatchOperations caps how many chapter operations one batch can carry.
+const MaxBatchOperations = 500
+
+var ErrBatchFailed = errors.New("one or more batch operations failed")
+
+// ChapterOp is one operation of a chapter batch. Create uses the fields as
+// given; update only changes the fields that are set. ID names the chapter
+// to update or delete, and Version, when set, must match the chapter's
+// current version.
+type ChapterOp struct {
+	Op            string  `json:"op"`
+	ID            int64   `json:"id"`
+	Version       *int    `json:"version"`
+	Title         *string `json:"title"`
+	Description   *string `json:"description"`
+	Content       *string `json:"content"`
+	ContentFormat *string `json:"contentFormat"`
+	Access        *string `json:"access"`
+}
+
+// ChapterOpResult reports what happened to one operation of a batch, by its
+// position in the request.
+type ChapterOpResult struct {
+	Index   int               `json:"index"`
+	Op      string            `json:"op"`
+	Status  string            `json:"status"`
+	ID      int64             `json:"id,omitempty"`
+	Chapter *Chapter          `json:"chapter,omitempty"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+func ValidateChapterBatch(v *validator.Validator, ops []ChapterOp) {
+	v.Check(len(ops) > 0, "operations", "must be provided")
+	v.Check(len(ops) <= MaxBatchOperations, "operations", "must not contain more than 500 operations")
+}
+
+// applyChapterOp copies the fields set on the operation onto the chapter.
+func applyChapterOp(chapter *Chapter, op ChapterOp) {
+	if op.Title != nil {
+		chapter.Title = *op.Title
+	}
+
+	if op.Description != nil {
+		chapter.Description = *op.Description
+	}
+
+	if op.Content != nil {
+		chapter.Content = op.Content
+	}
+
+	if op.ContentFormat != nil {
+		chapter.ContentFormat = *op.ContentFormat
+	}
+
+	if op.Access != nil {
+		chapter.Access = *op.Access
+	}
+}
+
+// Batch creates, updates and deletes chapters of a book in order, in one
+// transaction. Every created or updated chapter is checked with
+// ValidateChapter. If any operation fails, nothing is saved and
+// ErrBatchFailed is returned along with the results, which carry the errors
+// of the failed operations.
+func (m ChapterModel) Batch(bookID, userID int64, ops []ChapterOp) ([]*ChapterOpResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	err = lockBook(ctx, tx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*ChapterOpResult, len(ops))
+	failed := false
+
+	for i, op := range ops {
+		result := &ChapterOpResult{Index: i, Op: op.Op, ID: op.ID}
+		results[i] = result
+
+		v := validator.New()
+
+		switch op.Op {
+		case BatchCreate:
+			chapter := &Chapter{
+				BookID:        bookID,
+				UserID:        userID,
+				Access:        ChapterAccessFree,
+				ContentFormat: ContentFormatText,
+			}
+
+			applyChapterOp(chapter, op)
+
+			if ValidateChapter(v, chapter); !v.IsValid() {
+				break
+			}
+
+			err = insertChapter(ctx, tx, chapter)
+			if err != nil {
+				return nil, err
+			}
+
+			result.ID, result.Chapter, result.Status = chapter.ID, chapter, BatchCreated
+
+		case BatchUpdate, BatchDelete:
+			chapter, err := getBookChapter(ctx, tx, bookID, op.ID)
+
+			if err != nil {
+				switch {
+				case errors.Is(err, ErrRecordNotFound):
+					v.AddError("id", "must be a chapter of this book")
+				default:
+					return nil, err
+				}
+
+				break
+			}
+
+			if op.Version != nil && *op.Version != chapter.Version {
+				v.AddError("version", "does not match the chapter's current version")
+				break
+			}
+
+			if op.Op == BatchDelete {
+				err = trashChapter(ctx, tx, chapter.ID)
+				if err != nil {
+					return nil, err
+				}
+
+				result.Status = BatchDeleted
+				break
+			}
+
+			applyChapterOp(chapter, op)
+
+			if ValidateChapter(v, chapter); !v.IsValid() {
+				break
+			}
+
+			err = updateChapter(ctx, tx, chapter)
+			if err != nil {
+				return nil, err
+			}
+
+			result.Chapter, result.Status = chapter, BatchUpdated
+
+		default:
+			v.AddError("op", "must be create, update or delete")
+		}
+
+		if !v.IsValid() {
+			result.Status, result.Errors = BatchFailed, v.Errors
+			failed = true
+		}
+	}
+
+	if failed {
+		for _, result := range results {
+			if result.Status == BatchFailed {
+				continue
+			}
+
+			result.Status, result.Chapter = BatchRolledBack, nil
+
+			if result.Op == BatchCreate {
+				result.ID = 0
+			}
+		}
+
+		return results, ErrBatchFailed
+	}
+
+	err = refreshBookLength(ctx, tx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// getBookChapter reads a chapter of the book that is not in the trash.
+// Callers must hold the book lock.
+func getBookChapter(ctx context.Context, tx *sql.Tx, bookID, id int64) (*Chapter, error) {
+	query := `
+		SELECT id, created_at, updated_at, title, description, chapter_no, content, content_format, word_count, (reading_seconds + 59) / 60, access, is_published, published_at, book_id, user_id, version
+		FROM chapters
+		WHERE id = $1 AND book_id = $2 AND deleted_at IS NULL
+	`
+
+	var chapter Chapter
+
+	err := tx.QueryRowContext(ctx, query, id, bookID).Scan(
+		&chapter.ID,
+		&chapter.CreatedAt,
+		&chapter.UpdatedAt,
+		&chapter.Title,
+		&chapter.Description,
+		&chapter.ChapterNo,
+		&chapter.Content,
+		&chapter.ContentFormat,
+		&chapter.WordCount,
+		&chapter.ReadingMinutes,
+		&chapter.Access,
+		&chapter.IsPublished,
+		&chapter.PublishedAt,
+		&chapter.BookID,
+		&chapter.UserID,
+		&chapter.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &chapter, nil
+}
@@ -0,0 +1,39 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyChapterOp(t *testing.T) {
+	content := "Old text"
+	chapter := &Chapter{Title: "One", Description: "First", Content: &content, ContentFormat: ContentFormatText, Access: ChapterAccessFree}
+
+	title := "Chapter One"
+	newContent := "# New text"
+	format := ContentFormatMarkdown
+
+	applyChapterOp(chapter, ChapterOp{Op: BatchUpdate, Title: &title, Content: &newContent, ContentFormat: &format})
+
+	assert.Equal(t, "Chapter One", chapter.Title)
+	assert.Equal(t, "First", chapter.Description, "unset fields are left alone")
+	assert.Equal(t, "# New text", *chapter.Content)
+	assert.Equal(t, ContentFormatMarkdown, chapter.ContentFormat)
+	assert.Equal(t, ChapterAccessFree, chapter.Access)
+}
+
+func TestValidateChapterBatch(t *testing.T) {
+	v := validator.New()
+	ValidateChapterBatch(v, nil)
+	assert.Contains(t, v.Errors, "operations")
+
+	v = validator.New()
+	ValidateChapterBatch(v, make([]ChapterOp, MaxBatchOperations+1))
+	assert.Contains(t, v.Errors, "operations")
+
+	v = validator.New()
+	ValidateChapterBatch(v, make([]ChapterOp, 60))
+	assert.True(t, v.IsValid())
+}
@@ -10,54 +10,112 @@ import (
 	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
 )
 
+// SupportedCurrencies lists the ISO 4217 codes books can be priced in. Prices
+// are stored in the currency's minor unit, e.g. cents.
+var SupportedCurrencies = []string{"USD", "EUR", "GBP", "SGD", "THB"}
+
+const DefaultCurrency = "USD"
+
 type Book struct {
-	ID           int64      `json:"id"`
-	Title        string     `json:"string"`
-	UserID       int64      `json:"userId"`
-	CoverPicture string     `json:"coverPicture"`
-	CreatedAt    time.Time  `json:"createdAt"`
-	IsPublished  bool       `json:"isPublished"`
-	PublishedAt  *time.Time `json:"publishedAt"`
-	Version      int        `json:"-"`
+	ID             int64       `json:"id"`
+	Title          string      `json:"string"`
+	Description    string      `json:"description"`
+	UserID         int64       `json:"userId"`
+	CoverPicture   string      `json:"coverPicture"`
+	CreatedAt      time.Time   `json:"createdAt"`
+	IsPublished    bool        `json:"isPublished"`
+	PublishedAt    *time.Time  `json:"publishedAt"`
+	Price          int64       `json:"price"`
+	Currency       string      `json:"currency"`
+	RatingAverage  float64     `json:"ratingAverage"`
+	RatingCount    int         `json:"ratingCount"`
+	WordCount      int         `json:"wordCount"`
+	ReadingMinutes int         `json:"readingMinutes"`
+	DeletedAt      *time.Time  `json:"deletedAt,omitempty"`
+	Series         *BookSeries `json:"series,omitempty"`
+	Version        int         `json:"-"`
 }
 
 func ValidateBook(v *validator.Validator, book *Book) {
 	v.Check(book.Title != "", "title", "must be provided")
 	v.Check(len(book.Title) <= 500, "title", "must not be more than 500 bytes long")
 
+	if book.Description != "" {
+		v.Check(len(book.Description) <= 2000, "description", "must not be more than 2000 bytes long")
+	}
+
+	v.Check(book.Price >= 0, "price", "must not be negative")
+	v.Check(book.Price <= 100_000_00, "price", "must not be more than 100000.00")
+	v.Check(validator.In(book.Currency, SupportedCurrencies...), "currency", "must be a supported currency")
+
 }
 
 type BookModel struct {
 	DB *sql.DB
 }
 
+var bookSortKeys = map[string]sortKey{
+	"id":           {expr: "id", cast: "bigint"},
+	"title":        {expr: "title", cast: "text"},
+	"created_at":   {expr: "created_at", cast: "timestamptz"},
+	"published_at": {expr: "coalesce(published_at, '-infinity')", cast: "timestamp"},
+	"rating":       {expr: "rating_average", cast: "numeric"},
+	"length":       {expr: "word_count", cast: "integer"},
+}
+
 func getAllBooks(m BookModel, title string, filters Filters, userID int64) ([]*Book, *Metadata, error) {
-	query := `
-	SELECT count(*) OVER(), id,created_at,title,cover_picture,user_id,version, is_published, published_at 
+	fromWhere := `
 	FROM books	
-	WHERE (to_tsvector('simple', title) @@ plainto_tsquery('simple',$1) OR $1 = '') AND is_published = true
+	WHERE (to_tsvector('simple', title) @@ plainto_tsquery('simple',$1) OR $1 = '') AND is_published = true AND deleted_at IS NULL
 	`
 
 	args := []any{title}
 
 	if userID != -1 {
-		query += ` AND user_id = $2`
+		fromWhere += ` AND user_id = $2`
 		args = append(args, userID)
 	}
 
-	argsLength := len(args)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	totalRecords, err := countRecords(ctx, m.DB, filters.Count, fromWhere, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := bookSortKeys[filters.sortColumn()]
+
+	var cursor *Cursor
+
+	if filters.Cursor != "" {
+		c, err := DecodeCursor(filters.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		cursor = &c
+	}
+
+	cond, orderBy := filters.keyset(key, cursor, len(args)+1)
+
+	query := fmt.Sprintf(`
+	SELECT id,created_at,title,coalesce(description, ''),cover_picture,user_id,version, is_published, published_at, price, currency, rating_average, rating_count, word_count, (reading_seconds + 59) / 60, (%s)::text
+	%s`, key.expr, fromWhere)
+
+	if cursor != nil {
+		query += ` AND ` + cond
+		args = append(args, cursor.Value, cursor.ID)
+	}
 
+	// One extra row is fetched to tell whether another page follows.
 	query += fmt.Sprintf(`
-		ORDER BY %s %s, id ASC
+		%s
 		LIMIT $%d OFFSET $%d
-	`, filters.sortColumn(), filters.sortDirecton(), argsLength+1, argsLength+2)
+	`, orderBy, len(args)+1, len(args)+2)
 
-	args = append(args, filters.limit())
+	args = append(args, filters.limit()+1)
 	args = append(args, filters.offset())
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
 	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, nil, err
@@ -65,22 +123,30 @@ func getAllBooks(m BookModel, title string, filters Filters, userID int64) ([]*B
 
 	defer rows.Close()
 
-	totalRecords := 0
 	books := []*Book{}
+	keys := []string{}
 
 	for rows.Next() {
 		var book Book
+		var sortValue string
 
 		err := rows.Scan(
-			&totalRecords,
 			&book.ID,
 			&book.CreatedAt,
 			&book.Title,
+			&book.Description,
 			&book.CoverPicture,
 			&book.UserID,
 			&book.Version,
 			&book.IsPublished,
 			&book.PublishedAt,
+			&book.Price,
+			&book.Currency,
+			&book.RatingAverage,
+			&book.RatingCount,
+			&book.WordCount,
+			&book.ReadingMinutes,
+			&sortValue,
 		)
 
 		if err != nil {
@@ -88,13 +154,50 @@ func getAllBooks(m BookModel, title string, filters Filters, userID int64) ([]*B
 		}
 
 		books = append(books, &book)
+		keys = append(keys, sortValue)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, nil, err
 	}
 
-	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	hasMore := len(books) > filters.limit()
+	if hasMore {
+		books = books[:filters.limit()]
+		keys = keys[:filters.limit()]
+	}
+
+	backward := cursor != nil && cursor.Backward
+
+	if backward {
+		for i, j := 0, len(books)-1; i < j; i, j = i+1, j-1 {
+			books[i], books[j] = books[j], books[i]
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	var metadata Metadata
+
+	switch {
+	case cursor == nil && totalRecords != nil:
+		metadata = calculateMetadata(*totalRecords, filters.Page, filters.PageSize)
+	case cursor == nil:
+		metadata = Metadata{CurrentPage: filters.Page, PageSize: filters.PageSize, FirstPage: 1}
+	default:
+		metadata = Metadata{PageSize: filters.PageSize, TotalRecords: totalRecords}
+	}
+
+	if len(books) > 0 {
+		first, last := 0, len(books)-1
+
+		if (backward && hasMore) || (!backward && (cursor != nil || filters.Page > 1)) {
+			metadata.PrevCursor = EncodeCursor(Cursor{Sort: filters.Sort, Value: keys[first], ID: books[first].ID, Backward: true})
+		}
+
+		if backward || hasMore {
+			metadata.NextCursor = EncodeCursor(Cursor{Sort: filters.Sort, Value: keys[last], ID: books[last].ID})
+		}
+	}
 
 	return books, &metadata, nil
 }
@@ -113,9 +216,9 @@ func (m BookModel) Get(id int64) (*Book, error) {
 	}
 
 	query := `
-		SELECT id, created_at, title, cover_picture, version, user_id, is_published, published_at
+		SELECT id, created_at, title, coalesce(description, ''), cover_picture, version, user_id, is_published, published_at, price, currency, rating_average, rating_count, word_count, (reading_seconds + 59) / 60
 		FROM books
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var book Book
@@ -124,7 +227,7 @@ func (m BookModel) Get(id int64) (*Book, error) {
 
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, id).Scan(&book.ID, &book.CreatedAt, &book.Title, &book.CoverPicture, &book.Version, &book.UserID, &book.IsPublished, &book.PublishedAt)
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(&book.ID, &book.CreatedAt, &book.Title, &book.Description, &book.CoverPicture, &book.Version, &book.UserID, &book.IsPublished, &book.PublishedAt, &book.Price, &book.Currency, &book.RatingAverage, &book.RatingCount, &book.WordCount, &book.ReadingMinutes)
 
 	if err != nil {
 		switch {
@@ -140,12 +243,12 @@ func (m BookModel) Get(id int64) (*Book, error) {
 
 func (m BookModel) Insert(book *Book) error {
 	query := `
-		INSERT INTO books (title,user_id,cover_picture)
-		VALUES ($1,$2,$3)
+		INSERT INTO books (title,description,user_id,cover_picture,price,currency)
+		VALUES ($1,$2,$3,$4,$5,$6)
 		RETURNING id,created_at, version
 	`
 
-	args := []any{book.Title, book.UserID, book.CoverPicture}
+	args := []any{book.Title, book.Description, book.UserID, book.CoverPicture, book.Price, book.Currency}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 
@@ -157,16 +260,19 @@ func (m BookModel) Insert(book *Book) error {
 func (m BookModel) Update(book *Book) error {
 	query := `
 		UPDATE books 
-		SET title = $1, cover_picture = $2, is_published = $3, published_at = $4, version = version + 1
-		WHERE id = $5 AND version = $6
+		SET title = $1, description = $2, cover_picture = $3, is_published = $4, published_at = $5, price = $6, currency = $7, version = version + 1
+		WHERE id = $8 AND version = $9 AND deleted_at IS NULL
 		RETURNING version
 	`
 
 	args := []interface{}{
 		book.Title,
+		book.Description,
 		book.CoverPicture,
 		book.IsPublished,
 		book.PublishedAt,
+		book.Price,
+		book.Currency,
 		book.ID,
 		book.Version,
 	}
@@ -195,8 +301,9 @@ func (m BookModel) Delete(id int64) error {
 	}
 
 	query := `
-		DELETE FROM books 
-		WHERE id = $1
+		UPDATE books
+		SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -222,3 +329,145 @@ func (m BookModel) Delete(id int64) error {
 	return nil
 
 }
+
+func (m BookModel) Restore(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		UPDATE books
+		SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m BookModel) GetTrashed(id int64) (*Book, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, created_at, title, coalesce(description, ''), cover_picture, version, user_id, is_published, published_at, price, currency, rating_average, rating_count, word_count, (reading_seconds + 59) / 60, deleted_at
+		FROM books
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	var book Book
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(&book.ID, &book.CreatedAt, &book.Title, &book.Description, &book.CoverPicture, &book.Version, &book.UserID, &book.IsPublished, &book.PublishedAt, &book.Price, &book.Currency, &book.RatingAverage, &book.RatingCount, &book.WordCount, &book.ReadingMinutes, &book.DeletedAt)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &book, nil
+}
+
+func (m BookModel) GetTrashedByUser(userID int64) ([]*Book, error) {
+	query := `
+		SELECT id, created_at, title, coalesce(description, ''), cover_picture, version, user_id, is_published, published_at, price, currency, rating_average, rating_count, word_count, (reading_seconds + 59) / 60, deleted_at
+		FROM books
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	books := []*Book{}
+
+	for rows.Next() {
+		var book Book
+
+		err := rows.Scan(&book.ID, &book.CreatedAt, &book.Title, &book.Description, &book.CoverPicture, &book.Version, &book.UserID, &book.IsPublished, &book.PublishedAt, &book.Price, &book.Currency, &book.RatingAverage, &book.RatingCount, &book.WordCount, &book.ReadingMinutes, &book.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		books = append(books, &book)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+// Purge permanently removes books that have been in the trash since before
+// the given time. Their chapters go with them through ON DELETE CASCADE.
+func (m BookModel) Purge(before time.Time) (int64, error) {
+	query := `
+		DELETE FROM books
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// lockBook locks the book row for the rest of the transaction, so changes
+// that keep book-wide aggregates or numbering in step are applied one at a
+// time per book.
+func lockBook(ctx context.Context, tx *sql.Tx, bookID int64) error {
+	var id int64
+
+	err := tx.QueryRowContext(ctx, `SELECT id FROM books WHERE id = $1 FOR UPDATE`, bookID).Scan(&id)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type CartItem struct {
+	BookID   int64     `json:"bookId"`
+	Title    string    `json:"title"`
+	Price    int64     `json:"price"`
+	Currency string    `json:"currency"`
+	AddedAt  time.Time `json:"addedAt"`
+}
+
+type CartModel struct {
+	DB *sql.DB
+}
+
+// Add puts the book in the user's cart. Adding a book twice is a no-op.
+func (m CartModel) Add(userID, bookID int64) error {
+	query := `
+		INSERT INTO cart_items (user_id, book_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, book_id) DO NOTHING
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID, bookID)
+	return err
+}
+
+func (m CartModel) Remove(userID, bookID int64) error {
+	query := `
+		DELETE FROM cart_items
+		WHERE user_id = $1 AND book_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, bookID)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m CartModel) Get(userID int64) ([]*CartItem, error) {
+	query := `
+		SELECT b.id, b.title, b.price, b.currency, ci.added_at
+		FROM cart_items ci
+		INNER JOIN books b ON b.id = ci.book_id
+		WHERE ci.user_id = $1 AND b.deleted_at IS NULL AND b.is_published = true
+		ORDER BY ci.added_at ASC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	items := []*CartItem{}
+
+	for rows.Next() {
+		var item CartItem
+
+		err := rows.Scan(&item.BookID, &item.Title, &item.Price, &item.Currency, &item.AddedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
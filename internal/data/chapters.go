@@ -7,19 +7,49 @@ import (
 	"time"
 
 	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+	"github.com/lib/pq"
 )
 
+const (
+	ChapterAccessFree    = "free"
+	ChapterAccessPremium = "premium"
+)
+
+var ErrInvalidChapterOrder = errors.New("chapter order must list every chapter of the book exactly once")
+
 type Chapter struct {
-	ID          int64     `json:"id"`
-	ChapterNo   int64     `json:"chapterNo"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Content     *string   `json:"content"`
-	BookID      int64     `json:"bookId"`
-	UserID      int64     `json:"userId"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
-	Version     int       `json:"-"`
+	ID             int64      `json:"id"`
+	ChapterNo      int64      `json:"chapterNo"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Content        *string    `json:"content"`
+	ContentFormat  string     `json:"contentFormat"`
+	WordCount      int        `json:"wordCount"`
+	ReadingMinutes int        `json:"readingMinutes"`
+	Access         string     `json:"access"`
+	Locked         bool       `json:"locked,omitempty"`
+	IsPublished    bool       `json:"isPublished"`
+	PublishedAt    *time.Time `json:"publishedAt"`
+	BookID         int64      `json:"bookId"`
+	UserID         int64      `json:"userId"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	DeletedAt      *time.Time `json:"deletedAt,omitempty"`
+	Version        int        `json:"version"`
+}
+
+// ChapterSummary is a table of contents entry: a chapter without its
+// content.
+type ChapterSummary struct {
+	ID             int64      `json:"id"`
+	ChapterNo      int64      `json:"chapterNo"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	WordCount      int        `json:"wordCount"`
+	ReadingMinutes int        `json:"readingMinutes"`
+	Access         string     `json:"access"`
+	IsPublished    bool       `json:"isPublished"`
+	PublishedAt    *time.Time `json:"publishedAt"`
 }
 
 type ChapterModel struct {
@@ -36,48 +66,240 @@ func ValidateChapter(v *validator.Validator, chapter *Chapter) {
 		v.Check(len(chapter.Description) <= 500, "description", "must not be more than 500 bytes long")
 	}
 
+	v.Check(validator.In(chapter.Access, ChapterAccessFree, ChapterAccessPremium), "access", "must be free or premium")
+	v.Check(validator.In(chapter.ContentFormat, ContentFormatMarkdown, ContentFormatText), "contentFormat", "must be markdown or text")
+
 }
 
+func ValidateChapterOrder(v *validator.Validator, chapterIDs []int64) {
+	v.Check(len(chapterIDs) > 0, "chapterIds", "must be provided")
+	v.Check(len(chapterIDs) <= 1000, "chapterIds", "must not contain more than 1000 chapters")
+
+	seen := make(map[int64]bool, len(chapterIDs))
+
+	for _, id := range chapterIDs {
+		v.Check(id > 0, "chapterIds", "must only contain valid chapter ids")
+		v.Check(!seen[id], "chapterIds", "must not contain duplicate values")
+		seen[id] = true
+	}
+}
+
+// Insert adds the chapter after the last one of its book. Trashed chapters
+// keep their number, so a restored chapter never collides with a new one.
 func (m ChapterModel) Insert(chapter *Chapter) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	// Concurrent inserts into the same book would otherwise pick the same number.
+	err = lockBook(ctx, tx, chapter.BookID)
+	if err != nil {
+		return err
+	}
+
+	err = insertChapter(ctx, tx, chapter)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertChapter numbers and inserts the chapter and saves its first
+// revision. Callers must hold the book lock.
+func insertChapter(ctx context.Context, tx *sql.Tx, chapter *Chapter) error {
 	query := `
-		INSERT INTO chapters (title,description,book_id,user_id)
-		VALUES ($1,$2,$3,$4)
-		RETURNING id,created_at, version
+		INSERT INTO chapters (title,description,content,book_id,user_id,access,content_format,word_count,reading_seconds,chapter_no)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,(SELECT coalesce(max(chapter_no), 0) + 1 FROM chapters WHERE book_id = $4))
+		RETURNING id,created_at, version, chapter_no
 	`
 
-	args := []any{chapter.Title, chapter.Description, chapter.BookID, chapter.UserID}
+	stats, err := ContentStats(chapter.ContentFormat, chapter.Content)
+	if err != nil {
+		return err
+	}
+
+	chapter.WordCount, chapter.ReadingMinutes = stats.Words, stats.ReadingMinutes()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	args := []any{chapter.Title, chapter.Description, chapter.Content, chapter.BookID, chapter.UserID, chapter.Access, chapter.ContentFormat, stats.Words, stats.ReadingSeconds}
 
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&chapter.ID, &chapter.CreatedAt, &chapter.Version, &chapter.ChapterNo)
+	if err != nil {
+		return err
+	}
+
+	return insertRevision(ctx, tx, chapter)
+}
+
+// Reorder renumbers the chapters of a book in the order of chapterIDs, which
+// must list every chapter of the book that is not in the trash. Trashed
+// chapters are numbered after them. The unique constraint on the number is
+// deferred to commit so chapters can swap places.
+func (m ChapterModel) Reorder(bookID int64, chapterIDs []int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	return m.DB.QueryRowContext(ctx, query, args...).Scan(&chapter.ID, &chapter.CreatedAt, &chapter.Version)
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = lockBook(ctx, tx, bookID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `SET CONSTRAINTS chapters_book_id_chapter_no_key DEFERRED`)
+	if err != nil {
+		return err
+	}
+
+	var total, listed int
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT count(*), count(*) FILTER (WHERE id = ANY($2))
+		FROM chapters
+		WHERE book_id = $1 AND deleted_at IS NULL
+	`, bookID, pq.Array(chapterIDs)).Scan(&total, &listed)
+
+	if err != nil {
+		return err
+	}
+
+	if total != len(chapterIDs) || listed != len(chapterIDs) {
+		return ErrInvalidChapterOrder
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE chapters c
+		SET chapter_no = o.position
+		FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, position)
+		WHERE c.id = o.id AND c.book_id = $1
+	`, bookID, pq.Array(chapterIDs))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE chapters c
+		SET chapter_no = $2 + t.position
+		FROM (
+			SELECT id, row_number() OVER (ORDER BY chapter_no, id) AS position
+			FROM chapters
+			WHERE book_id = $1 AND deleted_at IS NOT NULL
+		) t
+		WHERE c.id = t.id
+	`, bookID, len(chapterIDs))
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (m ChapterModel) GetByBookId(bookId int64) ([]*Chapter, error) {
+// GetTOC lists a page of a book's table of contents in reading order.
+// Drafts are left out unless includeDrafts is set.
+func (m ChapterModel) GetTOC(bookID int64, includeDrafts bool, filters Filters) ([]*ChapterSummary, *Metadata, error) {
 	query := `
-		SELECT id, created_at, updated_at, title, description, chapter_no, content, book_id,user_id, version
-		FROM chapters 
-		WHERE book_id = $1
+		SELECT count(*) OVER(), c.id, c.chapter_no, c.title, coalesce(c.description, ''), c.word_count, (c.reading_seconds + 59) / 60, c.access, c.is_published, c.published_at
+		FROM chapters c
+		INNER JOIN books b ON b.id = c.book_id
+		WHERE c.book_id = $1 AND c.deleted_at IS NULL AND b.deleted_at IS NULL
+		AND (c.is_published = true OR $2)
+		ORDER BY c.chapter_no, c.id
+		LIMIT $3 OFFSET $4
 	`
 
-	args := []any{bookId}
+	args := []any{bookID, includeDrafts, filters.limit(), filters.offset()}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer rows.Close()
 
+	totalRecords := 0
+	entries := []*ChapterSummary{}
+
+	for rows.Next() {
+		var entry ChapterSummary
+
+		err := rows.Scan(
+			&totalRecords,
+			&entry.ID,
+			&entry.ChapterNo,
+			&entry.Title,
+			&entry.Description,
+			&entry.WordCount,
+			&entry.ReadingMinutes,
+			&entry.Access,
+			&entry.IsPublished,
+			&entry.PublishedAt,
+		)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return entries, &metadata, nil
+}
+
+// GetByBookId lists a page of a book's chapters with their content, in
+// reading order. Drafts are left out unless includeDrafts is set.
+func (m ChapterModel) GetByBookId(bookId int64, includeDrafts bool, filters Filters) ([]*Chapter, *Metadata, error) {
+	query := `
+		SELECT count(*) OVER(), c.id, c.created_at, c.updated_at, c.title, c.description, c.chapter_no, c.content, c.content_format, c.word_count, (c.reading_seconds + 59) / 60, c.access, c.is_published, c.published_at, c.book_id, c.user_id, c.version
+		FROM chapters c
+		INNER JOIN books b ON b.id = c.book_id
+		WHERE c.book_id = $1 AND c.deleted_at IS NULL AND b.deleted_at IS NULL
+		AND (c.is_published = true OR $2)
+		ORDER BY c.chapter_no, c.id
+		LIMIT $3 OFFSET $4
+	`
+
+	args := []any{bookId, includeDrafts, filters.limit(), filters.offset()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
 	chapters := []*Chapter{}
 
 	for rows.Next() {
 		var chapter Chapter
 		err := rows.Scan(
+			&totalRecords,
 			&chapter.ID,
 			&chapter.CreatedAt,
 			&chapter.UpdatedAt,
@@ -85,24 +307,31 @@ func (m ChapterModel) GetByBookId(bookId int64) ([]*Chapter, error) {
 			&chapter.Description,
 			&chapter.ChapterNo,
 			&chapter.Content,
+			&chapter.ContentFormat,
+			&chapter.WordCount,
+			&chapter.ReadingMinutes,
+			&chapter.Access,
+			&chapter.IsPublished,
+			&chapter.PublishedAt,
 			&chapter.BookID,
 			&chapter.UserID,
 			&chapter.Version,
 		)
 
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		chapters = append(chapters, &chapter)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return chapters, nil
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
 
+	return chapters, &metadata, nil
 }
 
 func (m ChapterModel) Get(id int64) (*Chapter, error) {
@@ -111,9 +340,10 @@ func (m ChapterModel) Get(id int64) (*Chapter, error) {
 	}
 
 	query := `
-		SELECT id, created_at, updated_at, title, description, chapter_no, content, book_id,user_id, version
-		FROM chapters 
-		WHERE id = $1
+		SELECT c.id, c.created_at, c.updated_at, c.title, c.description, c.chapter_no, c.content, c.content_format, c.word_count, (c.reading_seconds + 59) / 60, c.access, c.is_published, c.published_at, c.book_id, c.user_id, c.version
+		FROM chapters c
+		INNER JOIN books b ON b.id = c.book_id
+		WHERE c.id = $1 AND c.deleted_at IS NULL AND b.deleted_at IS NULL
 	`
 
 	var chapter Chapter
@@ -129,6 +359,12 @@ func (m ChapterModel) Get(id int64) (*Chapter, error) {
 		&chapter.Description,
 		&chapter.ChapterNo,
 		&chapter.Content,
+		&chapter.ContentFormat,
+		&chapter.WordCount,
+		&chapter.ReadingMinutes,
+		&chapter.Access,
+		&chapter.IsPublished,
+		&chapter.PublishedAt,
 		&chapter.BookID,
 		&chapter.UserID,
 		&chapter.Version,
@@ -148,26 +384,118 @@ func (m ChapterModel) Get(id int64) (*Chapter, error) {
 }
 
 func (m ChapterModel) Update(chapter *Chapter) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	// The book is locked before the chapter, in the same order as Insert and
+	// Reorder, so its length totals can be refreshed without deadlocks.
+	err = lockBook(ctx, tx, chapter.BookID)
+	if err != nil {
+		return err
+	}
+
+	err = updateChapter(ctx, tx, chapter)
+	if err != nil {
+		return err
+	}
+
+	err = refreshBookLength(ctx, tx, chapter.BookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// updateChapter saves the chapter if it is still at the version that was
+// read, records the new revision and moves readers' annotations along with
+// the text. Callers must hold the book lock.
+func updateChapter(ctx context.Context, tx *sql.Tx, chapter *Chapter) error {
 	query := `
 		UPDATE chapters 
-		SET title = $1, description = $2, content = $3, updated_at = NOW(), version = version + 1
-		WHERE id = $4 AND version = $5
+		SET title = $1, description = $2, content = $3, content_format = $4, access = $5, word_count = $6, reading_seconds = $7, updated_at = NOW(), version = version + 1
+		WHERE id = $8 AND version = $9 AND deleted_at IS NULL
 		RETURNING version
 	`
 
+	stats, err := ContentStats(chapter.ContentFormat, chapter.Content)
+	if err != nil {
+		return err
+	}
+
+	chapter.WordCount, chapter.ReadingMinutes = stats.Words, stats.ReadingMinutes()
+
 	args := []interface{}{
 		chapter.Title,
 		chapter.Description,
 		chapter.Content,
+		chapter.ContentFormat,
+		chapter.Access,
+		stats.Words,
+		stats.ReadingSeconds,
 		chapter.ID,
 		chapter.Version,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&chapter.Version)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	err = insertRevision(ctx, tx, chapter)
+	if err != nil {
+		return err
+	}
 
+	content := ""
+	if chapter.Content != nil {
+		content = *chapter.Content
+	}
+
+	// Readers' annotations follow the text they were made on.
+	return relocateAnnotations(ctx, tx, chapter.ID, content)
+}
+
+// SetPublished publishes or unpublishes the chapter. Like Update it only
+// applies to the version that was read and bumps it.
+func (m ChapterModel) SetPublished(chapter *Chapter, published bool) error {
+	query := `
+		UPDATE chapters
+		SET is_published = $1, published_at = CASE WHEN $1 THEN NOW() END, version = version + 1
+		WHERE id = $2 AND version = $3 AND deleted_at IS NULL
+		RETURNING is_published, published_at, version
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&chapter.Version)
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = lockBook(ctx, tx, chapter.BookID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, query, published, chapter.ID, chapter.Version).Scan(&chapter.IsPublished, &chapter.PublishedAt, &chapter.Version)
 
 	if err != nil {
 		switch {
@@ -178,7 +506,57 @@ func (m ChapterModel) Update(chapter *Chapter) error {
 		}
 	}
 
-	return nil
+	err = refreshBookLength(ctx, tx, chapter.BookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetRendered returns the HTML cached for the given version of the chapter,
+// or ErrRecordNotFound when it has not been rendered since it last changed.
+func (m ChapterModel) GetRendered(id int64, version int) (string, error) {
+	query := `
+		SELECT rendered_html
+		FROM chapters
+		WHERE id = $1 AND rendered_version = $2 AND rendered_html IS NOT NULL
+	`
+
+	var rendered string
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id, version).Scan(&rendered)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", ErrRecordNotFound
+		default:
+			return "", err
+		}
+	}
+
+	return rendered, nil
+}
+
+// SaveRendered caches the HTML rendered from the given version of the
+// chapter. It is a no-op if the chapter has changed in the meantime.
+func (m ChapterModel) SaveRendered(id int64, version int, rendered string) error {
+	query := `
+		UPDATE chapters
+		SET rendered_html = $1, rendered_version = $2
+		WHERE id = $3 AND version = $2
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, rendered, version, id)
+
+	return err
 }
 
 func (m ChapterModel) Delete(id int64) error {
@@ -186,16 +564,63 @@ func (m ChapterModel) Delete(id int64) error {
 		return ErrRecordNotFound
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	bookID, err := lockChapterBook(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	err = trashChapter(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	err = refreshBookLength(ctx, tx, bookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m ChapterModel) Restore(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
 	query := `
-		DELETE FROM chapters 
-		WHERE id = $1
+		UPDATE chapters
+		SET deleted_at = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
 	`
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 
 	defer cancel()
 
-	result, err := m.DB.ExecContext(ctx, query, id)
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	bookID, err := lockChapterBook(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, query, id)
 
 	if err != nil {
 		return err
@@ -204,7 +629,63 @@ func (m ChapterModel) Delete(id int64) error {
 	rowsAffected, err := result.RowsAffected()
 
 	if err != nil {
-		return nil
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	err = refreshBookLength(ctx, tx, bookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockChapterBook locks the book the chapter belongs to, trashed or not,
+// and returns its id.
+func lockChapterBook(ctx context.Context, tx *sql.Tx, chapterID int64) (int64, error) {
+	var bookID int64
+
+	err := tx.QueryRowContext(ctx, `
+		SELECT id FROM books
+		WHERE id = (SELECT book_id FROM chapters WHERE id = $1)
+		FOR UPDATE
+	`, chapterID).Scan(&bookID)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return bookID, nil
+}
+
+// trashChapter moves the chapter to the trash. Callers must hold the book
+// lock.
+func trashChapter(ctx context.Context, tx *sql.Tx, id int64) error {
+	query := `
+		UPDATE chapters
+		SET deleted_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, id)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
 	}
 
 	if rowsAffected == 0 {
@@ -213,3 +694,145 @@ func (m ChapterModel) Delete(id int64) error {
 
 	return nil
 }
+
+// refreshBookLength recomputes the book's word count and reading time from
+// its published chapters. Callers must hold the book lock.
+func refreshBookLength(ctx context.Context, tx *sql.Tx, bookID int64) error {
+	query := `
+		UPDATE books
+		SET word_count = s.words, reading_seconds = s.seconds
+		FROM (
+			SELECT coalesce(sum(word_count), 0) AS words, coalesce(sum(reading_seconds), 0) AS seconds
+			FROM chapters
+			WHERE book_id = $1 AND deleted_at IS NULL AND is_published = true
+		) s
+		WHERE books.id = $1
+	`
+
+	_, err := tx.ExecContext(ctx, query, bookID)
+
+	return err
+}
+
+func (m ChapterModel) GetTrashed(id int64) (*Chapter, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, created_at, updated_at, title, description, chapter_no, content_format, word_count, (reading_seconds + 59) / 60, access, is_published, published_at, book_id, user_id, version, deleted_at
+		FROM chapters
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	var chapter Chapter
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&chapter.ID,
+		&chapter.CreatedAt,
+		&chapter.UpdatedAt,
+		&chapter.Title,
+		&chapter.Description,
+		&chapter.ChapterNo,
+		&chapter.ContentFormat,
+		&chapter.WordCount,
+		&chapter.ReadingMinutes,
+		&chapter.Access,
+		&chapter.IsPublished,
+		&chapter.PublishedAt,
+		&chapter.BookID,
+		&chapter.UserID,
+		&chapter.Version,
+		&chapter.DeletedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &chapter, nil
+}
+
+// GetTrashedByUser lists trashed chapters the user wrote or whose book the
+// user owns. Chapters of a trashed book are not listed; they come back when
+// the book is restored.
+func (m ChapterModel) GetTrashedByUser(userID int64) ([]*Chapter, error) {
+	query := `
+		SELECT c.id, c.created_at, c.updated_at, c.title, c.description, c.chapter_no, c.content_format, c.word_count, (c.reading_seconds + 59) / 60, c.access, c.is_published, c.published_at, c.book_id, c.user_id, c.version, c.deleted_at
+		FROM chapters c
+		INNER JOIN books b ON b.id = c.book_id
+		WHERE (c.user_id = $1 OR b.user_id = $1) AND c.deleted_at IS NOT NULL AND b.deleted_at IS NULL
+		ORDER BY c.deleted_at DESC, c.id DESC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	chapters := []*Chapter{}
+
+	for rows.Next() {
+		var chapter Chapter
+		err := rows.Scan(
+			&chapter.ID,
+			&chapter.CreatedAt,
+			&chapter.UpdatedAt,
+			&chapter.Title,
+			&chapter.Description,
+			&chapter.ChapterNo,
+			&chapter.ContentFormat,
+			&chapter.WordCount,
+			&chapter.ReadingMinutes,
+			&chapter.Access,
+			&chapter.IsPublished,
+			&chapter.PublishedAt,
+			&chapter.BookID,
+			&chapter.UserID,
+			&chapter.Version,
+			&chapter.DeletedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		chapters = append(chapters, &chapter)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return chapters, nil
+}
+
+func (m ChapterModel) Purge(before time.Time) (int64, error) {
+	query := `
+		DELETE FROM chapters
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,248 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+var (
+	ErrDuplicateCollaborator = errors.New("duplicate collaborator")
+)
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// RoleAllows reports whether a collaborator holding role have may perform
+// actions that require role want. Owners can do everything editors can, and
+// editors everything viewers can.
+func RoleAllows(have, want string) bool {
+	return roleRanks[have] > 0 && roleRanks[have] >= roleRanks[want]
+}
+
+type Collaborator struct {
+	BookID      int64      `json:"bookId"`
+	UserID      int64      `json:"userId"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	Status      string     `json:"status"`
+	InvitedBy   int64      `json:"invitedBy"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	RespondedAt *time.Time `json:"respondedAt"`
+}
+
+func ValidateCollaborator(v *validator.Validator, collaborator *Collaborator) {
+	v.Check(validator.In(collaborator.Role, RoleOwner, RoleEditor, RoleViewer), "role", "must be one of owner, editor or viewer")
+}
+
+type CollaboratorModel struct {
+	DB *sql.DB
+}
+
+// Invite records a pending invitation. A previously declined invitation is
+// re-opened with the new role; any other existing row is a duplicate.
+func (m CollaboratorModel) Invite(collaborator *Collaborator) error {
+	query := `
+		INSERT INTO book_collaborators (book_id, user_id, role, invited_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (book_id, user_id) DO UPDATE
+		SET role = EXCLUDED.role, invited_by = EXCLUDED.invited_by, status = 'pending', created_at = NOW(), responded_at = NULL
+		WHERE book_collaborators.status = 'declined'
+		RETURNING status, created_at
+	`
+
+	args := []any{collaborator.BookID, collaborator.UserID, collaborator.Role, collaborator.InvitedBy}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&collaborator.Status, &collaborator.CreatedAt)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrDuplicateCollaborator
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetRole returns the role of an accepted collaborator on the book.
+func (m CollaboratorModel) GetRole(bookID, userID int64) (string, error) {
+	query := `
+		SELECT role
+		FROM book_collaborators
+		WHERE book_id = $1 AND user_id = $2 AND status = 'accepted'
+	`
+
+	var role string
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, bookID, userID).Scan(&role)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", ErrRecordNotFound
+		default:
+			return "", err
+		}
+	}
+
+	return role, nil
+}
+
+func (m CollaboratorModel) GetByBook(bookID int64) ([]*Collaborator, error) {
+	query := `
+		SELECT bc.book_id, bc.user_id, u.name, u.email, bc.role, bc.status, bc.invited_by, bc.created_at, bc.responded_at
+		FROM book_collaborators bc
+		INNER JOIN users u ON u.id = bc.user_id
+		WHERE bc.book_id = $1
+		ORDER BY bc.created_at ASC
+	`
+
+	return m.query(query, bookID)
+}
+
+// GetInvitationsByUser lists the pending invitations addressed to the user.
+func (m CollaboratorModel) GetInvitationsByUser(userID int64) ([]*Collaborator, error) {
+	query := `
+		SELECT bc.book_id, bc.user_id, u.name, u.email, bc.role, bc.status, bc.invited_by, bc.created_at, bc.responded_at
+		FROM book_collaborators bc
+		INNER JOIN users u ON u.id = bc.user_id
+		INNER JOIN books b ON b.id = bc.book_id
+		WHERE bc.user_id = $1 AND bc.status = 'pending' AND b.deleted_at IS NULL
+		ORDER BY bc.created_at DESC
+	`
+
+	return m.query(query, userID)
+}
+
+func (m CollaboratorModel) query(query string, args ...any) ([]*Collaborator, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	collaborators := []*Collaborator{}
+
+	for rows.Next() {
+		var collaborator Collaborator
+
+		err := rows.Scan(
+			&collaborator.BookID,
+			&collaborator.UserID,
+			&collaborator.Name,
+			&collaborator.Email,
+			&collaborator.Role,
+			&collaborator.Status,
+			&collaborator.InvitedBy,
+			&collaborator.CreatedAt,
+			&collaborator.RespondedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		collaborators = append(collaborators, &collaborator)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return collaborators, nil
+}
+
+// Respond accepts or declines a pending invitation.
+func (m CollaboratorModel) Respond(bookID, userID int64, accept bool) error {
+	status := InvitationDeclined
+	if accept {
+		status = InvitationAccepted
+	}
+
+	query := `
+		UPDATE book_collaborators
+		SET status = $1, responded_at = NOW()
+		WHERE book_id = $2 AND user_id = $3 AND status = 'pending'
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, status, bookID, userID)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m CollaboratorModel) Delete(bookID, userID int64) error {
+	query := `
+		DELETE FROM book_collaborators
+		WHERE book_id = $1 AND user_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, bookID, userID)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
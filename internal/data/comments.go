@@ -0,0 +1,389 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+var ErrCommentRateLimited = errors.New("too many comments posted recently")
+
+// commentLockClass namespaces the advisory locks taken while posting so they
+// cannot collide with locks taken for other purposes.
+const commentLockClass = 38
+
+type Comment struct {
+	ID         int64      `json:"id"`
+	ChapterID  int64      `json:"chapterId"`
+	ParentID   *int64     `json:"parentId"`
+	UserID     int64      `json:"userId"`
+	UserName   string     `json:"userName"`
+	Body       string     `json:"body"`
+	ReplyCount int        `json:"replyCount"`
+	Pinned     bool       `json:"pinned"`
+	Deleted    bool       `json:"deleted"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	EditedAt   *time.Time `json:"editedAt"`
+	Version    int        `json:"-"`
+}
+
+func ValidateComment(v *validator.Validator, comment *Comment) {
+	v.Check(comment.Body != "", "body", "must be provided")
+	v.Check(len(comment.Body) <= 5000, "body", "must not be more than 5000 bytes long")
+}
+
+// CanEdit reports whether the comment may still be edited by its author at
+// now. Deleted comments can never be edited.
+func (c *Comment) CanEdit(now time.Time, window time.Duration) bool {
+	return !c.Deleted && now.Before(c.CreatedAt.Add(window))
+}
+
+type CommentModel struct {
+	DB *sql.DB
+}
+
+var commentSortKeys = map[string]sortKey{
+	"created_at": {expr: "created_at", cast: "timestamptz"},
+}
+
+// commentColumns selects a comment from the table aliased as c. The body of a
+// deleted comment is blanked so the thread keeps its shape without the text.
+const commentColumns = `
+	c.id, c.chapter_id, c.parent_id, c.user_id, coalesce((SELECT u.name FROM users u WHERE u.id = c.user_id), ''),
+	CASE WHEN c.deleted_at IS NULL THEN c.body ELSE '' END,
+	(SELECT count(*) FROM comments r WHERE r.parent_id = c.id),
+	c.pinned_at IS NOT NULL, c.deleted_at IS NOT NULL, c.created_at, c.edited_at, c.version`
+
+func scanComment(row interface{ Scan(...any) error }, comment *Comment, extra ...any) error {
+	dest := []any{
+		&comment.ID,
+		&comment.ChapterID,
+		&comment.ParentID,
+		&comment.UserID,
+		&comment.UserName,
+		&comment.Body,
+		&comment.ReplyCount,
+		&comment.Pinned,
+		&comment.Deleted,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.Version,
+	}
+
+	return row.Scan(append(dest, extra...)...)
+}
+
+// Insert posts the comment unless the user already posted limit comments
+// within the window. Posts by the same user are serialised with an advisory
+// lock so concurrent requests cannot slip past the limit together.
+func (m CommentModel) Insert(comment *Comment, limit int, window time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1::int, $2::int)`, commentLockClass, int32(comment.UserID))
+	if err != nil {
+		return err
+	}
+
+	var recent int
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT count(*)
+		FROM comments
+		WHERE user_id = $1 AND created_at > $2
+	`, comment.UserID, time.Now().Add(-window)).Scan(&recent)
+
+	if err != nil {
+		return err
+	}
+
+	if recent >= limit {
+		return ErrCommentRateLimited
+	}
+
+	query := `
+		INSERT INTO comments (chapter_id, parent_id, user_id, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, version
+	`
+
+	args := []any{comment.ChapterID, comment.ParentID, comment.UserID, comment.Body}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&comment.ID, &comment.CreatedAt, &comment.Version)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m CommentModel) Get(id int64) (*Comment, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT ` + commentColumns + `
+		FROM comments c
+		WHERE c.id = $1
+	`
+
+	var comment Comment
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := scanComment(m.DB.QueryRowContext(ctx, query, id), &comment)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &comment, nil
+}
+
+// GetByChapter returns a page of the chapter's top-level comments.
+func (m CommentModel) GetByChapter(chapterID int64, filters Filters) ([]*Comment, *Metadata, error) {
+	return m.page(`c.chapter_id = $1 AND c.parent_id IS NULL`, chapterID, filters)
+}
+
+// GetReplies returns a page of the direct replies to a comment.
+func (m CommentModel) GetReplies(parentID int64, filters Filters) ([]*Comment, *Metadata, error) {
+	return m.page(`c.parent_id = $1`, parentID, filters)
+}
+
+// GetPinned returns the chapter's pinned comments, most recently pinned
+// first.
+func (m CommentModel) GetPinned(chapterID int64) ([]*Comment, error) {
+	query := `SELECT ` + commentColumns + `
+		FROM comments c
+		WHERE c.chapter_id = $1 AND c.pinned_at IS NOT NULL AND c.deleted_at IS NULL
+		ORDER BY c.pinned_at DESC, c.id DESC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, chapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	comments := []*Comment{}
+
+	for rows.Next() {
+		var comment Comment
+
+		if err := scanComment(rows, &comment); err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, &comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// page runs a cursor paginated listing of the comments matching where, which
+// refers to its single argument as $1.
+func (m CommentModel) page(where string, arg int64, filters Filters) ([]*Comment, *Metadata, error) {
+	key := commentSortKeys[filters.sortColumn()]
+
+	var cursor *Cursor
+
+	if filters.Cursor != "" {
+		c, err := DecodeCursor(filters.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		cursor = &c
+	}
+
+	args := []any{arg}
+
+	cond, orderBy := filters.keyset(key, cursor, len(args)+1)
+
+	query := fmt.Sprintf(`SELECT %s, (%s)::text
+		FROM comments c
+		WHERE %s`, commentColumns, key.expr, where)
+
+	if cursor != nil {
+		query += ` AND ` + cond
+		args = append(args, cursor.Value, cursor.ID)
+	}
+
+	// One extra row is fetched to tell whether another page follows.
+	query += fmt.Sprintf(`
+		%s
+		LIMIT $%d
+	`, orderBy, len(args)+1)
+
+	args = append(args, filters.limit()+1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer rows.Close()
+
+	comments := []*Comment{}
+	keys := []string{}
+
+	for rows.Next() {
+		var comment Comment
+		var sortValue string
+
+		if err := scanComment(rows, &comment, &sortValue); err != nil {
+			return nil, nil, err
+		}
+
+		comments = append(comments, &comment)
+		keys = append(keys, sortValue)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	hasMore := len(comments) > filters.limit()
+	if hasMore {
+		comments = comments[:filters.limit()]
+		keys = keys[:filters.limit()]
+	}
+
+	backward := cursor != nil && cursor.Backward
+
+	if backward {
+		for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+			comments[i], comments[j] = comments[j], comments[i]
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	metadata := Metadata{PageSize: filters.PageSize}
+
+	if len(comments) > 0 {
+		first, last := 0, len(comments)-1
+
+		if (backward && hasMore) || (!backward && cursor != nil) {
+			metadata.PrevCursor = EncodeCursor(Cursor{Sort: filters.Sort, Value: keys[first], ID: comments[first].ID, Backward: true})
+		}
+
+		if backward || hasMore {
+			metadata.NextCursor = EncodeCursor(Cursor{Sort: filters.Sort, Value: keys[last], ID: comments[last].ID})
+		}
+	}
+
+	return comments, &metadata, nil
+}
+
+// Update saves an edited body under optimistic locking and stamps the edit
+// time.
+func (m CommentModel) Update(comment *Comment) error {
+	query := `
+		UPDATE comments
+		SET body = $1, edited_at = NOW(), version = version + 1
+		WHERE id = $2 AND version = $3 AND deleted_at IS NULL
+		RETURNING edited_at, version
+	`
+
+	args := []any{comment.Body, comment.ID, comment.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&comment.EditedAt, &comment.Version)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetPinned pins or unpins a comment that has not been deleted.
+func (m CommentModel) SetPinned(id int64, pinned bool) error {
+	query := `
+		UPDATE comments
+		SET pinned_at = CASE WHEN $2 THEN coalesce(pinned_at, NOW()) END, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, pinned)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Delete soft deletes a comment. The row stays so its replies keep their
+// place in the thread; it is also unpinned.
+func (m CommentModel) Delete(id, deletedBy int64) error {
+	query := `
+		UPDATE comments
+		SET deleted_at = NOW(), deleted_by = $2, pinned_at = NULL, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, deletedBy)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
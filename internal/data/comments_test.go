@@ -0,0 +1,19 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentCanEdit(t *testing.T) {
+	posted := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	comment := &Comment{CreatedAt: posted}
+
+	assert.True(t, comment.CanEdit(posted.Add(14*time.Minute), 15*time.Minute))
+	assert.False(t, comment.CanEdit(posted.Add(15*time.Minute), 15*time.Minute))
+
+	comment.Deleted = true
+	assert.False(t, comment.CanEdit(posted, 15*time.Minute))
+}
@@ -0,0 +1,360 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+	"github.com/lib/pq"
+)
+
+const (
+	CouponPercent = "percent"
+	CouponFixed   = "fixed"
+)
+
+var (
+	ErrDuplicateCouponCode = errors.New("duplicate coupon code")
+	ErrInvalidCoupon       = errors.New("coupon does not exist or is not valid right now")
+	ErrCouponExhausted     = errors.New("coupon has reached its usage limit")
+	ErrCouponNotApplicable = errors.New("coupon does not apply to this order")
+)
+
+var couponCodeRX = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type Coupon struct {
+	ID             int64      `json:"id"`
+	Code           string     `json:"code"`
+	Kind           string     `json:"kind"`
+	Amount         int64      `json:"amount"`
+	Currency       string     `json:"currency,omitempty"`
+	BookID         *int64     `json:"bookId"`
+	CreatedBy      int64      `json:"createdBy"`
+	MaxUses        *int       `json:"maxUses"`
+	MaxUsesPerUser *int       `json:"maxUsesPerUser"`
+	StartsAt       time.Time  `json:"startsAt"`
+	EndsAt         *time.Time `json:"endsAt"`
+	Redemptions    int        `json:"redemptions"`
+	TotalDiscount  int64      `json:"totalDiscount"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	Version        int        `json:"-"`
+}
+
+func ValidateCoupon(v *validator.Validator, coupon *Coupon) {
+	v.Check(coupon.Code != "", "code", "must be provided")
+	v.Check(len(coupon.Code) >= 3, "code", "must be at least 3 bytes long")
+	v.Check(len(coupon.Code) <= 40, "code", "must not be more than 40 bytes long")
+	v.Check(validator.Matches(coupon.Code, *couponCodeRX), "code", "must only contain letters, digits, dashes and underscores")
+
+	v.Check(validator.In(coupon.Kind, CouponPercent, CouponFixed), "kind", "must be percent or fixed")
+
+	switch coupon.Kind {
+	case CouponPercent:
+		v.Check(coupon.Amount >= 1 && coupon.Amount <= 100, "amount", "must be between 1 and 100")
+	case CouponFixed:
+		v.Check(coupon.Amount > 0, "amount", "must be greater than zero")
+		v.Check(coupon.Amount <= 10_000_000, "amount", "must not be more than 10000000")
+		v.Check(validator.In(coupon.Currency, SupportedCurrencies...), "currency", "must be a supported currency")
+	}
+
+	if coupon.MaxUses != nil {
+		v.Check(*coupon.MaxUses > 0, "maxUses", "must be greater than zero")
+	}
+
+	if coupon.MaxUsesPerUser != nil {
+		v.Check(*coupon.MaxUsesPerUser > 0, "maxUsesPerUser", "must be greater than zero")
+	}
+
+	if coupon.EndsAt != nil {
+		v.Check(coupon.EndsAt.After(coupon.StartsAt), "endsAt", "must be after startsAt")
+	}
+}
+
+// ApplyCoupon discounts the order's eligible items: every item for a
+// store-wide coupon, or only the coupon's book. A fixed discount is capped at
+// the eligible amount and split across eligible items in proportion to their
+// price so each item records the share it gave away.
+func ApplyCoupon(order *Order, coupon *Coupon) error {
+	var eligible []*OrderItem
+	var eligibleTotal int64
+
+	for _, item := range order.Items {
+		if coupon.BookID != nil && (item.BookID == nil || *item.BookID != *coupon.BookID) {
+			continue
+		}
+
+		if item.Price == 0 {
+			continue
+		}
+
+		eligible = append(eligible, item)
+		eligibleTotal += item.Price
+	}
+
+	if len(eligible) == 0 {
+		return ErrCouponNotApplicable
+	}
+
+	switch coupon.Kind {
+	case CouponPercent:
+		for _, item := range eligible {
+			item.Discount = item.Price * coupon.Amount / 100
+		}
+
+	case CouponFixed:
+		if coupon.Currency != order.Currency {
+			return ErrCouponNotApplicable
+		}
+
+		discount := min(coupon.Amount, eligibleTotal)
+		remainder := discount
+
+		for _, item := range eligible {
+			item.Discount = discount * item.Price / eligibleTotal
+			remainder -= item.Discount
+		}
+
+		for _, item := range eligible {
+			if remainder == 0 {
+				break
+			}
+
+			if item.Discount < item.Price {
+				item.Discount++
+				remainder--
+			}
+		}
+	}
+
+	order.Discount = 0
+
+	for _, item := range eligible {
+		order.Discount += item.Discount
+	}
+
+	order.Total -= order.Discount
+	order.CouponID = &coupon.ID
+
+	return nil
+}
+
+type CouponModel struct {
+	DB *sql.DB
+}
+
+func (m CouponModel) Insert(coupon *Coupon) error {
+	query := `
+		INSERT INTO coupons (code, kind, amount, currency, book_id, created_by, max_uses, max_uses_per_user, starts_at, ends_at)
+		VALUES ($1, $2, $3, nullif($4, ''), $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, version
+	`
+
+	args := []any{
+		coupon.Code,
+		coupon.Kind,
+		coupon.Amount,
+		coupon.Currency,
+		coupon.BookID,
+		coupon.CreatedBy,
+		coupon.MaxUses,
+		coupon.MaxUsesPerUser,
+		coupon.StartsAt,
+		coupon.EndsAt,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&coupon.ID, &coupon.CreatedAt, &coupon.Version)
+
+	if err != nil {
+		var pqErr *pq.Error
+
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Constraint == "coupons_code_idx":
+			return ErrDuplicateCouponCode
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// couponColumns selects a coupon with its redemptions and the discount it
+// gave on paid orders, for reporting.
+const couponColumns = `
+	c.id, c.code, c.kind, c.amount, coalesce(c.currency, ''), c.book_id, c.created_by, c.max_uses,
+	c.max_uses_per_user, c.starts_at, c.ends_at, c.created_at, c.version,
+	(SELECT count(*) FROM orders o WHERE o.coupon_id = c.id AND o.status = 'paid'),
+	(SELECT coalesce(sum(o.discount), 0) FROM orders o WHERE o.coupon_id = c.id AND o.status = 'paid')
+`
+
+func scanCoupon(row interface{ Scan(...any) error }, coupon *Coupon) error {
+	return row.Scan(
+		&coupon.ID,
+		&coupon.Code,
+		&coupon.Kind,
+		&coupon.Amount,
+		&coupon.Currency,
+		&coupon.BookID,
+		&coupon.CreatedBy,
+		&coupon.MaxUses,
+		&coupon.MaxUsesPerUser,
+		&coupon.StartsAt,
+		&coupon.EndsAt,
+		&coupon.CreatedAt,
+		&coupon.Version,
+		&coupon.Redemptions,
+		&coupon.TotalDiscount,
+	)
+}
+
+func (m CouponModel) Get(id int64) (*Coupon, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `SELECT ` + couponColumns + ` FROM coupons c WHERE c.id = $1`
+
+	var coupon Coupon
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := scanCoupon(m.DB.QueryRowContext(ctx, query, id), &coupon)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &coupon, nil
+}
+
+func (m CouponModel) GetByCreator(userID int64) ([]*Coupon, error) {
+	query := `SELECT ` + couponColumns + ` FROM coupons c WHERE c.created_by = $1 ORDER BY c.created_at DESC, c.id DESC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	coupons := []*Coupon{}
+
+	for rows.Next() {
+		var coupon Coupon
+
+		if err := scanCoupon(rows, &coupon); err != nil {
+			return nil, err
+		}
+
+		coupons = append(coupons, &coupon)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return coupons, nil
+}
+
+// Delete removes the coupon. Orders that used it keep their discount.
+func (m CouponModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, `DELETE FROM coupons WHERE id = $1`, id)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// redeemCoupon locks the coupon with the given code inside tx and checks its
+// validity window and usage limits for the user. Paid orders count as uses,
+// and so do pending ones created after heldSince, so an abandoned checkout
+// cannot hold a use forever. Cancelled and refunded orders give it back.
+func redeemCoupon(ctx context.Context, tx *sql.Tx, code string, userID int64, heldSince time.Time) (*Coupon, error) {
+	query := `
+		SELECT id, code, kind, amount, coalesce(currency, ''), book_id, created_by, max_uses, max_uses_per_user, starts_at, ends_at
+		FROM coupons
+		WHERE lower(code) = lower($1) AND starts_at <= NOW() AND (ends_at IS NULL OR ends_at > NOW())
+		FOR UPDATE
+	`
+
+	var coupon Coupon
+
+	err := tx.QueryRowContext(ctx, query, code).Scan(
+		&coupon.ID,
+		&coupon.Code,
+		&coupon.Kind,
+		&coupon.Amount,
+		&coupon.Currency,
+		&coupon.BookID,
+		&coupon.CreatedBy,
+		&coupon.MaxUses,
+		&coupon.MaxUsesPerUser,
+		&coupon.StartsAt,
+		&coupon.EndsAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrInvalidCoupon
+		default:
+			return nil, err
+		}
+	}
+
+	var uses, userUses int
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT count(*), count(*) FILTER (WHERE user_id = $2)
+		FROM orders
+		WHERE coupon_id = $1 AND (status = 'paid' OR (status = 'pending' AND created_at > $3))
+	`, coupon.ID, userID, heldSince).Scan(&uses, &userUses)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if coupon.MaxUses != nil && uses >= *coupon.MaxUses {
+		return nil, ErrCouponExhausted
+	}
+
+	if coupon.MaxUsesPerUser != nil && userUses >= *coupon.MaxUsesPerUser {
+		return nil, ErrCouponExhausted
+	}
+
+	return &coupon, nil
+}
@@ -0,0 +1,69 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOrder(prices ...int64) *Order {
+	order := &Order{Currency: "USD"}
+
+	for i, price := range prices {
+		bookID := int64(i + 1)
+		order.Items = append(order.Items, &OrderItem{BookID: &bookID, Price: price})
+		order.Total += price
+	}
+
+	return order
+}
+
+func TestApplyCoupon(t *testing.T) {
+	bookTwo := int64(2)
+
+	t.Run("percent store-wide", func(t *testing.T) {
+		order := newTestOrder(1000, 555)
+
+		assert.NoError(t, ApplyCoupon(order, &Coupon{ID: 7, Kind: CouponPercent, Amount: 10}))
+		assert.Equal(t, int64(100), order.Items[0].Discount)
+		assert.Equal(t, int64(55), order.Items[1].Discount)
+		assert.Equal(t, int64(155), order.Discount)
+		assert.Equal(t, int64(1400), order.Total)
+		assert.Equal(t, int64(7), *order.CouponID)
+	})
+
+	t.Run("percent single book", func(t *testing.T) {
+		order := newTestOrder(1000, 500)
+
+		assert.NoError(t, ApplyCoupon(order, &Coupon{Kind: CouponPercent, Amount: 50, BookID: &bookTwo}))
+		assert.Equal(t, int64(0), order.Items[0].Discount)
+		assert.Equal(t, int64(250), order.Items[1].Discount)
+		assert.Equal(t, int64(1250), order.Total)
+	})
+
+	t.Run("fixed split across items", func(t *testing.T) {
+		order := newTestOrder(100, 100, 100)
+
+		assert.NoError(t, ApplyCoupon(order, &Coupon{Kind: CouponFixed, Amount: 100, Currency: "USD"}))
+		assert.Equal(t, int64(100), order.Discount)
+		assert.Equal(t, int64(34), order.Items[0].Discount)
+		assert.Equal(t, int64(33), order.Items[2].Discount)
+		assert.Equal(t, int64(200), order.Total)
+	})
+
+	t.Run("fixed capped at eligible amount", func(t *testing.T) {
+		order := newTestOrder(300, 200)
+
+		assert.NoError(t, ApplyCoupon(order, &Coupon{Kind: CouponFixed, Amount: 5000, Currency: "USD"}))
+		assert.Equal(t, int64(500), order.Discount)
+		assert.Equal(t, int64(0), order.Total)
+	})
+
+	t.Run("not applicable", func(t *testing.T) {
+		other := int64(9)
+
+		assert.ErrorIs(t, ApplyCoupon(newTestOrder(100), &Coupon{Kind: CouponPercent, Amount: 10, BookID: &other}), ErrCouponNotApplicable)
+		assert.ErrorIs(t, ApplyCoupon(newTestOrder(100), &Coupon{Kind: CouponFixed, Amount: 10, Currency: "EUR"}), ErrCouponNotApplicable)
+		assert.ErrorIs(t, ApplyCoupon(newTestOrder(0), &Coupon{Kind: CouponPercent, Amount: 10}), ErrCouponNotApplicable)
+	})
+}
@@ -0,0 +1,165 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	EntitlementSourcePurchase = "purchase"
+	EntitlementSourceGrant    = "grant"
+)
+
+var (
+	ErrDuplicateEntitlement = errors.New("duplicate entitlement")
+)
+
+type Entitlement struct {
+	UserID    int64     `json:"userId"`
+	BookID    int64     `json:"bookId"`
+	Source    string    `json:"source"`
+	OrderID   *int64    `json:"orderId,omitempty"`
+	GrantedBy *int64    `json:"grantedBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type EntitlementModel struct {
+	DB *sql.DB
+}
+
+// Has reports whether the user may read the book's premium chapters: the
+// book's author, accepted collaborators, anyone holding an entitlement from a
+// purchase or an admin grant, and live subscribers when the author takes
+// part in the subscription catalogue.
+func (m EntitlementModel) Has(userID, bookID int64) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM books WHERE id = $2 AND user_id = $1)
+		OR EXISTS (SELECT 1 FROM book_collaborators WHERE book_id = $2 AND user_id = $1 AND status = 'accepted')
+		OR EXISTS (SELECT 1 FROM entitlements WHERE book_id = $2 AND user_id = $1)
+		OR (
+			EXISTS (SELECT 1 FROM books b INNER JOIN subscription_authors sa ON sa.user_id = b.user_id WHERE b.id = $2)
+			AND EXISTS (SELECT 1 FROM subscriptions s WHERE s.user_id = $1 AND ` + liveSubscription + `)
+		)
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var has bool
+
+	err := m.DB.QueryRowContext(ctx, query, userID, bookID).Scan(&has)
+
+	if err != nil {
+		return false, err
+	}
+
+	return has, nil
+}
+
+// Grant gives the user free access to the book on behalf of an admin.
+func (m EntitlementModel) Grant(entitlement *Entitlement) error {
+	query := `
+		INSERT INTO entitlements (user_id, book_id, source, granted_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, book_id) DO NOTHING
+		RETURNING created_at
+	`
+
+	entitlement.Source = EntitlementSourceGrant
+
+	args := []any{entitlement.UserID, entitlement.BookID, entitlement.Source, entitlement.GrantedBy}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&entitlement.CreatedAt)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrDuplicateEntitlement
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Revoke removes an admin grant. Purchased entitlements are only revoked by
+// refunding their order.
+func (m EntitlementModel) Revoke(userID, bookID int64) error {
+	query := `
+		DELETE FROM entitlements
+		WHERE user_id = $1 AND book_id = $2 AND source = $3
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userID, bookID, EntitlementSourceGrant)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (m EntitlementModel) GetByBook(bookID int64) ([]*Entitlement, error) {
+	query := `
+		SELECT user_id, book_id, source, order_id, granted_by, created_at
+		FROM entitlements
+		WHERE book_id = $1
+		ORDER BY created_at DESC, user_id ASC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entitlements := []*Entitlement{}
+
+	for rows.Next() {
+		var entitlement Entitlement
+
+		err := rows.Scan(
+			&entitlement.UserID,
+			&entitlement.BookID,
+			&entitlement.Source,
+			&entitlement.OrderID,
+			&entitlement.GrantedBy,
+			&entitlement.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entitlements = append(entitlements, &entitlement)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entitlements, nil
+}
@@ -1,34 +1,96 @@
 package data
 
 import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
 	"strings"
 
 	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
 )
 
+const (
+	CountExact     = "exact"
+	CountEstimated = "estimated"
+	CountNone      = "none"
+)
+
+const maxOffset = 10_000
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 type Filters struct {
 	Page         int
 	PageSize     int
 	Sort         string
 	SortSafelist []string
+	Cursor       string
+	Count        string
 }
 
 type Metadata struct {
-	CurrentPage  int `json:"currentPage"`
-	PageSize     int `json:"pageSize"`
-	FirstPage    int `json:"firstPage"`
-	LastPage     int `json:"lastPage"`
-	TotalRecords int `json:"totalRecords"`
+	CurrentPage  int    `json:"currentPage,omitempty"`
+	PageSize     int    `json:"pageSize"`
+	FirstPage    int    `json:"firstPage,omitempty"`
+	LastPage     int    `json:"lastPage,omitempty"`
+	TotalRecords *int   `json:"totalRecords,omitempty"`
+	NextCursor   string `json:"nextCursor,omitempty"`
+	PrevCursor   string `json:"prevCursor,omitempty"`
+}
+
+// Cursor is the decoded form of the opaque cursor query parameter. It holds
+// the sort key and id of the row a page starts after (or, when Backward is
+// set, before). Sort is the sort parameter the cursor was issued for, since
+// the key value only makes sense for that column.
+type Cursor struct {
+	Sort     string `json:"s"`
+	Value    string `json:"v"`
+	ID       int64  `json:"id"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+func EncodeCursor(c Cursor) string {
+	js, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(js)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+
+	js, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(js, &c); err != nil || c.ID < 1 {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
 }
 
 func ValidateFilter(v *validator.Validator, f Filters) {
 	v.Check(f.Page > 0, "page", "must be greater than zero")
-	v.Check(f.Page <= 10_000_000, "page", "must be a maximum of 10 million")
 	v.Check(f.PageSize > 0, "page_size", "must be greater than zero")
 	v.Check(f.PageSize <= 100, "page_size", "must be a maximum of 100")
+	v.Check(f.offset() <= maxOffset, "page", "is too deep, use cursor pagination instead")
 
 	v.Check(validator.In(f.Sort, f.SortSafelist...), "sort", "invalid sort value")
+
+	if f.Count != "" {
+		v.Check(validator.In(f.Count, CountExact, CountEstimated, CountNone), "count", "must be one of exact, estimated or none")
+	}
+
+	if f.Cursor != "" {
+		c, err := DecodeCursor(f.Cursor)
+		v.Check(err == nil, "cursor", "invalid cursor value")
+		v.Check(c.Sort == f.Sort, "cursor", "was issued for a different sort order")
+		v.Check(f.Page == 1, "page", "cannot be combined with cursor")
+	}
 }
 
 func (f Filters) sortColumn() string {
@@ -58,6 +120,84 @@ func (f Filters) offset() int {
 	return (f.Page - 1) * f.PageSize
 }
 
+// sortKey describes how a sort column is compared in keyset pagination: the
+// SQL expression used for ordering and the type the cursor value is cast to.
+type sortKey struct {
+	expr string
+	cast string
+}
+
+// keyset builds the ORDER BY clause and, when a cursor is present, the
+// row-value comparison that starts the page after (or before) the cursor.
+// The id tiebreaker follows the sort direction so (key, id) compares as one.
+func (f Filters) keyset(key sortKey, cursor *Cursor, argPos int) (cond string, orderBy string) {
+	direction := f.sortDirecton()
+
+	if cursor != nil && cursor.Backward {
+		if direction == "ASC" {
+			direction = "DESC"
+		} else {
+			direction = "ASC"
+		}
+	}
+
+	orderBy = fmt.Sprintf("ORDER BY %s %s, id %s", key.expr, direction, direction)
+
+	if cursor == nil {
+		return "", orderBy
+	}
+
+	op := ">"
+	if direction == "DESC" {
+		op = "<"
+	}
+
+	cond = fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)", key.expr, op, argPos, key.cast, argPos+1)
+
+	return cond, orderBy
+}
+
+// countRecords counts the rows matched by fromWhere (a "FROM ... WHERE ..."
+// fragment) according to the requested count mode. It returns nil when the
+// caller asked for no count.
+func countRecords(ctx context.Context, db *sql.DB, mode, fromWhere string, args []any) (*int, error) {
+	switch mode {
+	case CountNone:
+		return nil, nil
+
+	case CountEstimated:
+		var plan []byte
+
+		err := db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) SELECT 1 "+fromWhere, args...).Scan(&plan)
+		if err != nil {
+			return nil, err
+		}
+
+		var explain []struct {
+			Plan struct {
+				Rows float64 `json:"Plan Rows"`
+			} `json:"Plan"`
+		}
+
+		if err := json.Unmarshal(plan, &explain); err != nil || len(explain) == 0 {
+			return nil, fmt.Errorf("unexpected query plan: %s", plan)
+		}
+
+		total := int(explain[0].Plan.Rows)
+		return &total, nil
+
+	default:
+		var total int
+
+		err := db.QueryRowContext(ctx, "SELECT count(*) "+fromWhere, args...).Scan(&total)
+		if err != nil {
+			return nil, err
+		}
+
+		return &total, nil
+	}
+}
+
 func calculateMetadata(totalRecords, page, pageSize int) Metadata {
 
 	lastpage := int(math.Ceil(float64(totalRecords) / float64(pageSize)))
@@ -66,7 +206,7 @@ func calculateMetadata(totalRecords, page, pageSize int) Metadata {
 		CurrentPage:  page,
 		PageSize:     pageSize,
 		FirstPage:    1,
-		TotalRecords: totalRecords,
+		TotalRecords: &totalRecords,
 	}
 
 	if lastpage == 0 {
@@ -0,0 +1,96 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{Sort: "-created_at", Value: "2025-04-03 02:02:02+00", ID: 42, Backward: true}
+
+	got, err := DecodeCursor(EncodeCursor(want))
+
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, s := range []string{"", "not base64!", EncodeCursor(Cursor{Value: "x"})} {
+		_, err := DecodeCursor(s)
+		assert.ErrorIs(t, err, ErrInvalidCursor, "cursor %q", s)
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	key := sortKey{expr: "created_at", cast: "timestamptz"}
+
+	tests := []struct {
+		name      string
+		sort      string
+		cursor    *Cursor
+		wantCond  string
+		wantOrder string
+	}{
+		{
+			name:      "No cursor",
+			sort:      "created_at",
+			wantOrder: "ORDER BY created_at ASC, id ASC",
+		},
+		{
+			name:      "Forward ascending",
+			sort:      "created_at",
+			cursor:    &Cursor{ID: 1},
+			wantCond:  "(created_at, id) > ($3::timestamptz, $4)",
+			wantOrder: "ORDER BY created_at ASC, id ASC",
+		},
+		{
+			name:      "Forward descending",
+			sort:      "-created_at",
+			cursor:    &Cursor{ID: 1},
+			wantCond:  "(created_at, id) < ($3::timestamptz, $4)",
+			wantOrder: "ORDER BY created_at DESC, id DESC",
+		},
+		{
+			name:      "Backward descending",
+			sort:      "-created_at",
+			cursor:    &Cursor{ID: 1, Backward: true},
+			wantCond:  "(created_at, id) > ($3::timestamptz, $4)",
+			wantOrder: "ORDER BY created_at ASC, id ASC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := Filters{Sort: tt.sort, SortSafelist: []string{tt.sort}}
+
+			cond, orderBy := f.keyset(key, tt.cursor, 3)
+
+			assert.Equal(t, tt.wantCond, cond)
+			assert.Equal(t, tt.wantOrder, orderBy)
+		})
+	}
+}
+
+func TestValidateFilterCursor(t *testing.T) {
+	f := Filters{Page: 2, PageSize: 20, Sort: "id", SortSafelist: []string{"id"}, Cursor: EncodeCursor(Cursor{Sort: "id", Value: "1", ID: 1})}
+
+	v := validator.New()
+	ValidateFilter(v, f)
+
+	assert.Equal(t, "cannot be combined with cursor", v.Errors["page"])
+}
+
+func TestValidateFilterCursorSort(t *testing.T) {
+	safelist := []string{"title", "-published_at"}
+	cursor := EncodeCursor(Cursor{Sort: "title", Value: "Dune", ID: 7})
+
+	v := validator.New()
+	ValidateFilter(v, Filters{Page: 1, PageSize: 20, Sort: "-published_at", SortSafelist: safelist, Cursor: cursor})
+	assert.Equal(t, "was issued for a different sort order", v.Errors["cursor"])
+
+	v = validator.New()
+	ValidateFilter(v, Filters{Page: 1, PageSize: 20, Sort: "title", SortSafelist: safelist, Cursor: cursor})
+	assert.True(t, v.IsValid())
+}
@@ -0,0 +1,665 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// PlatformFeePercent is the share of every sale, after discounts, kept by
+// the store. The author is credited the rest, including rounding.
+const PlatformFeePercent = 30
+
+const (
+	LedgerSale   = "sale"
+	LedgerRefund = "refund"
+	LedgerPayout = "payout"
+)
+
+const (
+	AccountCash            = "cash"
+	AccountAuthorPayable   = "author_payable"
+	AccountPlatformRevenue = "platform_revenue"
+)
+
+var (
+	ErrUnbalancedTransaction = errors.New("ledger transaction does not balance")
+	ErrInvalidPayoutPeriod   = errors.New("payout period must end after the previous one and not in the future")
+)
+
+var EarningsPeriods = []string{"day", "week", "month"}
+
+// LedgerEntry is one leg of a double-entry ledger transaction. Debits are
+// positive and credits negative, so the entries of a transaction sum to zero
+// per currency. Money owed to an author is a credit on their author_payable
+// account.
+type LedgerEntry struct {
+	Account  string
+	UserID   *int64
+	BookID   *int64
+	Amount   int64
+	Currency string
+}
+
+// SaleLine is the part of a paid order that belongs to one book.
+type SaleLine struct {
+	BookID   *int64
+	AuthorID *int64
+	Amount   int64
+	Currency string
+}
+
+// SaleEntries splits each sale line into cash received, the platform fee and
+// the author's share. Lines without a known author are kept by the platform.
+func SaleEntries(lines []SaleLine, feePercent int64) []LedgerEntry {
+	entries := []LedgerEntry{}
+
+	for _, line := range lines {
+		if line.Amount == 0 {
+			continue
+		}
+
+		fee := line.Amount * feePercent / 100
+
+		if line.AuthorID == nil {
+			fee = line.Amount
+		}
+
+		entries = append(entries, LedgerEntry{Account: AccountCash, BookID: line.BookID, Amount: line.Amount, Currency: line.Currency})
+
+		if share := line.Amount - fee; share > 0 {
+			entries = append(entries, LedgerEntry{Account: AccountAuthorPayable, UserID: line.AuthorID, BookID: line.BookID, Amount: -share, Currency: line.Currency})
+		}
+
+		if fee > 0 {
+			entries = append(entries, LedgerEntry{Account: AccountPlatformRevenue, BookID: line.BookID, Amount: -fee, Currency: line.Currency})
+		}
+	}
+
+	return entries
+}
+
+// Balanced reports whether the entries sum to zero in every currency.
+func Balanced(entries []LedgerEntry) bool {
+	sums := make(map[string]int64)
+
+	for _, entry := range entries {
+		sums[entry.Currency] += entry.Amount
+	}
+
+	for _, sum := range sums {
+		if sum != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// postLedgerTransaction records a balanced set of entries as one ledger
+// transaction inside tx.
+func postLedgerTransaction(ctx context.Context, tx *sql.Tx, kind string, orderID, statementID, periodID *int64, entries []LedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if !Balanced(entries) {
+		return ErrUnbalancedTransaction
+	}
+
+	var transactionID int64
+
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO ledger_transactions (kind, order_id, payout_statement_id)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, kind, orderID, statementID).Scan(&transactionID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO ledger_entries (transaction_id, account, user_id, book_id, amount, currency, payout_period_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, transactionID, entry.Account, entry.UserID, entry.BookID, entry.Amount, entry.Currency, periodID)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// postSale records the ledger transaction for a paid order.
+func postSale(ctx context.Context, tx *sql.Tx, order *Order) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT oi.book_id, b.user_id, oi.price - oi.discount
+		FROM order_items oi
+		LEFT JOIN books b ON b.id = oi.book_id
+		WHERE oi.order_id = $1
+		ORDER BY oi.id ASC
+	`, order.ID)
+
+	if err != nil {
+		return err
+	}
+
+	lines := []SaleLine{}
+
+	for rows.Next() {
+		line := SaleLine{Currency: order.Currency}
+
+		if err := rows.Scan(&line.BookID, &line.AuthorID, &line.Amount); err != nil {
+			rows.Close()
+			return err
+		}
+
+		lines = append(lines, line)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	return postLedgerTransaction(ctx, tx, LedgerSale, &order.ID, nil, nil, SaleEntries(lines, PlatformFeePercent))
+}
+
+// postRefund reverses every entry of the order's sale transaction. Author
+// shares that were already paid out leave a negative balance that is
+// settled against later earnings.
+func postRefund(ctx context.Context, tx *sql.Tx, order *Order) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT e.account, e.user_id, e.book_id, e.amount, e.currency
+		FROM ledger_entries e
+		INNER JOIN ledger_transactions t ON t.id = e.transaction_id
+		WHERE t.order_id = $1 AND t.kind = $2
+		ORDER BY e.id ASC
+	`, order.ID, LedgerSale)
+
+	if err != nil {
+		return err
+	}
+
+	entries := []LedgerEntry{}
+
+	for rows.Next() {
+		var entry LedgerEntry
+
+		if err := rows.Scan(&entry.Account, &entry.UserID, &entry.BookID, &entry.Amount, &entry.Currency); err != nil {
+			rows.Close()
+			return err
+		}
+
+		entry.Amount = -entry.Amount
+		entries = append(entries, entry)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	return postLedgerTransaction(ctx, tx, LedgerRefund, &order.ID, nil, nil, entries)
+}
+
+type BookEarnings struct {
+	BookID   *int64 `json:"bookId"`
+	Title    string `json:"title"`
+	Currency string `json:"currency"`
+	Sales    int    `json:"sales"`
+	Earned   int64  `json:"earned"`
+	Refunded int64  `json:"refunded"`
+	Net      int64  `json:"net"`
+}
+
+type PeriodEarnings struct {
+	Period   time.Time `json:"period"`
+	Currency string    `json:"currency"`
+	Sales    int       `json:"sales"`
+	Earned   int64     `json:"earned"`
+	Refunded int64     `json:"refunded"`
+	Net      int64     `json:"net"`
+}
+
+type Balance struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+}
+
+type Earnings struct {
+	ByBook   []*BookEarnings   `json:"byBook"`
+	ByPeriod []*PeriodEarnings `json:"byPeriod"`
+	Unpaid   []*Balance        `json:"unpaid"`
+}
+
+// EarningsEntry is one movement on an author's account, as exported to CSV.
+type EarningsEntry struct {
+	CreatedAt time.Time
+	Kind      string
+	OrderID   *int64
+	BookID    *int64
+	Title     string
+	Amount    int64
+	Currency  string
+}
+
+func ValidateEarningsQuery(v *validator.Validator, from, to time.Time, period string) {
+	v.Check(to.After(from), "to", "must be after from")
+	v.Check(to.Sub(from) <= 5*366*24*time.Hour, "to", "range must not be longer than 5 years")
+	v.Check(validator.In(period, EarningsPeriods...), "period", "must be day, week or month")
+}
+
+type PayoutStatementLine struct {
+	BookID *int64 `json:"bookId"`
+	Title  string `json:"title"`
+	Amount int64  `json:"amount"`
+}
+
+type PayoutStatement struct {
+	ID             int64                  `json:"id"`
+	PayoutPeriodID int64                  `json:"payoutPeriodId"`
+	UserID         int64                  `json:"userId"`
+	Currency       string                 `json:"currency"`
+	Amount         int64                  `json:"amount"`
+	CreatedAt      time.Time              `json:"createdAt"`
+	Lines          []*PayoutStatementLine `json:"lines,omitempty"`
+}
+
+type PayoutPeriod struct {
+	ID          int64              `json:"id"`
+	PeriodStart *time.Time         `json:"periodStart"`
+	PeriodEnd   time.Time          `json:"periodEnd"`
+	ClosedBy    *int64             `json:"closedBy"`
+	ClosedAt    time.Time          `json:"closedAt"`
+	Statements  []*PayoutStatement `json:"statements"`
+}
+
+type LedgerModel struct {
+	DB *sql.DB
+}
+
+// authorEntries filters ledger entries down to an author's sales and refunds
+// in [$2, $3).
+const authorEntries = `
+	FROM ledger_entries e
+	INNER JOIN ledger_transactions t ON t.id = e.transaction_id
+	LEFT JOIN books b ON b.id = e.book_id
+	WHERE e.account = 'author_payable' AND e.user_id = $1 AND t.kind IN ('sale', 'refund')
+	AND e.created_at >= $2 AND e.created_at < $3
+`
+
+// Earnings breaks the author's sales and refunds in [from, to) down by book
+// and by period, and reports the balance not yet paid out.
+func (m LedgerModel) Earnings(userID int64, from, to time.Time, period string) (*Earnings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	earnings := &Earnings{ByBook: []*BookEarnings{}, ByPeriod: []*PeriodEarnings{}, Unpaid: []*Balance{}}
+
+	rows, err := m.DB.QueryContext(ctx, `
+		SELECT e.book_id, coalesce(b.title, ''), e.currency,
+			count(*) FILTER (WHERE t.kind = 'sale'),
+			coalesce(-sum(e.amount) FILTER (WHERE t.kind = 'sale'), 0),
+			coalesce(sum(e.amount) FILTER (WHERE t.kind = 'refund'), 0),
+			-sum(e.amount) AS net
+		`+authorEntries+`
+		GROUP BY e.book_id, b.title, e.currency
+		ORDER BY net DESC, e.book_id ASC
+	`, userID, from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var row BookEarnings
+
+		if err := rows.Scan(&row.BookID, &row.Title, &row.Currency, &row.Sales, &row.Earned, &row.Refunded, &row.Net); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		earnings.ByBook = append(earnings.ByBook, &row)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = m.DB.QueryContext(ctx, `
+		SELECT date_trunc($4, e.created_at) AS period, e.currency,
+			count(*) FILTER (WHERE t.kind = 'sale'),
+			coalesce(-sum(e.amount) FILTER (WHERE t.kind = 'sale'), 0),
+			coalesce(sum(e.amount) FILTER (WHERE t.kind = 'refund'), 0),
+			-sum(e.amount)
+		`+authorEntries+`
+		GROUP BY period, e.currency
+		ORDER BY period ASC, e.currency ASC
+	`, userID, from, to, period)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var row PeriodEarnings
+
+		if err := rows.Scan(&row.Period, &row.Currency, &row.Sales, &row.Earned, &row.Refunded, &row.Net); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		earnings.ByPeriod = append(earnings.ByPeriod, &row)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = m.DB.QueryContext(ctx, `
+		SELECT currency, -sum(amount)
+		FROM ledger_entries
+		WHERE account = 'author_payable' AND user_id = $1 AND payout_period_id IS NULL
+		GROUP BY currency
+		ORDER BY currency ASC
+	`, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var balance Balance
+
+		if err := rows.Scan(&balance.Currency, &balance.Amount); err != nil {
+			return nil, err
+		}
+
+		earnings.Unpaid = append(earnings.Unpaid, &balance)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return earnings, nil
+}
+
+// EarningsEntries lists the author's individual sales and refunds in
+// [from, to), oldest first.
+func (m LedgerModel) EarningsEntries(userID int64, from, to time.Time) ([]*EarningsEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, `
+		SELECT e.created_at, t.kind, t.order_id, e.book_id, coalesce(b.title, ''), -e.amount, e.currency
+		`+authorEntries+`
+		ORDER BY e.created_at ASC, e.id ASC
+	`, userID, from, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := []*EarningsEntry{}
+
+	for rows.Next() {
+		var entry EarningsEntry
+
+		err := rows.Scan(&entry.CreatedAt, &entry.Kind, &entry.OrderID, &entry.BookID, &entry.Title, &entry.Amount, &entry.Currency)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// ClosePayoutPeriod closes the period ending at periodEnd. Every author with
+// a positive unpaid balance earned before periodEnd gets a payout statement,
+// and a payout transaction moves that balance out of their payable account.
+// Negative balances are carried over to the next period.
+func (m LedgerModel) ClosePayoutPeriod(periodEnd time.Time, closedBy int64) (*PayoutPeriod, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	// Closing periods is serialised so two admins cannot sweep the same
+	// entries into overlapping periods.
+	_, err = tx.ExecContext(ctx, `LOCK TABLE payout_periods IN EXCLUSIVE MODE`)
+	if err != nil {
+		return nil, err
+	}
+
+	period := &PayoutPeriod{PeriodEnd: periodEnd, ClosedBy: &closedBy, Statements: []*PayoutStatement{}}
+
+	err = tx.QueryRowContext(ctx, `SELECT max(period_end) FROM payout_periods`).Scan(&period.PeriodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	if periodEnd.After(time.Now()) || (period.PeriodStart != nil && !periodEnd.After(*period.PeriodStart)) {
+		return nil, ErrInvalidPayoutPeriod
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO payout_periods (period_start, period_end, closed_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, closed_at
+	`, period.PeriodStart, period.PeriodEnd, closedBy).Scan(&period.ID, &period.ClosedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT user_id, currency, -sum(amount) AS balance
+		FROM ledger_entries
+		WHERE account = 'author_payable' AND user_id IS NOT NULL AND payout_period_id IS NULL AND created_at < $1
+		GROUP BY user_id, currency
+		HAVING -sum(amount) > 0
+		ORDER BY user_id ASC, currency ASC
+	`, periodEnd)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		statement := PayoutStatement{PayoutPeriodID: period.ID}
+
+		if err := rows.Scan(&statement.UserID, &statement.Currency, &statement.Amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		period.Statements = append(period.Statements, &statement)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, statement := range period.Statements {
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO payout_statements (payout_period_id, user_id, currency, amount)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id, created_at
+		`, period.ID, statement.UserID, statement.Currency, statement.Amount).Scan(&statement.ID, &statement.CreatedAt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE ledger_entries
+			SET payout_period_id = $1
+			WHERE account = 'author_payable' AND user_id = $2 AND currency = $3 AND payout_period_id IS NULL AND created_at < $4
+		`, period.ID, statement.UserID, statement.Currency, periodEnd)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries := []LedgerEntry{
+			{Account: AccountAuthorPayable, UserID: &statement.UserID, Amount: statement.Amount, Currency: statement.Currency},
+			{Account: AccountCash, UserID: &statement.UserID, Amount: -statement.Amount, Currency: statement.Currency},
+		}
+
+		err = postLedgerTransaction(ctx, tx, LedgerPayout, nil, &statement.ID, &period.ID, entries)
+		if err != nil {
+			return nil, fmt.Errorf("posting payout for user %d: %w", statement.UserID, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return period, nil
+}
+
+func (m LedgerModel) GetPayoutPeriod(id int64) (*PayoutPeriod, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var period PayoutPeriod
+
+	err := m.DB.QueryRowContext(ctx, `
+		SELECT id, period_start, period_end, closed_by, closed_at
+		FROM payout_periods
+		WHERE id = $1
+	`, id).Scan(&period.ID, &period.PeriodStart, &period.PeriodEnd, &period.ClosedBy, &period.ClosedAt)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	period.Statements, err = m.getStatements(ctx, `s.payout_period_id = $1`, period.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &period, nil
+}
+
+func (m LedgerModel) GetStatementsByUser(userID int64) ([]*PayoutStatement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return m.getStatements(ctx, `s.user_id = $1`, userID)
+}
+
+// getStatements loads payout statements with their per-book lines.
+func (m LedgerModel) getStatements(ctx context.Context, where string, arg any) ([]*PayoutStatement, error) {
+	rows, err := m.DB.QueryContext(ctx, `
+		SELECT s.id, s.payout_period_id, s.user_id, s.currency, s.amount, s.created_at
+		FROM payout_statements s
+		WHERE `+where+`
+		ORDER BY s.created_at DESC, s.id DESC
+	`, arg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []*PayoutStatement{}
+	byID := make(map[int64]*PayoutStatement)
+
+	for rows.Next() {
+		statement := PayoutStatement{Lines: []*PayoutStatementLine{}}
+
+		err := rows.Scan(&statement.ID, &statement.PayoutPeriodID, &statement.UserID, &statement.Currency, &statement.Amount, &statement.CreatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		statements = append(statements, &statement)
+		byID[statement.ID] = &statement
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = m.DB.QueryContext(ctx, `
+		SELECT s.id, e.book_id, coalesce(b.title, ''), -sum(e.amount) AS amount
+		FROM payout_statements s
+		INNER JOIN ledger_entries e ON e.payout_period_id = s.payout_period_id AND e.user_id = s.user_id AND e.currency = s.currency
+		INNER JOIN ledger_transactions t ON t.id = e.transaction_id AND t.kind <> 'payout'
+		LEFT JOIN books b ON b.id = e.book_id
+		WHERE e.account = 'author_payable' AND `+where+`
+		GROUP BY s.id, e.book_id, b.title
+		ORDER BY s.id ASC, amount DESC
+	`, arg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var statementID int64
+		var line PayoutStatementLine
+
+		if err := rows.Scan(&statementID, &line.BookID, &line.Title, &line.Amount); err != nil {
+			return nil, err
+		}
+
+		if statement, ok := byID[statementID]; ok {
+			statement.Lines = append(statement.Lines, &line)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return statements, nil
+}
@@ -0,0 +1,36 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaleEntries(t *testing.T) {
+	author := int64(3)
+	bookOne, bookTwo := int64(1), int64(2)
+
+	entries := SaleEntries([]SaleLine{
+		{BookID: &bookOne, AuthorID: &author, Amount: 999, Currency: "USD"},
+		{BookID: &bookTwo, AuthorID: nil, Amount: 500, Currency: "USD"},
+		{BookID: &bookOne, AuthorID: &author, Amount: 0, Currency: "USD"},
+	}, 30)
+
+	assert.True(t, Balanced(entries))
+	assert.Len(t, entries, 5)
+
+	// 30% of 999 rounds down to 299; the author keeps the remainder.
+	assert.Equal(t, LedgerEntry{Account: AccountCash, BookID: &bookOne, Amount: 999, Currency: "USD"}, entries[0])
+	assert.Equal(t, LedgerEntry{Account: AccountAuthorPayable, UserID: &author, BookID: &bookOne, Amount: -700, Currency: "USD"}, entries[1])
+	assert.Equal(t, LedgerEntry{Account: AccountPlatformRevenue, BookID: &bookOne, Amount: -299, Currency: "USD"}, entries[2])
+
+	// Without a known author the platform keeps the whole sale.
+	assert.Equal(t, int64(-500), entries[4].Amount)
+	assert.Equal(t, AccountPlatformRevenue, entries[4].Account)
+}
+
+func TestBalanced(t *testing.T) {
+	assert.True(t, Balanced(nil))
+	assert.False(t, Balanced([]LedgerEntry{{Amount: 100, Currency: "USD"}, {Amount: -100, Currency: "EUR"}}))
+	assert.True(t, Balanced([]LedgerEntry{{Amount: 100, Currency: "USD"}, {Amount: -60, Currency: "USD"}, {Amount: -40, Currency: "USD"}}))
+}
@@ -0,0 +1,186 @@
+package data
+
+import (
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// MergeConflict is a region both sides changed differently. Field names the
+// chapter field it is in and, for text merged line by line, BaseLine is the
+// 1-based line in the base text the region starts at.
+type MergeConflict struct {
+	Field    string `json:"field,omitempty"`
+	BaseLine int    `json:"baseLine,omitempty"`
+	Base     string `json:"base"`
+	Yours    string `json:"yours"`
+	Current  string `json:"current"`
+}
+
+// textHunk replaces base lines [start, end) with lines.
+type textHunk struct {
+	start int
+	end   int
+	lines []string
+}
+
+// Merge3 merges two edits of the same base text line by line. Changes to
+// different regions are combined; changes to the same or adjacent lines are
+// only accepted when both sides made the same change, and are otherwise
+// reported as conflicts. When there are conflicts the merged text should
+// not be used.
+func Merge3(base, yours, current string) (string, []MergeConflict) {
+	baseLines := splitLines(base)
+	yourHunks := lineHunks(base, yours)
+	currentHunks := lineHunks(base, current)
+
+	var out strings.Builder
+	conflicts := []MergeConflict{}
+
+	pos := 0
+
+	for len(yourHunks) > 0 || len(currentHunks) > 0 {
+		// Start a group at the earliest hunk of either side and grow it over
+		// every hunk that overlaps or touches it.
+		var mine, theirs []textHunk
+		var start, end int
+
+		if len(currentHunks) == 0 || (len(yourHunks) > 0 && yourHunks[0].start <= currentHunks[0].start) {
+			mine, yourHunks = append(mine, yourHunks[0]), yourHunks[1:]
+			start, end = mine[0].start, mine[0].end
+		} else {
+			theirs, currentHunks = append(theirs, currentHunks[0]), currentHunks[1:]
+			start, end = theirs[0].start, theirs[0].end
+		}
+
+		for grew := true; grew; {
+			grew = false
+
+			for len(yourHunks) > 0 && yourHunks[0].start <= end {
+				mine, yourHunks = append(mine, yourHunks[0]), yourHunks[1:]
+				end = max(end, mine[len(mine)-1].end)
+				grew = true
+			}
+
+			for len(currentHunks) > 0 && currentHunks[0].start <= end {
+				theirs, currentHunks = append(theirs, currentHunks[0]), currentHunks[1:]
+				end = max(end, theirs[len(theirs)-1].end)
+				grew = true
+			}
+		}
+
+		out.WriteString(strings.Join(baseLines[pos:start], ""))
+
+		ours := applyHunks(baseLines, start, end, mine)
+		other := applyHunks(baseLines, start, end, theirs)
+
+		switch {
+		case len(theirs) == 0:
+			out.WriteString(ours)
+		case len(mine) == 0 || ours == other:
+			out.WriteString(other)
+		default:
+			conflicts = append(conflicts, MergeConflict{
+				BaseLine: start + 1,
+				Base:     strings.Join(baseLines[start:end], ""),
+				Yours:    ours,
+				Current:  other,
+			})
+
+			out.WriteString(other)
+		}
+
+		pos = end
+	}
+
+	out.WriteString(strings.Join(baseLines[pos:], ""))
+
+	return out.String(), conflicts
+}
+
+// MergeField merges two edits of a single-line field such as a title. A
+// side that kept the base value gives way to the other; when both changed it
+// differently the conflict is returned and current should be kept.
+func MergeField(field, base, yours, current string) (string, *MergeConflict) {
+	switch {
+	case yours == base:
+		return current, nil
+	case current == base, current == yours:
+		return yours, nil
+	}
+
+	return current, &MergeConflict{Field: field, Base: base, Yours: yours, Current: current}
+}
+
+// applyHunks returns base lines [start, end) with the hunks applied. The
+// hunks must lie inside the range and be in order.
+func applyHunks(baseLines []string, start, end int, hunks []textHunk) string {
+	var out strings.Builder
+
+	pos := start
+
+	for _, h := range hunks {
+		out.WriteString(strings.Join(baseLines[pos:h.start], ""))
+		out.WriteString(strings.Join(h.lines, ""))
+		pos = h.end
+	}
+
+	out.WriteString(strings.Join(baseLines[pos:end], ""))
+
+	return out.String()
+}
+
+// lineHunks lists the changes that turn base into other, in base order.
+func lineHunks(base, other string) []textHunk {
+	dmp := diffmatchpatch.New()
+
+	a, b, lineArray := dmp.DiffLinesToRunes(base, other)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMainRunes(a, b, false), lineArray)
+
+	hunks := []textHunk{}
+	var current *textHunk
+
+	pos := 0
+
+	for _, d := range diffs {
+		lines := splitLines(d.Text)
+
+		if d.Type == diffmatchpatch.DiffEqual {
+			if current != nil {
+				hunks = append(hunks, *current)
+				current = nil
+			}
+
+			pos += len(lines)
+			continue
+		}
+
+		if current == nil {
+			current = &textHunk{start: pos, end: pos}
+		}
+
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			pos += len(lines)
+			current.end = pos
+		case diffmatchpatch.DiffInsert:
+			current.lines = append(current.lines, lines...)
+		}
+	}
+
+	if current != nil {
+		hunks = append(hunks, *current)
+	}
+
+	return hunks
+}
+
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
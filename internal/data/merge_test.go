@@ -0,0 +1,105 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge3(t *testing.T) {
+	base := "one\ntwo\nthree\nfour\nfive\nsix\n"
+
+	tests := []struct {
+		name      string
+		yours     string
+		current   string
+		merged    string
+		conflicts []MergeConflict
+	}{
+		{
+			name:    "separate regions",
+			yours:   "ONE\ntwo\nthree\nfour\nfive\nsix\n",
+			current: "one\ntwo\nthree\nfour\nfive\nSIX\n",
+			merged:  "ONE\ntwo\nthree\nfour\nfive\nSIX\n",
+		},
+		{
+			name:    "insert and delete",
+			yours:   "one\ntwo\ntwo and a half\nthree\nfour\nfive\nsix\n",
+			current: "one\ntwo\nthree\nfive\nsix\n",
+			merged:  "one\ntwo\ntwo and a half\nthree\nfive\nsix\n",
+		},
+		{
+			name:    "only yours changed",
+			yours:   "one\n2\nthree\nfour\nfive\nsix\n",
+			current: base,
+			merged:  "one\n2\nthree\nfour\nfive\nsix\n",
+		},
+		{
+			name:    "same change on both sides",
+			yours:   "one\n2\nthree\nfour\nfive\nsix\n",
+			current: "one\n2\nthree\nfour\nfive\nsix\n",
+			merged:  "one\n2\nthree\nfour\nfive\nsix\n",
+		},
+		{
+			name:    "same line changed differently",
+			yours:   "one\nTWO\nthree\nfour\nfive\nsix\n",
+			current: "one\n2\nthree\nfour\nfive\nsix\n",
+			merged:  "one\n2\nthree\nfour\nfive\nsix\n",
+			conflicts: []MergeConflict{
+				{BaseLine: 2, Base: "two\n", Yours: "TWO\n", Current: "2\n"},
+			},
+		},
+		{
+			name:    "adjacent lines conflict",
+			yours:   "one\nTWO\nthree\nfour\nfive\nsix\n",
+			current: "one\ntwo\n3\nfour\nfive\nsix\n",
+			merged:  "one\ntwo\n3\nfour\nfive\nsix\n",
+			conflicts: []MergeConflict{
+				{BaseLine: 2, Base: "two\nthree\n", Yours: "TWO\nthree\n", Current: "two\n3\n"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := Merge3(base, tt.yours, tt.current)
+
+			if tt.conflicts == nil {
+				tt.conflicts = []MergeConflict{}
+			}
+
+			assert.Equal(t, tt.merged, merged)
+			assert.Equal(t, tt.conflicts, conflicts)
+		})
+	}
+}
+
+func TestMergeField(t *testing.T) {
+	tests := []struct {
+		name     string
+		yours    string
+		current  string
+		merged   string
+		conflict bool
+	}{
+		{name: "unchanged", yours: "Dune", current: "Dune", merged: "Dune"},
+		{name: "yours changed", yours: "Arrakis", current: "Dune", merged: "Arrakis"},
+		{name: "current changed", yours: "Dune", current: "Dune Messiah", merged: "Dune Messiah"},
+		{name: "same change", yours: "Arrakis", current: "Arrakis", merged: "Arrakis"},
+		{name: "different changes", yours: "Arrakis", current: "Dune Messiah", merged: "Dune Messiah", conflict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflict := MergeField("title", "Dune", tt.yours, tt.current)
+
+			assert.Equal(t, tt.merged, merged)
+
+			if tt.conflict {
+				assert.Equal(t, &MergeConflict{Field: "title", Base: "Dune", Yours: tt.yours, Current: tt.current}, conflict)
+			} else {
+				assert.Nil(t, conflict)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package mock
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type AnnotationModel struct {
+	Annotations []*data.Annotation
+}
+
+func (m *AnnotationModel) find(id int64) (int, *data.Annotation) {
+	for i, a := range m.Annotations {
+		if a.ID == id {
+			return i, a
+		}
+	}
+
+	return -1, nil
+}
+
+// list returns the user's annotations that match in reading order.
+func (m *AnnotationModel) list(userID int64, match func(a *data.Annotation) bool) []*data.Annotation {
+	annotations := []*data.Annotation{}
+
+	for _, a := range m.Annotations {
+		if a.UserID == userID && match(a) {
+			annotation := *a
+			annotations = append(annotations, &annotation)
+		}
+	}
+
+	sort.SliceStable(annotations, func(i, j int) bool {
+		if annotations[i].ChapterID != annotations[j].ChapterID {
+			return annotations[i].ChapterID < annotations[j].ChapterID
+		}
+
+		return annotations[i].Start < annotations[j].Start
+	})
+
+	return annotations
+}
+
+func (m *AnnotationModel) Insert(annotation *data.Annotation) error {
+	annotation.ID = int64(len(m.Annotations) + 1)
+	annotation.CreatedAt = time.Now()
+	annotation.UpdatedAt = annotation.CreatedAt
+	annotation.Version = 1
+
+	a := *annotation
+	m.Annotations = append(m.Annotations, &a)
+
+	return nil
+}
+
+func (m *AnnotationModel) Get(id int64) (*data.Annotation, error) {
+	_, a := m.find(id)
+
+	if a == nil {
+		return nil, data.ErrRecordNotFound
+	}
+
+	annotation := *a
+	return &annotation, nil
+}
+
+func (m *AnnotationModel) GetByChapter(userID, chapterID int64) ([]*data.Annotation, error) {
+	return m.list(userID, func(a *data.Annotation) bool { return a.ChapterID == chapterID }), nil
+}
+
+func (m *AnnotationModel) GetByBook(userID, bookID int64) ([]*data.Annotation, error) {
+	return m.list(userID, func(a *data.Annotation) bool { return a.BookID == bookID }), nil
+}
+
+func (m *AnnotationModel) Update(annotation *data.Annotation) error {
+	_, a := m.find(annotation.ID)
+
+	if a == nil || a.Version != annotation.Version {
+		return data.ErrEditConflict
+	}
+
+	annotation.UpdatedAt = time.Now()
+	annotation.Version++
+	*a = *annotation
+
+	return nil
+}
+
+func (m *AnnotationModel) Delete(id int64) error {
+	i, a := m.find(id)
+
+	if a == nil {
+		return data.ErrRecordNotFound
+	}
+
+	m.Annotations = append(m.Annotations[:i], m.Annotations[i+1:]...)
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type AutosaveModel struct {
+	// Autosaves holds each user's autosaves.
+	Autosaves map[int64][]*data.ChapterAutosave
+}
+
+func (m *AutosaveModel) Save(userID int64, autosave *data.ChapterAutosave) error {
+	_ = m.Delete(userID, autosave.ChapterID)
+
+	if m.Autosaves == nil {
+		m.Autosaves = make(map[int64][]*data.ChapterAutosave)
+	}
+
+	autosave.UpdatedAt = time.Now()
+
+	a := *autosave
+	m.Autosaves[userID] = append(m.Autosaves[userID], &a)
+
+	return nil
+}
+
+func (m *AutosaveModel) Get(userID, chapterID int64) (*data.ChapterAutosave, error) {
+	for _, a := range m.Autosaves[userID] {
+		if a.ChapterID == chapterID {
+			autosave := *a
+			return &autosave, nil
+		}
+	}
+
+	return nil, data.ErrRecordNotFound
+}
+
+func (m *AutosaveModel) Delete(userID, chapterID int64) error {
+	for i, a := range m.Autosaves[userID] {
+		if a.ChapterID == chapterID {
+			m.Autosaves[userID] = append(m.Autosaves[userID][:i], m.Autosaves[userID][i+1:]...)
+			return nil
+		}
+	}
+
+	return data.ErrRecordNotFound
+}
+
+func (m *AutosaveModel) Purge(before time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,130 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type BookModel struct {
+	Books []*data.Book
+}
+
+func (m *BookModel) find(id int64, trashed bool) *data.Book {
+	for _, b := range m.Books {
+		if b.ID == id && (b.DeletedAt != nil) == trashed {
+			return b
+		}
+	}
+
+	return nil
+}
+
+func (m *BookModel) Delete(id int64) error {
+	b := m.find(id, false)
+
+	if b == nil {
+		return data.ErrRecordNotFound
+	}
+
+	now := time.Now()
+	b.DeletedAt = &now
+
+	return nil
+}
+
+func (m *BookModel) Get(id int64) (*data.Book, error) {
+	b := m.find(id, false)
+
+	if b == nil {
+		return nil, data.ErrRecordNotFound
+	}
+
+	book := *b
+	return &book, nil
+}
+
+func (m *BookModel) GetAll(title string, filters data.Filters) ([]*data.Book, *data.Metadata, error) {
+	return m.list(func(b *data.Book) bool { return true }, filters)
+}
+
+func (m *BookModel) GetAllByUser(title string, filters data.Filters, userID int64) ([]*data.Book, *data.Metadata, error) {
+	return m.list(func(b *data.Book) bool { return b.UserID == userID }, filters)
+}
+
+func (m *BookModel) list(match func(b *data.Book) bool, filters data.Filters) ([]*data.Book, *data.Metadata, error) {
+	books := []*data.Book{}
+
+	for _, b := range m.Books {
+		if b.DeletedAt == nil && match(b) {
+			book := *b
+			books = append(books, &book)
+		}
+	}
+
+	return page(books, filters), pageMetadata(len(books), filters), nil
+}
+
+func (m *BookModel) Insert(book *data.Book) error {
+	book.ID = int64(len(m.Books) + 1)
+	book.CreatedAt = time.Now()
+	book.Version = 1
+
+	b := *book
+	m.Books = append(m.Books, &b)
+
+	return nil
+}
+
+func (m *BookModel) Update(book *data.Book) error {
+	b := m.find(book.ID, false)
+
+	if b == nil || b.Version != book.Version {
+		return data.ErrEditConflict
+	}
+
+	book.Version++
+	*b = *book
+
+	return nil
+}
+
+func (m *BookModel) Restore(id int64) error {
+	b := m.find(id, true)
+
+	if b == nil {
+		return data.ErrRecordNotFound
+	}
+
+	b.DeletedAt = nil
+
+	return nil
+}
+
+func (m *BookModel) GetTrashed(id int64) (*data.Book, error) {
+	b := m.find(id, true)
+
+	if b == nil {
+		return nil, data.ErrRecordNotFound
+	}
+
+	book := *b
+	return &book, nil
+}
+
+func (m *BookModel) GetTrashedByUser(userID int64) ([]*data.Book, error) {
+	books := []*data.Book{}
+
+	for _, b := range m.Books {
+		if b.UserID == userID && b.DeletedAt != nil {
+			book := *b
+			books = append(books, &book)
+		}
+	}
+
+	return books, nil
+}
+
+func (m *BookModel) Purge(before time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,54 @@
+package mock
+
+import (
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type CartModel struct {
+	// Items holds the book ids in each user's cart, oldest first.
+	Items map[int64][]int64
+	// Books prices the items in the cart.
+	Books *BookModel
+}
+
+func (m *CartModel) Add(userID, bookID int64) error {
+	for _, id := range m.Items[userID] {
+		if id == bookID {
+			return nil
+		}
+	}
+
+	if m.Items == nil {
+		m.Items = make(map[int64][]int64)
+	}
+
+	m.Items[userID] = append(m.Items[userID], bookID)
+
+	return nil
+}
+
+func (m *CartModel) Remove(userID, bookID int64) error {
+	for i, id := range m.Items[userID] {
+		if id == bookID {
+			m.Items[userID] = append(m.Items[userID][:i], m.Items[userID][i+1:]...)
+			return nil
+		}
+	}
+
+	return data.ErrRecordNotFound
+}
+
+func (m *CartModel) Get(userID int64) ([]*data.CartItem, error) {
+	items := []*data.CartItem{}
+
+	for _, id := range m.Items[userID] {
+		book, err := m.Books.Get(id)
+		if err != nil {
+			continue
+		}
+
+		items = append(items, &data.CartItem{BookID: book.ID, Title: book.Title, Price: book.Price, Currency: book.Currency})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,348 @@
+package mock
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+type ChapterModel struct {
+	Chapters []*data.Chapter
+	// Rendered caches rendered HTML by chapter version.
+	Rendered map[RenderedKey]string
+}
+
+type RenderedKey struct {
+	ID      int64
+	Version int
+}
+
+func (m *ChapterModel) find(id int64, trashed bool) *data.Chapter {
+	for _, c := range m.Chapters {
+		if c.ID == id && (c.DeletedAt != nil) == trashed {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// listed returns the book's chapters outside the trash in reading order.
+func (m *ChapterModel) listed(bookID int64, includeDrafts bool) []*data.Chapter {
+	chapters := []*data.Chapter{}
+
+	for _, c := range m.Chapters {
+		if c.BookID == bookID && c.DeletedAt == nil && (c.IsPublished || includeDrafts) {
+			chapters = append(chapters, c)
+		}
+	}
+
+	sort.SliceStable(chapters, func(i, j int) bool {
+		return chapters[i].ChapterNo < chapters[j].ChapterNo
+	})
+
+	return chapters
+}
+
+func (m *ChapterModel) Insert(chapter *data.Chapter) error {
+	chapter.ID = int64(len(m.Chapters) + 1)
+	chapter.ChapterNo = int64(len(m.listed(chapter.BookID, true)) + 1)
+	chapter.CreatedAt = time.Now()
+	chapter.UpdatedAt = chapter.CreatedAt
+	chapter.Version = 1
+
+	c := *chapter
+	m.Chapters = append(m.Chapters, &c)
+
+	return nil
+}
+
+func (m *ChapterModel) GetTOC(bookID int64, includeDrafts bool, filters data.Filters) ([]*data.ChapterSummary, *data.Metadata, error) {
+	chapters := m.listed(bookID, includeDrafts)
+	entries := []*data.ChapterSummary{}
+
+	for _, c := range page(chapters, filters) {
+		entries = append(entries, &data.ChapterSummary{
+			ID:             c.ID,
+			ChapterNo:      c.ChapterNo,
+			Title:          c.Title,
+			Description:    c.Description,
+			WordCount:      c.WordCount,
+			ReadingMinutes: c.ReadingMinutes,
+			Access:         c.Access,
+			IsPublished:    c.IsPublished,
+			PublishedAt:    c.PublishedAt,
+		})
+	}
+
+	return entries, pageMetadata(len(chapters), filters), nil
+}
+
+func (m *ChapterModel) GetByBookId(bookId int64, includeDrafts bool, filters data.Filters) ([]*data.Chapter, *data.Metadata, error) {
+	chapters := m.listed(bookId, includeDrafts)
+	result := []*data.Chapter{}
+
+	for _, c := range page(chapters, filters) {
+		chapter := *c
+		result = append(result, &chapter)
+	}
+
+	return result, pageMetadata(len(chapters), filters), nil
+}
+
+func (m *ChapterModel) Reorder(bookID int64, chapterIDs []int64) error {
+	chapters := m.listed(bookID, true)
+
+	if len(chapters) != len(chapterIDs) {
+		return data.ErrInvalidChapterOrder
+	}
+
+	numbers := make(map[int64]int64, len(chapterIDs))
+
+	for i, id := range chapterIDs {
+		numbers[id] = int64(i + 1)
+	}
+
+	for _, c := range chapters {
+		if numbers[c.ID] == 0 {
+			return data.ErrInvalidChapterOrder
+		}
+	}
+
+	for _, c := range chapters {
+		c.ChapterNo = numbers[c.ID]
+	}
+
+	return nil
+}
+
+// Batch applies the operations to a copy of the chapters and only keeps the
+// copy when every operation succeeds, like the transaction it stands in for.
+func (m *ChapterModel) Batch(bookID, userID int64, ops []data.ChapterOp) ([]*data.ChapterOpResult, error) {
+	work := make([]*data.Chapter, len(m.Chapters))
+
+	for i, c := range m.Chapters {
+		chapter := *c
+		work[i] = &chapter
+	}
+
+	find := func(id int64) *data.Chapter {
+		for _, c := range work {
+			if c.ID == id && c.BookID == bookID && c.DeletedAt == nil {
+				return c
+			}
+		}
+
+		return nil
+	}
+
+	results := make([]*data.ChapterOpResult, len(ops))
+	failed := false
+
+	for i, op := range ops {
+		result := &data.ChapterOpResult{Index: i, Op: op.Op, ID: op.ID}
+		results[i] = result
+
+		v := validator.New()
+
+		switch op.Op {
+		case data.BatchCreate:
+			chapter := &data.Chapter{BookID: bookID, UserID: userID, Access: data.ChapterAccessFree, ContentFormat: data.ContentFormatText}
+			applyChapterOp(chapter, op)
+
+			if data.ValidateChapter(v, chapter); !v.IsValid() {
+				break
+			}
+
+			chapter.ID = int64(len(work) + 1)
+			chapter.Version = 1
+			work = append(work, chapter)
+
+			result.ID, result.Chapter, result.Status = chapter.ID, chapter, data.BatchCreated
+
+		case data.BatchUpdate, data.BatchDelete:
+			chapter := find(op.ID)
+
+			if chapter == nil {
+				v.AddError("id", "must be a chapter of this book")
+				break
+			}
+
+			if op.Version != nil && *op.Version != chapter.Version {
+				v.AddError("version", "does not match the chapter's current version")
+				break
+			}
+
+			if op.Op == data.BatchDelete {
+				now := time.Now()
+				chapter.DeletedAt = &now
+				result.Status = data.BatchDeleted
+				break
+			}
+
+			applyChapterOp(chapter, op)
+
+			if data.ValidateChapter(v, chapter); !v.IsValid() {
+				break
+			}
+
+			chapter.Version++
+			result.Chapter, result.Status = chapter, data.BatchUpdated
+
+		default:
+			v.AddError("op", "must be create, update or delete")
+		}
+
+		if !v.IsValid() {
+			result.Status, result.Errors = data.BatchFailed, v.Errors
+			failed = true
+		}
+	}
+
+	if failed {
+		for _, result := range results {
+			if result.Status == data.BatchFailed {
+				continue
+			}
+
+			result.Status, result.Chapter = data.BatchRolledBack, nil
+
+			if result.Op == data.BatchCreate {
+				result.ID = 0
+			}
+		}
+
+		return results, data.ErrBatchFailed
+	}
+
+	m.Chapters = work
+
+	return results, nil
+}
+
+func applyChapterOp(chapter *data.Chapter, op data.ChapterOp) {
+	if op.Title != nil {
+		chapter.Title = *op.Title
+	}
+
+	if op.Description != nil {
+		chapter.Description = *op.Description
+	}
+
+	if op.Content != nil {
+		chapter.Content = op.Content
+	}
+
+	if op.ContentFormat != nil {
+		chapter.ContentFormat = *op.ContentFormat
+	}
+
+	if op.Access != nil {
+		chapter.Access = *op.Access
+	}
+}
+
+func (m *ChapterModel) Get(id int64) (*data.Chapter, error) {
+	c := m.find(id, false)
+
+	if c == nil {
+		return nil, data.ErrRecordNotFound
+	}
+
+	chapter := *c
+	return &chapter, nil
+}
+
+func (m *ChapterModel) Update(chapter *data.Chapter) error {
+	c := m.find(chapter.ID, false)
+
+	if c == nil || c.Version != chapter.Version {
+		return data.ErrEditConflict
+	}
+
+	chapter.Version++
+	chapter.UpdatedAt = time.Now()
+	*c = *chapter
+
+	return nil
+}
+
+func (m *ChapterModel) SetPublished(chapter *data.Chapter, published bool) error {
+	chapter.IsPublished = published
+	return m.Update(chapter)
+}
+
+func (m *ChapterModel) GetRendered(id int64, version int) (string, error) {
+	rendered, ok := m.Rendered[RenderedKey{id, version}]
+
+	if !ok {
+		return "", data.ErrRecordNotFound
+	}
+
+	return rendered, nil
+}
+
+func (m *ChapterModel) SaveRendered(id int64, version int, rendered string) error {
+	if m.Rendered == nil {
+		m.Rendered = make(map[RenderedKey]string)
+	}
+
+	m.Rendered[RenderedKey{id, version}] = rendered
+
+	return nil
+}
+
+func (m *ChapterModel) Delete(id int64) error {
+	c := m.find(id, false)
+
+	if c == nil {
+		return data.ErrRecordNotFound
+	}
+
+	now := time.Now()
+	c.DeletedAt = &now
+
+	return nil
+}
+
+func (m *ChapterModel) Restore(id int64) error {
+	c := m.find(id, true)
+
+	if c == nil {
+		return data.ErrRecordNotFound
+	}
+
+	c.DeletedAt = nil
+
+	return nil
+}
+
+func (m *ChapterModel) GetTrashed(id int64) (*data.Chapter, error) {
+	c := m.find(id, true)
+
+	if c == nil {
+		return nil, data.ErrRecordNotFound
+	}
+
+	chapter := *c
+	return &chapter, nil
+}
+
+func (m *ChapterModel) GetTrashedByUser(userID int64) ([]*data.Chapter, error) {
+	chapters := []*data.Chapter{}
+
+	for _, c := range m.Chapters {
+		if c.UserID == userID && c.DeletedAt != nil {
+			chapter := *c
+			chapters = append(chapters, &chapter)
+		}
+	}
+
+	return chapters, nil
+}
+
+func (m *ChapterModel) Purge(before time.Time) (int64, error) {
+	return 0, nil
+}
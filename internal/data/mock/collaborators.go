@@ -0,0 +1,105 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type CollaboratorModel struct {
+	Collaborators []*data.Collaborator
+}
+
+func (m *CollaboratorModel) find(bookID, userID int64) *data.Collaborator {
+	for _, c := range m.Collaborators {
+		if c.BookID == bookID && c.UserID == userID {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (m *CollaboratorModel) Invite(collaborator *data.Collaborator) error {
+	c := m.find(collaborator.BookID, collaborator.UserID)
+
+	if c != nil && c.Status != data.InvitationDeclined {
+		return data.ErrDuplicateCollaborator
+	}
+
+	collaborator.Status = data.InvitationPending
+	collaborator.CreatedAt = time.Now()
+
+	if c != nil {
+		*c = *collaborator
+		return nil
+	}
+
+	invited := *collaborator
+	m.Collaborators = append(m.Collaborators, &invited)
+
+	return nil
+}
+
+func (m *CollaboratorModel) GetRole(bookID, userID int64) (string, error) {
+	c := m.find(bookID, userID)
+
+	if c == nil || c.Status != data.InvitationAccepted {
+		return "", data.ErrRecordNotFound
+	}
+
+	return c.Role, nil
+}
+
+func (m *CollaboratorModel) GetByBook(bookID int64) ([]*data.Collaborator, error) {
+	collaborators := []*data.Collaborator{}
+
+	for _, c := range m.Collaborators {
+		if c.BookID == bookID {
+			collaborator := *c
+			collaborators = append(collaborators, &collaborator)
+		}
+	}
+
+	return collaborators, nil
+}
+
+func (m *CollaboratorModel) GetInvitationsByUser(userID int64) ([]*data.Collaborator, error) {
+	invitations := []*data.Collaborator{}
+
+	for _, c := range m.Collaborators {
+		if c.UserID == userID && c.Status == data.InvitationPending {
+			invitation := *c
+			invitations = append(invitations, &invitation)
+		}
+	}
+
+	return invitations, nil
+}
+
+func (m *CollaboratorModel) Respond(bookID, userID int64, accept bool) error {
+	c := m.find(bookID, userID)
+
+	if c == nil || c.Status != data.InvitationPending {
+		return data.ErrRecordNotFound
+	}
+
+	c.Status = data.InvitationDeclined
+
+	if accept {
+		c.Status = data.InvitationAccepted
+	}
+
+	return nil
+}
+
+func (m *CollaboratorModel) Delete(bookID, userID int64) error {
+	for i, c := range m.Collaborators {
+		if c.BookID == bookID && c.UserID == userID {
+			m.Collaborators = append(m.Collaborators[:i], m.Collaborators[i+1:]...)
+			return nil
+		}
+	}
+
+	return data.ErrRecordNotFound
+}
@@ -0,0 +1,130 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type CommentModel struct {
+	Comments []*data.Comment
+}
+
+func (m *CommentModel) find(id int64) *data.Comment {
+	for _, c := range m.Comments {
+		if c.ID == id {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func (m *CommentModel) list(match func(c *data.Comment) bool, filters data.Filters) ([]*data.Comment, *data.Metadata, error) {
+	comments := []*data.Comment{}
+
+	for _, c := range m.Comments {
+		if match(c) {
+			comment := *c
+			comments = append(comments, &comment)
+		}
+	}
+
+	return page(comments, filters), pageMetadata(len(comments), filters), nil
+}
+
+func (m *CommentModel) Insert(comment *data.Comment, limit int, window time.Duration) error {
+	now := time.Now()
+	recent := 0
+
+	for _, c := range m.Comments {
+		if c.UserID == comment.UserID && c.CreatedAt.After(now.Add(-window)) {
+			recent++
+		}
+	}
+
+	if recent >= limit {
+		return data.ErrCommentRateLimited
+	}
+
+	comment.ID = int64(len(m.Comments) + 1)
+	comment.CreatedAt = now
+	comment.Version = 1
+
+	c := *comment
+	m.Comments = append(m.Comments, &c)
+
+	return nil
+}
+
+func (m *CommentModel) Get(id int64) (*data.Comment, error) {
+	c := m.find(id)
+
+	if c == nil {
+		return nil, data.ErrRecordNotFound
+	}
+
+	comment := *c
+	return &comment, nil
+}
+
+func (m *CommentModel) GetByChapter(chapterID int64, filters data.Filters) ([]*data.Comment, *data.Metadata, error) {
+	return m.list(func(c *data.Comment) bool { return c.ChapterID == chapterID && c.ParentID == nil }, filters)
+}
+
+func (m *CommentModel) GetReplies(parentID int64, filters data.Filters) ([]*data.Comment, *data.Metadata, error) {
+	return m.list(func(c *data.Comment) bool { return c.ParentID != nil && *c.ParentID == parentID }, filters)
+}
+
+func (m *CommentModel) GetPinned(chapterID int64) ([]*data.Comment, error) {
+	comments := []*data.Comment{}
+
+	for _, c := range m.Comments {
+		if c.ChapterID == chapterID && c.Pinned {
+			comment := *c
+			comments = append(comments, &comment)
+		}
+	}
+
+	return comments, nil
+}
+
+func (m *CommentModel) Update(comment *data.Comment) error {
+	c := m.find(comment.ID)
+
+	if c == nil || c.Version != comment.Version {
+		return data.ErrEditConflict
+	}
+
+	now := time.Now()
+	comment.EditedAt = &now
+	comment.Version++
+	*c = *comment
+
+	return nil
+}
+
+func (m *CommentModel) SetPinned(id int64, pinned bool) error {
+	c := m.find(id)
+
+	if c == nil {
+		return data.ErrRecordNotFound
+	}
+
+	c.Pinned = pinned
+
+	return nil
+}
+
+func (m *CommentModel) Delete(id, deletedBy int64) error {
+	c := m.find(id)
+
+	if c == nil || c.Deleted {
+		return data.ErrRecordNotFound
+	}
+
+	c.Deleted = true
+	c.Body = ""
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type EntitlementModel struct {
+	Entitlements []*data.Entitlement
+}
+
+func (m *EntitlementModel) Has(userID, bookID int64) (bool, error) {
+	for _, e := range m.Entitlements {
+		if e.UserID == userID && e.BookID == bookID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (m *EntitlementModel) Grant(entitlement *data.Entitlement) error {
+	if has, _ := m.Has(entitlement.UserID, entitlement.BookID); has {
+		return data.ErrDuplicateEntitlement
+	}
+
+	entitlement.Source = data.EntitlementSourceGrant
+	entitlement.CreatedAt = time.Now()
+
+	e := *entitlement
+	m.Entitlements = append(m.Entitlements, &e)
+
+	return nil
+}
+
+func (m *EntitlementModel) Revoke(userID, bookID int64) error {
+	for i, e := range m.Entitlements {
+		if e.UserID == userID && e.BookID == bookID && e.Source == data.EntitlementSourceGrant {
+			m.Entitlements = append(m.Entitlements[:i], m.Entitlements[i+1:]...)
+			return nil
+		}
+	}
+
+	return data.ErrRecordNotFound
+}
+
+func (m *EntitlementModel) GetByBook(bookID int64) ([]*data.Entitlement, error) {
+	entitlements := []*data.Entitlement{}
+
+	for _, e := range m.Entitlements {
+		if e.BookID == bookID {
+			entitlement := *e
+			entitlements = append(entitlements, &entitlement)
+		}
+	}
+
+	return entitlements, nil
+}
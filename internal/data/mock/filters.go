@@ -0,0 +1,27 @@
+package mock
+
+import (
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+// page returns the slice of items on the page the filters ask for.
+func page[T any](items []T, filters data.Filters) []T {
+	start := min((filters.Page-1)*filters.PageSize, len(items))
+	end := min(start+filters.PageSize, len(items))
+
+	return items[start:end]
+}
+
+// pageMetadata mirrors the metadata the data package calculates for page
+// based listings.
+func pageMetadata(totalRecords int, filters data.Filters) *data.Metadata {
+	lastPage := max((totalRecords+filters.PageSize-1)/filters.PageSize, 1)
+
+	return &data.Metadata{
+		CurrentPage:  filters.Page,
+		PageSize:     filters.PageSize,
+		FirstPage:    1,
+		LastPage:     lastPage,
+		TotalRecords: &totalRecords,
+	}
+}
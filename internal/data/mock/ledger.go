@@ -0,0 +1,122 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+// LedgerEntry is an author's movement as the ledger mock stores it.
+type LedgerEntry struct {
+	UserID int64
+	data.EarningsEntry
+}
+
+type LedgerModel struct {
+	Entries    []*LedgerEntry
+	Periods    []*data.PayoutPeriod
+	Statements []*data.PayoutStatement
+}
+
+func (m *LedgerModel) entries(userID int64, from, to time.Time) []*data.EarningsEntry {
+	entries := []*data.EarningsEntry{}
+
+	for _, e := range m.Entries {
+		if e.UserID == userID && !e.CreatedAt.Before(from) && e.CreatedAt.Before(to) {
+			entry := e.EarningsEntry
+			entries = append(entries, &entry)
+		}
+	}
+
+	return entries
+}
+
+// Earnings only totals the entries by book; the period breakdown and
+// balances are left to the SQL implementation.
+func (m *LedgerModel) Earnings(userID int64, from, to time.Time, period string) (*data.Earnings, error) {
+	earnings := &data.Earnings{
+		ByBook:   []*data.BookEarnings{},
+		ByPeriod: []*data.PeriodEarnings{},
+		Unpaid:   []*data.Balance{},
+	}
+
+	byBook := make(map[int64]*data.BookEarnings)
+
+	for _, entry := range m.entries(userID, from, to) {
+		if entry.BookID == nil {
+			continue
+		}
+
+		book, ok := byBook[*entry.BookID]
+
+		if !ok {
+			book = &data.BookEarnings{BookID: entry.BookID, Title: entry.Title, Currency: entry.Currency}
+			byBook[*entry.BookID] = book
+			earnings.ByBook = append(earnings.ByBook, book)
+		}
+
+		switch entry.Kind {
+		case data.LedgerSale:
+			book.Sales++
+			book.Earned += entry.Amount
+		case data.LedgerRefund:
+			book.Refunded -= entry.Amount
+		}
+
+		book.Net += entry.Amount
+	}
+
+	return earnings, nil
+}
+
+func (m *LedgerModel) EarningsEntries(userID int64, from, to time.Time) ([]*data.EarningsEntry, error) {
+	return m.entries(userID, from, to), nil
+}
+
+func (m *LedgerModel) ClosePayoutPeriod(periodEnd time.Time, closedBy int64) (*data.PayoutPeriod, error) {
+	var periodStart *time.Time
+
+	if len(m.Periods) > 0 {
+		last := m.Periods[len(m.Periods)-1].PeriodEnd
+		periodStart = &last
+	}
+
+	if periodEnd.After(time.Now()) || (periodStart != nil && !periodEnd.After(*periodStart)) {
+		return nil, data.ErrInvalidPayoutPeriod
+	}
+
+	period := &data.PayoutPeriod{
+		ID:          int64(len(m.Periods) + 1),
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		ClosedBy:    &closedBy,
+		ClosedAt:    time.Now(),
+		Statements:  []*data.PayoutStatement{},
+	}
+
+	m.Periods = append(m.Periods, period)
+
+	return period, nil
+}
+
+func (m *LedgerModel) GetPayoutPeriod(id int64) (*data.PayoutPeriod, error) {
+	for _, p := range m.Periods {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+
+	return nil, data.ErrRecordNotFound
+}
+
+func (m *LedgerModel) GetStatementsByUser(userID int64) ([]*data.PayoutStatement, error) {
+	statements := []*data.PayoutStatement{}
+
+	for _, s := range m.Statements {
+		if s.UserID == userID {
+			statements = append(statements, s)
+		}
+	}
+
+	return statements, nil
+}
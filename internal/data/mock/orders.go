@@ -0,0 +1,139 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type OrderModel struct {
+	Orders []*data.Order
+	// Cart is turned into orders by CreateFromCart.
+	Cart *CartModel
+}
+
+func (m *OrderModel) find(id int64) *data.Order {
+	for _, o := range m.Orders {
+		if o.ID == id {
+			return o
+		}
+	}
+
+	return nil
+}
+
+func (m *OrderModel) CreateFromCart(userID int64, couponCode string, hold time.Duration) (*data.Order, error) {
+	items, _ := m.Cart.Get(userID)
+
+	if len(items) == 0 {
+		return nil, data.ErrEmptyCart
+	}
+
+	order := &data.Order{
+		ID:        int64(len(m.Orders) + 1),
+		UserID:    userID,
+		Status:    data.OrderPending,
+		Currency:  items[0].Currency,
+		Items:     []*data.OrderItem{},
+		CreatedAt: time.Now(),
+		Version:   1,
+	}
+
+	for _, item := range items {
+		bookID := item.BookID
+		order.Total += item.Price
+		order.Items = append(order.Items, &data.OrderItem{BookID: &bookID, Title: item.Title, Price: item.Price})
+	}
+
+	delete(m.Cart.Items, userID)
+
+	o := *order
+	m.Orders = append(m.Orders, &o)
+
+	return order, nil
+}
+
+func (m *OrderModel) ExpirePending(before time.Time) ([]*data.Order, error) {
+	expired := []*data.Order{}
+
+	for _, o := range m.Orders {
+		if o.Status == data.OrderPending && o.CreatedAt.Before(before) {
+			o.Status = data.OrderCancelled
+			o.Version++
+
+			order := *o
+			expired = append(expired, &order)
+		}
+	}
+
+	return expired, nil
+}
+
+func (m *OrderModel) SetPayment(order *data.Order) error {
+	o := m.find(order.ID)
+
+	if o == nil || o.Version != order.Version {
+		return data.ErrEditConflict
+	}
+
+	order.Version++
+	o.PaymentProvider = order.PaymentProvider
+	o.PaymentRef = order.PaymentRef
+	o.Version = order.Version
+
+	return nil
+}
+
+func (m *OrderModel) Get(id int64) (*data.Order, error) {
+	o := m.find(id)
+
+	if o == nil {
+		return nil, data.ErrRecordNotFound
+	}
+
+	order := *o
+	return &order, nil
+}
+
+func (m *OrderModel) GetByPaymentRef(ref string) (*data.Order, error) {
+	for _, o := range m.Orders {
+		if o.PaymentRef == ref {
+			order := *o
+			return &order, nil
+		}
+	}
+
+	return nil, data.ErrRecordNotFound
+}
+
+func (m *OrderModel) GetAllByUser(userID int64, filters data.Filters) ([]*data.Order, *data.Metadata, error) {
+	orders := []*data.Order{}
+
+	for _, o := range m.Orders {
+		if o.UserID == userID {
+			order := *o
+			orders = append(orders, &order)
+		}
+	}
+
+	return page(orders, filters), pageMetadata(len(orders), filters), nil
+}
+
+func (m *OrderModel) Transition(order *data.Order, to string) error {
+	if !data.CanTransition(order.Status, to) {
+		return data.ErrInvalidTransition
+	}
+
+	o := m.find(order.ID)
+
+	if o == nil || o.Version != order.Version || o.Status != order.Status {
+		return data.ErrEditConflict
+	}
+
+	order.Status = to
+	order.Version++
+	o.Status = to
+	o.Version = order.Version
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package mock
+
+import (
+	"sort"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type progressKey struct {
+	userID, bookID int64
+}
+
+type ProgressModel struct {
+	Progress map[progressKey]*data.ReadingProgress
+}
+
+func (m *ProgressModel) Save(userID int64, progress *data.ReadingProgress) (bool, error) {
+	if m.Progress == nil {
+		m.Progress = make(map[progressKey]*data.ReadingProgress)
+	}
+
+	key := progressKey{userID, progress.BookID}
+
+	if stored, ok := m.Progress[key]; ok && !stored.UpdatedAt.Before(progress.UpdatedAt) {
+		*progress = *stored
+		return false, nil
+	}
+
+	p := *progress
+	m.Progress[key] = &p
+
+	return true, nil
+}
+
+func (m *ProgressModel) Get(userID, bookID int64) (*data.ReadingProgress, error) {
+	stored, ok := m.Progress[progressKey{userID, bookID}]
+
+	if !ok {
+		return nil, data.ErrRecordNotFound
+	}
+
+	progress := *stored
+	return &progress, nil
+}
+
+// ContinueReading lists every book the user has progress in, most recently
+// read first. Finished books are only filtered out by the SQL implementation.
+func (m *ProgressModel) ContinueReading(userID int64, filters data.Filters) ([]*data.ContinueReading, *data.Metadata, error) {
+	items := []*data.ContinueReading{}
+
+	for key, p := range m.Progress {
+		if key.userID != userID {
+			continue
+		}
+
+		items = append(items, &data.ContinueReading{
+			BookID:    p.BookID,
+			Chapter:   data.ChapterRef{ID: p.ChapterID},
+			Position:  p.Position,
+			Percent:   p.Percent,
+			UpdatedAt: p.UpdatedAt,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].UpdatedAt.After(items[j].UpdatedAt)
+	})
+
+	return page(items, filters), pageMetadata(len(items), filters), nil
+}
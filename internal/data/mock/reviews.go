@@ -0,0 +1,89 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type ReviewModel struct {
+	Reviews []*data.Review
+}
+
+func (m *ReviewModel) find(id int64) (int, *data.Review) {
+	for i, r := range m.Reviews {
+		if r.ID == id {
+			return i, r
+		}
+	}
+
+	return -1, nil
+}
+
+func (m *ReviewModel) Insert(review *data.Review) error {
+	for _, r := range m.Reviews {
+		if r.BookID == review.BookID && r.UserID == review.UserID {
+			return data.ErrDuplicateReview
+		}
+	}
+
+	review.ID = int64(len(m.Reviews) + 1)
+	review.CreatedAt = time.Now()
+	review.UpdatedAt = review.CreatedAt
+	review.Version = 1
+
+	r := *review
+	m.Reviews = append(m.Reviews, &r)
+
+	return nil
+}
+
+func (m *ReviewModel) Get(id int64) (*data.Review, error) {
+	_, r := m.find(id)
+
+	if r == nil {
+		return nil, data.ErrRecordNotFound
+	}
+
+	review := *r
+	return &review, nil
+}
+
+func (m *ReviewModel) GetByBook(bookID int64, filters data.Filters) ([]*data.Review, *data.Metadata, error) {
+	reviews := []*data.Review{}
+
+	for _, r := range m.Reviews {
+		if r.BookID == bookID {
+			review := *r
+			reviews = append(reviews, &review)
+		}
+	}
+
+	return page(reviews, filters), pageMetadata(len(reviews), filters), nil
+}
+
+func (m *ReviewModel) Update(review *data.Review) error {
+	_, r := m.find(review.ID)
+
+	if r == nil || r.Version != review.Version {
+		return data.ErrEditConflict
+	}
+
+	review.Version++
+	review.UpdatedAt = time.Now()
+	*r = *review
+
+	return nil
+}
+
+func (m *ReviewModel) Delete(review *data.Review) error {
+	i, r := m.find(review.ID)
+
+	if r == nil {
+		return data.ErrRecordNotFound
+	}
+
+	m.Reviews = append(m.Reviews[:i], m.Reviews[i+1:]...)
+
+	return nil
+}
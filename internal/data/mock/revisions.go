@@ -0,0 +1,60 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type ChapterRevisionModel struct {
+	Revisions []*data.ChapterRevision
+}
+
+func (m *ChapterRevisionModel) GetAll(chapterID int64, filters data.Filters) ([]*data.ChapterRevision, *data.Metadata, error) {
+	revisions := []*data.ChapterRevision{}
+
+	for _, r := range m.Revisions {
+		if r.ChapterID == chapterID {
+			revision := *r
+			revision.Content = nil
+			revisions = append(revisions, &revision)
+		}
+	}
+
+	return page(revisions, filters), pageMetadata(len(revisions), filters), nil
+}
+
+func (m *ChapterRevisionModel) Get(chapterID int64, revision int) (*data.ChapterRevision, error) {
+	for _, r := range m.Revisions {
+		if r.ChapterID == chapterID && r.Revision == revision {
+			rev := *r
+			return &rev, nil
+		}
+	}
+
+	return nil, data.ErrRecordNotFound
+}
+
+// GetAt returns the newest revision at or before version, as the database
+// model does.
+func (m *ChapterRevisionModel) GetAt(chapterID int64, version int) (*data.ChapterRevision, error) {
+	var found *data.ChapterRevision
+
+	for _, r := range m.Revisions {
+		if r.ChapterID == chapterID && r.Revision <= version && (found == nil || r.Revision > found.Revision) {
+			found = r
+		}
+	}
+
+	if found == nil {
+		return nil, data.ErrRecordNotFound
+	}
+
+	rev := *found
+
+	return &rev, nil
+}
+
+func (m *ChapterRevisionModel) Prune(keep int, before time.Time) (int64, error) {
+	return 0, nil
+}
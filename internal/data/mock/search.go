@@ -0,0 +1,24 @@
+package mock
+
+import (
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type SearchModel struct {
+	Results     []*data.SearchResult
+	Suggestions []*data.Suggestion
+	// Query is the last query the model was asked for.
+	Query string
+}
+
+func (m *SearchModel) Search(query string, filters data.Filters) ([]*data.SearchResult, *data.Metadata, error) {
+	m.Query = query
+
+	return page(m.Results, filters), pageMetadata(len(m.Results), filters), nil
+}
+
+func (m *SearchModel) Suggest(query string, limit int) ([]*data.Suggestion, error) {
+	m.Query = query
+
+	return m.Suggestions[:min(limit, len(m.Suggestions))], nil
+}
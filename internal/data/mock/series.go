@@ -0,0 +1,81 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type SeriesModel struct {
+	Series []*data.Series
+	// Volumes holds the ordered book ids of each series.
+	Volumes map[int64][]int64
+}
+
+func (m *SeriesModel) Insert(series *data.Series) error {
+	series.ID = int64(len(m.Series) + 1)
+	series.CreatedAt = time.Now()
+	series.Version = 1
+
+	s := *series
+	m.Series = append(m.Series, &s)
+
+	return nil
+}
+
+func (m *SeriesModel) Get(id int64, includeUnpublished bool) (*data.Series, error) {
+	for _, s := range m.Series {
+		if s.ID == id {
+			series := *s
+			series.Books = []*data.Book{}
+
+			for _, bookID := range m.Volumes[id] {
+				series.Books = append(series.Books, &data.Book{ID: bookID})
+			}
+
+			return &series, nil
+		}
+	}
+
+	return nil, data.ErrRecordNotFound
+}
+
+func (m *SeriesModel) getByBook(bookID int64) (*data.BookSeries, error) {
+	for seriesID, bookIDs := range m.Volumes {
+		for i, id := range bookIDs {
+			if id == bookID {
+				return &data.BookSeries{ID: seriesID, Position: i + 1}, nil
+			}
+		}
+	}
+
+	return nil, data.ErrRecordNotFound
+}
+
+func (m *SeriesModel) GetByBooks(bookIDs []int64) (map[int64]*data.BookSeries, error) {
+	series := make(map[int64]*data.BookSeries)
+
+	for _, bookID := range bookIDs {
+		if s, err := m.getByBook(bookID); err == nil {
+			series[bookID] = s
+		}
+	}
+
+	return series, nil
+}
+
+func (m *SeriesModel) SetBooks(seriesID int64, bookIDs []int64) error {
+	for _, bookID := range bookIDs {
+		if s, err := m.getByBook(bookID); err == nil && s.ID != seriesID {
+			return data.ErrBookInOtherSeries
+		}
+	}
+
+	if m.Volumes == nil {
+		m.Volumes = make(map[int64][]int64)
+	}
+
+	m.Volumes[seriesID] = bookIDs
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type ShelfModel struct {
+	Shelves []*data.Shelf
+	// Books holds the book ids on each shelf.
+	Books map[int64][]int64
+}
+
+func (m *ShelfModel) find(id int64) (int, *data.Shelf) {
+	for i, s := range m.Shelves {
+		if s.ID == id {
+			return i, s
+		}
+	}
+
+	return -1, nil
+}
+
+func (m *ShelfModel) nameTaken(shelf *data.Shelf) bool {
+	for _, s := range m.Shelves {
+		if s.UserID == shelf.UserID && s.Name == shelf.Name && s.ID != shelf.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *ShelfModel) EnsureDefaults(userID int64) error {
+	return nil
+}
+
+func (m *ShelfModel) Insert(shelf *data.Shelf) error {
+	if m.nameTaken(shelf) {
+		return data.ErrDuplicateShelfName
+	}
+
+	shelf.ID = int64(len(m.Shelves) + 1)
+	shelf.CreatedAt = time.Now()
+	shelf.Version = 1
+
+	s := *shelf
+	m.Shelves = append(m.Shelves, &s)
+
+	return nil
+}
+
+func (m *ShelfModel) Get(id int64) (*data.Shelf, error) {
+	_, s := m.find(id)
+
+	if s == nil {
+		return nil, data.ErrRecordNotFound
+	}
+
+	shelf := *s
+	shelf.BookCount = len(m.Books[id])
+
+	return &shelf, nil
+}
+
+func (m *ShelfModel) GetAllByUser(userID int64, publicOnly bool) ([]*data.Shelf, error) {
+	shelves := []*data.Shelf{}
+
+	for _, s := range m.Shelves {
+		if s.UserID == userID && (s.IsPublic || !publicOnly) {
+			shelf, _ := m.Get(s.ID)
+			shelves = append(shelves, shelf)
+		}
+	}
+
+	return shelves, nil
+}
+
+func (m *ShelfModel) Update(shelf *data.Shelf) error {
+	_, s := m.find(shelf.ID)
+
+	if s == nil || s.Version != shelf.Version {
+		return data.ErrEditConflict
+	}
+
+	if m.nameTaken(shelf) {
+		return data.ErrDuplicateShelfName
+	}
+
+	shelf.Version++
+	*s = *shelf
+
+	return nil
+}
+
+func (m *ShelfModel) Delete(id int64) error {
+	i, s := m.find(id)
+
+	if s == nil {
+		return data.ErrRecordNotFound
+	}
+
+	m.Shelves = append(m.Shelves[:i], m.Shelves[i+1:]...)
+	delete(m.Books, id)
+
+	return nil
+}
+
+func (m *ShelfModel) GetBooks(shelfID int64, filters data.Filters) ([]*data.ShelfBook, *data.Metadata, error) {
+	books := []*data.ShelfBook{}
+
+	for _, bookID := range m.Books[shelfID] {
+		books = append(books, &data.ShelfBook{Book: &data.Book{ID: bookID}})
+	}
+
+	return page(books, filters), pageMetadata(len(books), filters), nil
+}
+
+func (m *ShelfModel) AddBook(shelf *data.Shelf, bookID int64) error {
+	for _, id := range m.Books[shelf.ID] {
+		if id == bookID {
+			return data.ErrDuplicateShelfBook
+		}
+	}
+
+	if m.Books == nil {
+		m.Books = make(map[int64][]int64)
+	}
+
+	m.Books[shelf.ID] = append(m.Books[shelf.ID], bookID)
+
+	return nil
+}
+
+func (m *ShelfModel) RemoveBook(shelfID, bookID int64) error {
+	for i, id := range m.Books[shelfID] {
+		if id == bookID {
+			m.Books[shelfID] = append(m.Books[shelfID][:i], m.Books[shelfID][i+1:]...)
+			return nil
+		}
+	}
+
+	return data.ErrRecordNotFound
+}
+
+func (m *ShelfModel) MoveBook(from, to *data.Shelf, bookID int64) error {
+	for _, id := range m.Books[to.ID] {
+		if id == bookID {
+			return data.ErrDuplicateShelfBook
+		}
+	}
+
+	err := m.RemoveBook(from.ID, bookID)
+	if err != nil {
+		return err
+	}
+
+	return m.AddBook(to, bookID)
+}
@@ -0,0 +1,155 @@
+package mock
+
+import (
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/data"
+)
+
+type PlanModel struct {
+	Plans []*data.Plan
+}
+
+func (m *PlanModel) Insert(plan *data.Plan) error {
+	plan.ID = int64(len(m.Plans) + 1)
+	plan.IsActive = true
+	plan.CreatedAt = time.Now()
+
+	p := *plan
+	m.Plans = append(m.Plans, &p)
+
+	return nil
+}
+
+func (m *PlanModel) Get(id int64) (*data.Plan, error) {
+	for _, p := range m.Plans {
+		if p.ID == id {
+			plan := *p
+			return &plan, nil
+		}
+	}
+
+	return nil, data.ErrRecordNotFound
+}
+
+func (m *PlanModel) GetAllActive() ([]*data.Plan, error) {
+	plans := []*data.Plan{}
+
+	for _, p := range m.Plans {
+		if p.IsActive {
+			plan := *p
+			plans = append(plans, &plan)
+		}
+	}
+
+	return plans, nil
+}
+
+type SubscriptionModel struct {
+	Subscriptions []*data.Subscription
+	// Authors holds the ids of authors taking part in subscriptions.
+	Authors map[int64]bool
+}
+
+// isOpen mirrors the subscriptions_user_id_open_idx unique index.
+func isOpen(s *data.Subscription) bool {
+	switch s.Status {
+	case data.SubscriptionIncomplete, data.SubscriptionActive, data.SubscriptionPastDue:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *SubscriptionModel) Insert(subscription *data.Subscription) error {
+	now := time.Now()
+
+	for _, s := range m.Subscriptions {
+		if s.UserID != subscription.UserID || !isOpen(s) {
+			continue
+		}
+
+		if s.Status != data.SubscriptionIncomplete && !s.IsLive(now) {
+			s.Status = data.SubscriptionExpired
+			s.Version++
+			continue
+		}
+
+		return data.ErrActiveSubscription
+	}
+
+	subscription.ID = int64(len(m.Subscriptions) + 1)
+	subscription.Status = data.SubscriptionIncomplete
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+	subscription.Version = 1
+
+	s := *subscription
+	m.Subscriptions = append(m.Subscriptions, &s)
+
+	return nil
+}
+
+func (m *SubscriptionModel) Update(subscription *data.Subscription) error {
+	for _, s := range m.Subscriptions {
+		if s.ID != subscription.ID {
+			continue
+		}
+
+		if s.Version != subscription.Version {
+			return data.ErrEditConflict
+		}
+
+		subscription.UpdatedAt = time.Now()
+		subscription.Version++
+		*s = *subscription
+
+		return nil
+	}
+
+	return data.ErrEditConflict
+}
+
+func (m *SubscriptionModel) GetCurrentByUser(userID int64) (*data.Subscription, error) {
+	now := time.Now()
+
+	for i := len(m.Subscriptions) - 1; i >= 0; i-- {
+		s := m.Subscriptions[i]
+
+		if s.UserID == userID && (isOpen(s) || s.IsLive(now)) {
+			subscription := *s
+			return &subscription, nil
+		}
+	}
+
+	return nil, data.ErrRecordNotFound
+}
+
+func (m *SubscriptionModel) GetByPaymentRef(ref string) (*data.Subscription, error) {
+	for _, s := range m.Subscriptions {
+		if s.PaymentRef == ref {
+			subscription := *s
+			return &subscription, nil
+		}
+	}
+
+	return nil, data.ErrRecordNotFound
+}
+
+func (m *SubscriptionModel) SetAuthorParticipation(userID int64, participating bool) error {
+	if m.Authors == nil {
+		m.Authors = make(map[int64]bool)
+	}
+
+	if participating {
+		m.Authors[userID] = true
+	} else {
+		delete(m.Authors, userID)
+	}
+
+	return nil
+}
+
+func (m *SubscriptionModel) IsAuthorParticipating(userID int64) (bool, error) {
+	return m.Authors[userID], nil
+}
@@ -17,6 +17,36 @@ var MockUser = &data.User{
 	AuthProvider: data.CredentialAuthProvider,
 }
 
+// MockAuthor, MockReader and MockAdmin are activated users the handler tests
+// sign in as.
+var MockAuthor = &data.User{
+	ID:           2,
+	Name:         "Bob",
+	Email:        "bob@example.com",
+	CreatedAt:    time.Date(2025, time.April, 3, 2, 2, 2, 2, time.UTC),
+	Activated:    true,
+	AuthProvider: data.CredentialAuthProvider,
+}
+
+var MockReader = &data.User{
+	ID:           3,
+	Name:         "Carol",
+	Email:        "carol@example.com",
+	CreatedAt:    time.Date(2025, time.April, 3, 2, 2, 2, 2, time.UTC),
+	Activated:    true,
+	AuthProvider: data.CredentialAuthProvider,
+}
+
+var MockAdmin = &data.User{
+	ID:           4,
+	Name:         "Dave",
+	Email:        "dave@example.com",
+	CreatedAt:    time.Date(2025, time.April, 3, 2, 2, 2, 2, time.UTC),
+	Activated:    true,
+	IsAdmin:      true,
+	AuthProvider: data.CredentialAuthProvider,
+}
+
 type UserModel struct {
 }
 
@@ -54,10 +84,12 @@ func (m *UserModel) GetByEmail(email string) (*data.User, error) {
 }
 func (m *UserModel) GetByID(id int64) (*data.User, error) {
 
-	if id != 1 {
-		return nil, data.ErrRecordNotFound
+	for _, user := range []*data.User{MockUser, MockAuthor, MockReader, MockAdmin} {
+		if user.ID == id {
+			return user, nil
+		}
 	}
 
-	return MockUser, nil
+	return nil, data.ErrRecordNotFound
 
 }
@@ -32,28 +32,211 @@ type IBookModel interface {
 	GetAllByUser(title string, filters Filters, userID int64) ([]*Book, *Metadata, error)
 	Insert(book *Book) error
 	Update(book *Book) error
+	Restore(id int64) error
+	GetTrashed(id int64) (*Book, error)
+	GetTrashedByUser(userID int64) ([]*Book, error)
+	Purge(before time.Time) (int64, error)
 }
 
 type IChapterModel interface {
 	Insert(chapter *Chapter) error
-	GetByBookId(bookId int64) ([]*Chapter, error)
+	GetTOC(bookID int64, includeDrafts bool, filters Filters) ([]*ChapterSummary, *Metadata, error)
+	GetByBookId(bookId int64, includeDrafts bool, filters Filters) ([]*Chapter, *Metadata, error)
+	Reorder(bookID int64, chapterIDs []int64) error
+	Batch(bookID, userID int64, ops []ChapterOp) ([]*ChapterOpResult, error)
 	Get(id int64) (*Chapter, error)
 	Update(chapter *Chapter) error
+	SetPublished(chapter *Chapter, published bool) error
+	GetRendered(id int64, version int) (string, error)
+	SaveRendered(id int64, version int, rendered string) error
 	Delete(id int64) error
+	Restore(id int64) error
+	GetTrashed(id int64) (*Chapter, error)
+	GetTrashedByUser(userID int64) ([]*Chapter, error)
+	Purge(before time.Time) (int64, error)
+}
+
+type ISearchModel interface {
+	Search(query string, filters Filters) ([]*SearchResult, *Metadata, error)
+	Suggest(query string, limit int) ([]*Suggestion, error)
+}
+
+type ICollaboratorModel interface {
+	Invite(collaborator *Collaborator) error
+	GetRole(bookID, userID int64) (string, error)
+	GetByBook(bookID int64) ([]*Collaborator, error)
+	GetInvitationsByUser(userID int64) ([]*Collaborator, error)
+	Respond(bookID, userID int64, accept bool) error
+	Delete(bookID, userID int64) error
+}
+
+type ISeriesModel interface {
+	Insert(series *Series) error
+	Get(id int64, includeUnpublished bool) (*Series, error)
+	GetByBooks(bookIDs []int64) (map[int64]*BookSeries, error)
+	SetBooks(seriesID int64, bookIDs []int64) error
+}
+
+type ICartModel interface {
+	Add(userID, bookID int64) error
+	Remove(userID, bookID int64) error
+	Get(userID int64) ([]*CartItem, error)
+}
+
+type IOrderModel interface {
+	CreateFromCart(userID int64, couponCode string, hold time.Duration) (*Order, error)
+	ExpirePending(before time.Time) ([]*Order, error)
+	SetPayment(order *Order) error
+	Get(id int64) (*Order, error)
+	GetByPaymentRef(ref string) (*Order, error)
+	GetAllByUser(userID int64, filters Filters) ([]*Order, *Metadata, error)
+	Transition(order *Order, to string) error
+}
+
+type IEntitlementModel interface {
+	Has(userID, bookID int64) (bool, error)
+	Grant(entitlement *Entitlement) error
+	Revoke(userID, bookID int64) error
+	GetByBook(bookID int64) ([]*Entitlement, error)
+}
+
+type IPlanModel interface {
+	Insert(plan *Plan) error
+	Get(id int64) (*Plan, error)
+	GetAllActive() ([]*Plan, error)
+}
+
+type ISubscriptionModel interface {
+	Insert(subscription *Subscription) error
+	Update(subscription *Subscription) error
+	GetCurrentByUser(userID int64) (*Subscription, error)
+	GetByPaymentRef(ref string) (*Subscription, error)
+	SetAuthorParticipation(userID int64, participating bool) error
+	IsAuthorParticipating(userID int64) (bool, error)
+}
+
+type ICouponModel interface {
+	Insert(coupon *Coupon) error
+	Get(id int64) (*Coupon, error)
+	GetByCreator(userID int64) ([]*Coupon, error)
+	Delete(id int64) error
+}
+
+type ILedgerModel interface {
+	Earnings(userID int64, from, to time.Time, period string) (*Earnings, error)
+	EarningsEntries(userID int64, from, to time.Time) ([]*EarningsEntry, error)
+	ClosePayoutPeriod(periodEnd time.Time, closedBy int64) (*PayoutPeriod, error)
+	GetPayoutPeriod(id int64) (*PayoutPeriod, error)
+	GetStatementsByUser(userID int64) ([]*PayoutStatement, error)
+}
+
+type IReviewModel interface {
+	Insert(review *Review) error
+	Get(id int64) (*Review, error)
+	GetByBook(bookID int64, filters Filters) ([]*Review, *Metadata, error)
+	Update(review *Review) error
+	Delete(review *Review) error
+}
+
+type ICommentModel interface {
+	Insert(comment *Comment, limit int, window time.Duration) error
+	Get(id int64) (*Comment, error)
+	GetByChapter(chapterID int64, filters Filters) ([]*Comment, *Metadata, error)
+	GetReplies(parentID int64, filters Filters) ([]*Comment, *Metadata, error)
+	GetPinned(chapterID int64) ([]*Comment, error)
+	Update(comment *Comment) error
+	SetPinned(id int64, pinned bool) error
+	Delete(id, deletedBy int64) error
+}
+
+type IShelfModel interface {
+	EnsureDefaults(userID int64) error
+	Insert(shelf *Shelf) error
+	Get(id int64) (*Shelf, error)
+	GetAllByUser(userID int64, publicOnly bool) ([]*Shelf, error)
+	Update(shelf *Shelf) error
+	Delete(id int64) error
+	GetBooks(shelfID int64, filters Filters) ([]*ShelfBook, *Metadata, error)
+	AddBook(shelf *Shelf, bookID int64) error
+	RemoveBook(shelfID, bookID int64) error
+	MoveBook(from, to *Shelf, bookID int64) error
+}
+
+type IProgressModel interface {
+	Save(userID int64, progress *ReadingProgress) (bool, error)
+	Get(userID, bookID int64) (*ReadingProgress, error)
+	ContinueReading(userID int64, filters Filters) ([]*ContinueReading, *Metadata, error)
+}
+
+type IAnnotationModel interface {
+	Insert(annotation *Annotation) error
+	Get(id int64) (*Annotation, error)
+	GetByChapter(userID, chapterID int64) ([]*Annotation, error)
+	GetByBook(userID, bookID int64) ([]*Annotation, error)
+	Update(annotation *Annotation) error
+	Delete(id int64) error
+}
+
+type IChapterRevisionModel interface {
+	GetAll(chapterID int64, filters Filters) ([]*ChapterRevision, *Metadata, error)
+	Get(chapterID int64, revision int) (*ChapterRevision, error)
+	GetAt(chapterID int64, version int) (*ChapterRevision, error)
+	Prune(keep int, before time.Time) (int64, error)
+}
+
+type IAutosaveModel interface {
+	Save(userID int64, autosave *ChapterAutosave) error
+	Get(userID, chapterID int64) (*ChapterAutosave, error)
+	Delete(userID, chapterID int64) error
+	Purge(before time.Time) (int64, error)
 }
 
 type Models struct {
-	Users    IUserModel
-	Tokens   ITokenModel
-	Books    IBookModel
-	Chapters IChapterModel
+	Users         IUserModel
+	Tokens        ITokenModel
+	Books         IBookModel
+	Chapters      IChapterModel
+	Search        ISearchModel
+	Collaborators ICollaboratorModel
+	Series        ISeriesModel
+	Cart          ICartModel
+	Orders        IOrderModel
+	Entitlements  IEntitlementModel
+	Plans         IPlanModel
+	Subscriptions ISubscriptionModel
+	Coupons       ICouponModel
+	Ledger        ILedgerModel
+	Reviews       IReviewModel
+	Comments      ICommentModel
+	Shelves       IShelfModel
+	Progress      IProgressModel
+	Annotations   IAnnotationModel
+	Revisions     IChapterRevisionModel
+	Autosaves     IAutosaveModel
 }
 
 func NewModels(db *sql.DB) Models {
 	return Models{
-		Users:    UserModel{DB: db},
-		Tokens:   TokenModel{DB: db},
-		Books:    BookModel{DB: db},
-		Chapters: ChapterModel{DB: db},
+		Users:         UserModel{DB: db},
+		Tokens:        TokenModel{DB: db},
+		Books:         BookModel{DB: db},
+		Chapters:      ChapterModel{DB: db},
+		Search:        SearchModel{DB: db},
+		Collaborators: CollaboratorModel{DB: db},
+		Series:        SeriesModel{DB: db},
+		Cart:          CartModel{DB: db},
+		Orders:        OrderModel{DB: db},
+		Entitlements:  EntitlementModel{DB: db},
+		Plans:         PlanModel{DB: db},
+		Subscriptions: SubscriptionModel{DB: db},
+		Coupons:       CouponModel{DB: db},
+		Ledger:        LedgerModel{DB: db},
+		Reviews:       ReviewModel{DB: db},
+		Comments:      CommentModel{DB: db},
+		Shelves:       ShelfModel{DB: db},
+		Progress:      ProgressModel{DB: db},
+		Annotations:   AnnotationModel{DB: db},
+		Revisions:     ChapterRevisionModel{DB: db},
+		Autosaves:     AutosaveModel{DB: db},
 	}
 }
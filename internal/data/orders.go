@@ -0,0 +1,461 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderRefunded  = "refunded"
+	OrderCancelled = "cancelled"
+)
+
+var (
+	ErrEmptyCart         = errors.New("cart is empty")
+	ErrMixedCurrencies   = errors.New("cart contains books priced in different currencies")
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
+
+// orderTransitions is the order state machine: the statuses an order in a
+// given status may move to.
+var orderTransitions = map[string][]string{
+	OrderPending: {OrderPaid, OrderCancelled},
+	OrderPaid:    {OrderRefunded},
+}
+
+func CanTransition(from, to string) bool {
+	for _, status := range orderTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+type Order struct {
+	ID              int64        `json:"id"`
+	UserID          int64        `json:"userId"`
+	Status          string       `json:"status"`
+	Total           int64        `json:"total"`
+	Discount        int64        `json:"discount"`
+	Currency        string       `json:"currency"`
+	CouponID        *int64       `json:"couponId,omitempty"`
+	PaymentProvider string       `json:"paymentProvider,omitempty"`
+	PaymentRef      string       `json:"-"`
+	Items           []*OrderItem `json:"items,omitempty"`
+	CreatedAt       time.Time    `json:"createdAt"`
+	UpdatedAt       time.Time    `json:"updatedAt"`
+	Version         int          `json:"-"`
+}
+
+type OrderItem struct {
+	BookID   *int64 `json:"bookId"`
+	Title    string `json:"title"`
+	Price    int64  `json:"price"`
+	Discount int64  `json:"discount"`
+}
+
+type OrderModel struct {
+	DB *sql.DB
+}
+
+// CreateFromCart turns the user's cart into a pending order and empties the
+// cart, all in one transaction. Books the user already owns or wrote are
+// skipped. A non-empty couponCode is validated and applied to the order;
+// pending orders only hold a coupon use for the hold period.
+func (m OrderModel) CreateFromCart(userID int64, couponCode string, hold time.Duration) (*Order, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT b.id, b.title, b.price, b.currency
+		FROM cart_items ci
+		INNER JOIN books b ON b.id = ci.book_id
+		WHERE ci.user_id = $1 AND b.deleted_at IS NULL AND b.is_published = true AND b.user_id <> $1
+		AND NOT EXISTS (SELECT 1 FROM entitlements e WHERE e.user_id = $1 AND e.book_id = b.id)
+		ORDER BY ci.added_at ASC
+	`, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	order := &Order{UserID: userID, Status: OrderPending, Items: []*OrderItem{}}
+
+	for rows.Next() {
+		var item OrderItem
+		var bookID int64
+		var currency string
+
+		if err := rows.Scan(&bookID, &item.Title, &item.Price, &currency); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		if order.Currency != "" && order.Currency != currency {
+			rows.Close()
+			return nil, ErrMixedCurrencies
+		}
+
+		item.BookID = &bookID
+		order.Currency = currency
+		order.Total += item.Price
+		order.Items = append(order.Items, &item)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(order.Items) == 0 {
+		return nil, ErrEmptyCart
+	}
+
+	if couponCode != "" {
+		coupon, err := redeemCoupon(ctx, tx, couponCode, userID, time.Now().Add(-hold))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err = ApplyCoupon(order, coupon); err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO orders (user_id, status, total, discount, currency, coupon_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at, version
+	`, order.UserID, order.Status, order.Total, order.Discount, order.Currency, order.CouponID).Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt, &order.Version)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range order.Items {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO order_items (order_id, book_id, title, price, discount)
+			VALUES ($1, $2, $3, $4, $5)
+		`, order.ID, item.BookID, item.Title, item.Price, item.Discount)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// SetPayment records the provider's reference for the order's charge.
+func (m OrderModel) SetPayment(order *Order) error {
+	query := `
+		UPDATE orders
+		SET payment_provider = $1, payment_ref = $2, updated_at = NOW(), version = version + 1
+		WHERE id = $3 AND version = $4
+		RETURNING updated_at, version
+	`
+
+	args := []any{order.PaymentProvider, order.PaymentRef, order.ID, order.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&order.UpdatedAt, &order.Version)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m OrderModel) Get(id int64) (*Order, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	return m.getBy(`id = $1`, id)
+}
+
+func (m OrderModel) GetByPaymentRef(ref string) (*Order, error) {
+	return m.getBy(`payment_ref = $1`, ref)
+}
+
+func (m OrderModel) getBy(where string, arg any) (*Order, error) {
+	query := `
+		SELECT id, user_id, status, total, discount, currency, coupon_id, coalesce(payment_provider, ''), coalesce(payment_ref, ''), created_at, updated_at, version
+		FROM orders
+		WHERE ` + where
+
+	var order Order
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, arg).Scan(
+		&order.ID,
+		&order.UserID,
+		&order.Status,
+		&order.Total,
+		&order.Discount,
+		&order.Currency,
+		&order.CouponID,
+		&order.PaymentProvider,
+		&order.PaymentRef,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+		&order.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	rows, err := m.DB.QueryContext(ctx, `
+		SELECT book_id, title, price, discount
+		FROM order_items
+		WHERE order_id = $1
+		ORDER BY id ASC
+	`, order.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	order.Items = []*OrderItem{}
+
+	for rows.Next() {
+		var item OrderItem
+
+		if err := rows.Scan(&item.BookID, &item.Title, &item.Price, &item.Discount); err != nil {
+			return nil, err
+		}
+
+		order.Items = append(order.Items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (m OrderModel) GetAllByUser(userID int64, filters Filters) ([]*Order, *Metadata, error) {
+	query := `
+		SELECT count(*) OVER(), id, user_id, status, total, discount, currency, coupon_id, coalesce(payment_provider, ''), created_at, updated_at, version
+		FROM orders
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	orders := []*Order{}
+
+	for rows.Next() {
+		var order Order
+
+		err := rows.Scan(
+			&totalRecords,
+			&order.ID,
+			&order.UserID,
+			&order.Status,
+			&order.Total,
+			&order.Discount,
+			&order.Currency,
+			&order.CouponID,
+			&order.PaymentProvider,
+			&order.CreatedAt,
+			&order.UpdatedAt,
+			&order.Version,
+		)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		orders = append(orders, &order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return orders, &metadata, nil
+}
+
+// Transition moves the order to status to, enforcing the order state machine
+// and optimistic locking. Paying an order grants the buyer an entitlement to
+// every book in it and posts the sale to the ledger; refunding revokes those
+// entitlements and reverses the sale.
+func (m OrderModel) Transition(order *Order, to string) error {
+	if !CanTransition(order.Status, to) {
+		return ErrInvalidTransition
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE orders
+		SET status = $1, updated_at = NOW(), version = version + 1
+		WHERE id = $2 AND version = $3 AND status = $4
+		RETURNING updated_at, version
+	`, to, order.ID, order.Version, order.Status).Scan(&order.UpdatedAt, &order.Version)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	switch to {
+	case OrderPaid:
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO entitlements (user_id, book_id, source, order_id)
+			SELECT $1, book_id, $2, order_id
+			FROM order_items
+			WHERE order_id = $3 AND book_id IS NOT NULL
+			ON CONFLICT (user_id, book_id) DO NOTHING
+		`, order.UserID, EntitlementSourcePurchase, order.ID)
+
+		if err == nil {
+			err = postSale(ctx, tx, order)
+		}
+
+	case OrderRefunded:
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM entitlements
+			WHERE user_id = $1 AND order_id = $2 AND source = $3
+		`, order.UserID, order.ID, EntitlementSourcePurchase)
+
+		if err == nil {
+			err = postRefund(ctx, tx, order)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	order.Status = to
+
+	return nil
+}
+
+// ExpirePending cancels orders that were created before the given time and
+// are still waiting for payment, giving back any coupon use they held. It
+// returns the cancelled orders so their payments can be voided.
+func (m OrderModel) ExpirePending(before time.Time) ([]*Order, error) {
+	query := `
+		UPDATE orders
+		SET status = $1, updated_at = NOW(), version = version + 1
+		WHERE status = $2 AND created_at < $3
+		RETURNING id, user_id, status, total, discount, currency, coalesce(payment_provider, ''), coalesce(payment_ref, ''), created_at, updated_at, version
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, OrderCancelled, OrderPending, before)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	orders := []*Order{}
+
+	for rows.Next() {
+		var order Order
+
+		err := rows.Scan(
+			&order.ID,
+			&order.UserID,
+			&order.Status,
+			&order.Total,
+			&order.Discount,
+			&order.Currency,
+			&order.PaymentProvider,
+			&order.PaymentRef,
+			&order.CreatedAt,
+			&order.UpdatedAt,
+			&order.Version,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, &order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
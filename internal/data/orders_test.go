@@ -0,0 +1,28 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want bool
+	}{
+		{OrderPending, OrderPaid, true},
+		{OrderPending, OrderCancelled, true},
+		{OrderPaid, OrderRefunded, true},
+		{OrderPending, OrderRefunded, false},
+		{OrderPaid, OrderCancelled, false},
+		{OrderCancelled, OrderPaid, false},
+		{OrderRefunded, OrderPaid, false},
+		{OrderPaid, OrderPaid, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, CanTransition(tt.from, tt.to), "%s -> %s", tt.from, tt.to)
+	}
+}
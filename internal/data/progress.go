@@ -0,0 +1,221 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+// ReadingProgress is where a user left off in a book. Devices sync it by
+// sending the time the position was recorded; the most recent one wins.
+type ReadingProgress struct {
+	BookID    int64     `json:"bookId"`
+	ChapterID int64     `json:"chapterId"`
+	Position  *int      `json:"position"`
+	Percent   *float64  `json:"percent"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func ValidateReadingProgress(v *validator.Validator, progress *ReadingProgress) {
+	v.Check(progress.ChapterID > 0, "chapterId", "must be provided")
+
+	if progress.Position != nil {
+		v.Check(*progress.Position >= 0, "position", "must not be negative")
+	}
+
+	if progress.Percent != nil {
+		v.Check(*progress.Percent >= 0 && *progress.Percent <= 100, "percent", "must be between 0 and 100")
+	}
+}
+
+// ProgressTime returns the timestamp a progress update is recorded with: the
+// time the client reported, or now when it is missing or in the future, so a
+// device with a fast clock cannot pin its position over later reads.
+func ProgressTime(reported *time.Time, now time.Time) time.Time {
+	if reported == nil || reported.After(now) {
+		return now
+	}
+
+	return *reported
+}
+
+type ChapterRef struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	ChapterNo int64  `json:"chapterNo"`
+}
+
+type ContinueReading struct {
+	BookID       int64       `json:"bookId"`
+	Title        string      `json:"title"`
+	CoverPicture string      `json:"coverPicture"`
+	Chapter      ChapterRef  `json:"chapter"`
+	NextChapter  *ChapterRef `json:"nextChapter"`
+	Position     *int        `json:"position"`
+	Percent      *float64    `json:"percent"`
+	UpdatedAt    time.Time   `json:"updatedAt"`
+}
+
+type ProgressModel struct {
+	DB *sql.DB
+}
+
+// Save records the user's progress unless a more recent update is already
+// stored. It reports whether the update was applied; when it was not,
+// progress is overwritten with the stored state.
+func (m ProgressModel) Save(userID int64, progress *ReadingProgress) (bool, error) {
+	query := `
+		INSERT INTO reading_progress (user_id, book_id, chapter_id, position, percent, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, book_id) DO UPDATE
+		SET chapter_id = EXCLUDED.chapter_id, position = EXCLUDED.position, percent = EXCLUDED.percent, updated_at = EXCLUDED.updated_at
+		WHERE reading_progress.updated_at < EXCLUDED.updated_at
+		RETURNING updated_at
+	`
+
+	args := []any{userID, progress.BookID, progress.ChapterID, progress.Position, progress.Percent, progress.UpdatedAt}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&progress.UpdatedAt)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			stored, err := m.Get(userID, progress.BookID)
+			if err != nil {
+				return false, err
+			}
+
+			*progress = *stored
+
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (m ProgressModel) Get(userID, bookID int64) (*ReadingProgress, error) {
+	query := `
+		SELECT book_id, chapter_id, position, percent, updated_at
+		FROM reading_progress
+		WHERE user_id = $1 AND book_id = $2
+	`
+
+	var progress ReadingProgress
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, userID, bookID).Scan(
+		&progress.BookID,
+		&progress.ChapterID,
+		&progress.Position,
+		&progress.Percent,
+		&progress.UpdatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &progress, nil
+}
+
+// ContinueReading lists the books the user is part way through, most recently
+// read first, with the chapter after the one they are on. A book counts as
+// finished once the last chapter is fully read or it sits on the user's
+// finished shelf.
+func (m ProgressModel) ContinueReading(userID int64, filters Filters) ([]*ContinueReading, *Metadata, error) {
+	query := `
+		SELECT count(*) OVER(), rp.book_id, b.title, b.cover_picture, c.id, c.title, c.chapter_no,
+			n.id, n.title, n.chapter_no, rp.position, rp.percent, rp.updated_at
+		FROM reading_progress rp
+		INNER JOIN books b ON b.id = rp.book_id AND b.deleted_at IS NULL
+		INNER JOIN chapters c ON c.id = rp.chapter_id AND c.deleted_at IS NULL
+		LEFT JOIN LATERAL (
+			SELECT nc.id, nc.title, nc.chapter_no
+			FROM chapters nc
+			WHERE nc.book_id = rp.book_id AND nc.deleted_at IS NULL AND nc.is_published = true
+				AND (nc.chapter_no, nc.id) > (c.chapter_no, c.id)
+			ORDER BY nc.chapter_no, nc.id
+			LIMIT 1
+		) n ON true
+		WHERE rp.user_id = $1
+			AND (n.id IS NOT NULL OR coalesce(rp.percent, 0) < 100)
+			AND NOT EXISTS (
+				SELECT 1
+				FROM shelf_books sb
+				INNER JOIN shelves s ON s.id = sb.shelf_id
+				WHERE s.user_id = rp.user_id AND s.kind = 'finished' AND sb.book_id = rp.book_id
+			)
+		ORDER BY rp.updated_at DESC, rp.book_id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	items := []*ContinueReading{}
+
+	for rows.Next() {
+		var item ContinueReading
+		var nextID sql.NullInt64
+		var nextTitle sql.NullString
+		var nextNo sql.NullInt64
+
+		err := rows.Scan(
+			&totalRecords,
+			&item.BookID,
+			&item.Title,
+			&item.CoverPicture,
+			&item.Chapter.ID,
+			&item.Chapter.Title,
+			&item.Chapter.ChapterNo,
+			&nextID,
+			&nextTitle,
+			&nextNo,
+			&item.Position,
+			&item.Percent,
+			&item.UpdatedAt,
+		)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if nextID.Valid {
+			item.NextChapter = &ChapterRef{ID: nextID.Int64, Title: nextTitle.String, ChapterNo: nextNo.Int64}
+		}
+
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return items, &metadata, nil
+}
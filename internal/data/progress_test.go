@@ -0,0 +1,20 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressTime(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+	later := now.Add(time.Hour)
+
+	assert.Equal(t, now, ProgressTime(nil, now))
+	assert.Equal(t, earlier, ProgressTime(&earlier, now))
+
+	// A device whose clock runs ahead must not win every later sync.
+	assert.Equal(t, now, ProgressTime(&later, now))
+}
@@ -0,0 +1,82 @@
+package data
+
+import (
+	"bytes"
+	"html"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+const (
+	ContentFormatMarkdown = "markdown"
+	ContentFormatText     = "text"
+)
+
+var (
+	markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	// htmlPolicy allows the elements Markdown produces and drops everything
+	// else, including scripts, styles and event handler attributes.
+	htmlPolicy = bluemonday.UGCPolicy()
+
+	textPolicy = bluemonday.StrictPolicy()
+)
+
+// RenderHTML renders chapter content written in the given format to
+// sanitized HTML. Plain text is escaped and split into paragraphs on blank
+// lines.
+func RenderHTML(format, content string) (string, error) {
+	if format != ContentFormatMarkdown {
+		return textHTML(content), nil
+	}
+
+	var buf bytes.Buffer
+
+	err := markdown.Convert([]byte(content), &buf)
+	if err != nil {
+		return "", err
+	}
+
+	return htmlPolicy.Sanitize(buf.String()), nil
+}
+
+// RenderText returns chapter content with any Markdown formatting removed.
+func RenderText(format, content string) (string, error) {
+	if format != ContentFormatMarkdown {
+		return content, nil
+	}
+
+	rendered, err := RenderHTML(format, content)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(html.UnescapeString(textPolicy.Sanitize(rendered))), nil
+}
+
+func textHTML(content string) string {
+	var out strings.Builder
+
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	for _, paragraph := range strings.Split(content, "\n\n") {
+		paragraph = strings.Trim(paragraph, "\n")
+		if strings.TrimSpace(paragraph) == "" {
+			continue
+		}
+
+		lines := strings.Split(paragraph, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+
+		out.WriteString("<p>")
+		out.WriteString(strings.Join(lines, "<br>\n"))
+		out.WriteString("</p>\n")
+	}
+
+	return out.String()
+}
@@ -0,0 +1,35 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderHTML(t *testing.T) {
+	got, err := RenderHTML(ContentFormatMarkdown, "# Title\n\nSome *emphasis* and [a link](https://example.com).")
+	assert.NoError(t, err)
+	assert.Contains(t, got, "<h1>Title</h1>")
+	assert.Contains(t, got, "<em>emphasis</em>")
+	assert.Contains(t, got, `href="https://example.com"`)
+
+	got, err = RenderHTML(ContentFormatMarkdown, "[click](javascript:alert(1)) <img src=x onerror=alert(1)> <script>alert(1)</script>")
+	assert.NoError(t, err)
+	assert.NotContains(t, got, "javascript:")
+	assert.NotContains(t, got, "onerror")
+	assert.NotContains(t, got, "<script")
+
+	got, err = RenderHTML(ContentFormatText, "First line\nsecond <b>line</b>\n\nNext paragraph")
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>First line<br>\nsecond &lt;b&gt;line&lt;/b&gt;</p>\n<p>Next paragraph</p>\n", got)
+}
+
+func TestRenderText(t *testing.T) {
+	got, err := RenderText(ContentFormatMarkdown, "Some **bold** text & more")
+	assert.NoError(t, err)
+	assert.Equal(t, "Some bold text & more", got)
+
+	got, err = RenderText(ContentFormatText, "Some **literal** stars")
+	assert.NoError(t, err)
+	assert.Equal(t, "Some **literal** stars", got)
+}
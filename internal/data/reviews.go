@@ -0,0 +1,289 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+	"github.com/lib/pq"
+)
+
+var ErrDuplicateReview = errors.New("duplicate review")
+
+type Review struct {
+	ID        int64     `json:"id"`
+	BookID    int64     `json:"bookId"`
+	UserID    int64     `json:"userId"`
+	UserName  string    `json:"userName"`
+	Rating    int       `json:"rating"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Version   int       `json:"-"`
+}
+
+func ValidateReview(v *validator.Validator, review *Review) {
+	v.Check(review.Rating >= 1 && review.Rating <= 5, "rating", "must be between 1 and 5")
+	v.Check(len(review.Body) <= 5000, "body", "must not be more than 5000 bytes long")
+}
+
+type ReviewModel struct {
+	DB *sql.DB
+}
+
+var reviewSortKeys = map[string]string{
+	"created_at": "r.created_at",
+	"rating":     "r.rating",
+}
+
+// Insert adds the review and refreshes the book's rating aggregate in the same
+// transaction. The book row is locked first so concurrent reviews of the same
+// book recompute the aggregate one after another instead of overwriting each
+// other with stale counts.
+func (m ReviewModel) Insert(review *Review) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = lockBook(ctx, tx, review.BookID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO reviews (book_id, user_id, rating, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at, version
+	`
+
+	args := []any{review.BookID, review.UserID, review.Rating, review.Body}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&review.ID, &review.CreatedAt, &review.UpdatedAt, &review.Version)
+
+	if err != nil {
+		var pqErr *pq.Error
+
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Constraint == "reviews_book_id_user_id_key":
+			return ErrDuplicateReview
+		default:
+			return err
+		}
+	}
+
+	err = refreshBookRating(ctx, tx, review.BookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m ReviewModel) Get(id int64) (*Review, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT r.id, r.book_id, r.user_id, u.name, r.rating, r.body, r.created_at, r.updated_at, r.version
+		FROM reviews r
+		INNER JOIN users u ON u.id = r.user_id
+		WHERE r.id = $1
+	`
+
+	var review Review
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&review.ID,
+		&review.BookID,
+		&review.UserID,
+		&review.UserName,
+		&review.Rating,
+		&review.Body,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+		&review.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &review, nil
+}
+
+func (m ReviewModel) GetByBook(bookID int64, filters Filters) ([]*Review, *Metadata, error) {
+	column := reviewSortKeys[filters.sortColumn()]
+	direction := filters.sortDirecton()
+
+	query := fmt.Sprintf(`
+		SELECT count(*) OVER(), r.id, r.book_id, r.user_id, u.name, r.rating, r.body, r.created_at, r.updated_at, r.version
+		FROM reviews r
+		INNER JOIN users u ON u.id = r.user_id
+		WHERE r.book_id = $1
+		ORDER BY %s %s, r.id %s
+		LIMIT $2 OFFSET $3
+	`, column, direction, direction)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, bookID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	reviews := []*Review{}
+
+	for rows.Next() {
+		var review Review
+
+		err := rows.Scan(
+			&totalRecords,
+			&review.ID,
+			&review.BookID,
+			&review.UserID,
+			&review.UserName,
+			&review.Rating,
+			&review.Body,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+			&review.Version,
+		)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		reviews = append(reviews, &review)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return reviews, &metadata, nil
+}
+
+// Update edits the review under optimistic locking and refreshes the book's
+// rating aggregate, locking the book row like Insert.
+func (m ReviewModel) Update(review *Review) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = lockBook(ctx, tx, review.BookID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE reviews
+		SET rating = $1, body = $2, updated_at = NOW(), version = version + 1
+		WHERE id = $3 AND version = $4
+		RETURNING updated_at, version
+	`
+
+	args := []any{review.Rating, review.Body, review.ID, review.Version}
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&review.UpdatedAt, &review.Version)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	err = refreshBookRating(ctx, tx, review.BookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes the review and refreshes the book's rating aggregate.
+func (m ReviewModel) Delete(review *Review) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = lockBook(ctx, tx, review.BookID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM reviews WHERE id = $1`, review.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	err = refreshBookRating(ctx, tx, review.BookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// refreshBookRating recomputes the book's rating aggregate from its reviews.
+// The book's version is left alone so a review never causes an edit conflict
+// for the author.
+func refreshBookRating(ctx context.Context, tx *sql.Tx, bookID int64) error {
+	query := `
+		UPDATE books
+		SET rating_average = coalesce((SELECT round(avg(rating), 2) FROM reviews WHERE book_id = $1), 0),
+			rating_count = (SELECT count(*) FROM reviews WHERE book_id = $1)
+		WHERE id = $1
+	`
+
+	_, err := tx.ExecContext(ctx, query, bookID)
+
+	return err
+}
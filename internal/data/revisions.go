@@ -0,0 +1,432 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// ChapterRevision is a saved version of a chapter. Revision numbers are the
+// chapter's version at the time it was saved.
+type ChapterRevision struct {
+	ID            int64     `json:"id"`
+	ChapterID     int64     `json:"chapterId"`
+	Revision      int       `json:"revision"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	Content       *string   `json:"content,omitempty"`
+	ContentFormat string    `json:"contentFormat"`
+	Access        string    `json:"access"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type RevisionDiff struct {
+	From    int      `json:"from"`
+	To      int      `json:"to,omitempty"`
+	Format  string   `json:"format"`
+	Unified string   `json:"unified,omitempty"`
+	Ops     []DiffOp `json:"ops,omitempty"`
+}
+
+type ChapterRevisionModel struct {
+	DB *sql.DB
+}
+
+// insertRevision records the chapter as it now stands. It runs inside the
+// transaction that saved the chapter so history never misses a version.
+func insertRevision(ctx context.Context, tx *sql.Tx, chapter *Chapter) error {
+	query := `
+		INSERT INTO chapter_revisions (chapter_id, revision, title, description, content, content_format, access)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	args := []any{chapter.ID, chapter.Version, chapter.Title, chapter.Description, chapter.Content, chapter.ContentFormat, chapter.Access}
+
+	_, err := tx.ExecContext(ctx, query, args...)
+
+	return err
+}
+
+// GetAll lists the revisions of a chapter, newest first, without their
+// content.
+func (m ChapterRevisionModel) GetAll(chapterID int64, filters Filters) ([]*ChapterRevision, *Metadata, error) {
+	query := `
+		SELECT count(*) OVER(), id, chapter_id, revision, title, description, content_format, access, created_at
+		FROM chapter_revisions
+		WHERE chapter_id = $1
+		ORDER BY revision DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, chapterID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	revisions := []*ChapterRevision{}
+
+	for rows.Next() {
+		var revision ChapterRevision
+
+		err := rows.Scan(
+			&totalRecords,
+			&revision.ID,
+			&revision.ChapterID,
+			&revision.Revision,
+			&revision.Title,
+			&revision.Description,
+			&revision.ContentFormat,
+			&revision.Access,
+			&revision.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		revisions = append(revisions, &revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return revisions, &metadata, nil
+}
+
+func (m ChapterRevisionModel) Get(chapterID int64, revision int) (*ChapterRevision, error) {
+	query := `
+		SELECT id, chapter_id, revision, title, description, content, content_format, access, created_at
+		FROM chapter_revisions
+		WHERE chapter_id = $1 AND revision = $2
+	`
+
+	var r ChapterRevision
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, chapterID, revision).Scan(
+		&r.ID,
+		&r.ChapterID,
+		&r.Revision,
+		&r.Title,
+		&r.Description,
+		&r.Content,
+		&r.ContentFormat,
+		&r.Access,
+		&r.CreatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &r, nil
+}
+
+// GetAt returns the chapter as it stood at the given version: the newest
+// revision saved at or before it. Versions bumped without a content change,
+// such as publishing, have no revision of their own.
+func (m ChapterRevisionModel) GetAt(chapterID int64, version int) (*ChapterRevision, error) {
+	query := `
+		SELECT id, chapter_id, revision, title, description, content, content_format, access, created_at
+		FROM chapter_revisions
+		WHERE chapter_id = $1 AND revision <= $2
+		ORDER BY revision DESC
+		LIMIT 1
+	`
+
+	var r ChapterRevision
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, chapterID, version).Scan(
+		&r.ID,
+		&r.ChapterID,
+		&r.Revision,
+		&r.Title,
+		&r.Description,
+		&r.Content,
+		&r.ContentFormat,
+		&r.Access,
+		&r.CreatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &r, nil
+}
+
+// Prune deletes revisions saved before the given time, always keeping the
+// newest keep revisions of every chapter. Revisions from the one an autosave
+// was started from onwards are kept too, as committing the autosave merges
+// against it. Only a chapter's oldest revisions are ever deleted, so GetAt
+// never passes off an older revision for a pruned one.
+func (m ChapterRevisionModel) Prune(keep int, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM chapter_revisions r
+		USING (
+			SELECT id, row_number() OVER (PARTITION BY chapter_id ORDER BY revision DESC) AS rank
+			FROM chapter_revisions
+		) ranked
+		WHERE r.id = ranked.id AND ranked.rank > $1 AND r.created_at < $2
+		AND NOT EXISTS (
+			SELECT 1
+			FROM chapter_autosaves a
+			WHERE a.chapter_id = r.chapter_id
+			AND r.revision >= (
+				SELECT max(b.revision)
+				FROM chapter_revisions b
+				WHERE b.chapter_id = a.chapter_id AND b.revision <= a.base_version
+			)
+		)
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, keep, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+var diffTokenRX = regexp.MustCompile(`\s+|[^\s]+`)
+
+// WordDiff compares two texts word by word. Runs of whitespace count as
+// words so the operations concatenate back into either text.
+func WordDiff(from, to string) []DiffOp {
+	tokens := []string{}
+	index := make(map[string]rune)
+
+	encode := func(text string) []rune {
+		words := diffTokenRX.FindAllString(text, -1)
+		runes := make([]rune, len(words))
+
+		for i, word := range words {
+			r, ok := index[word]
+			if !ok {
+				r = tokenRune(len(tokens))
+				index[word] = r
+				tokens = append(tokens, word)
+			}
+
+			runes[i] = r
+		}
+
+		return runes
+	}
+
+	a, b := encode(from), encode(to)
+
+	decode := make(map[rune]string, len(tokens))
+	for word, r := range index {
+		decode[r] = word
+	}
+
+	dmp := diffmatchpatch.New()
+	ops := []DiffOp{}
+
+	for _, d := range dmp.DiffMainRunes(a, b, false) {
+		var text strings.Builder
+
+		for _, r := range d.Text {
+			text.WriteString(decode[r])
+		}
+
+		op := diffOpName(d.Type)
+
+		if n := len(ops); n > 0 && ops[n-1].Op == op {
+			ops[n-1].Text += text.String()
+			continue
+		}
+
+		ops = append(ops, DiffOp{Op: op, Text: text.String()})
+	}
+
+	return ops
+}
+
+// tokenRune maps a token index to a rune, skipping the surrogate range so
+// the diff library can round-trip it through a string.
+func tokenRune(i int) rune {
+	r := rune(i)
+	if r >= 0xD800 {
+		r += 0x800
+	}
+
+	return r
+}
+
+func diffOpName(op diffmatchpatch.Operation) string {
+	switch op {
+	case diffmatchpatch.DiffInsert:
+		return DiffInsert
+	case diffmatchpatch.DiffDelete:
+		return DiffDelete
+	default:
+		return DiffEqual
+	}
+}
+
+type diffLine struct {
+	op   string
+	text string
+}
+
+// UnifiedDiff compares two texts line by line and formats the result like
+// diff -u with the given number of context lines. It returns an empty string
+// when the texts are the same.
+func UnifiedDiff(fromName, toName, from, to string, context int) string {
+	dmp := diffmatchpatch.New()
+
+	a, b, lineArray := dmp.DiffLinesToRunes(from, to)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMainRunes(a, b, false), lineArray)
+
+	lines := []diffLine{}
+
+	for _, d := range diffs {
+		for _, text := range strings.SplitAfter(d.Text, "\n") {
+			if text != "" {
+				lines = append(lines, diffLine{op: diffOpName(d.Type), text: text})
+			}
+		}
+	}
+
+	// oldNo[i] and newNo[i] count the lines of each text before lines[i].
+	oldNo := make([]int, len(lines)+1)
+	newNo := make([]int, len(lines)+1)
+
+	for i, line := range lines {
+		oldNo[i+1], newNo[i+1] = oldNo[i], newNo[i]
+
+		if line.op != DiffInsert {
+			oldNo[i+1]++
+		}
+
+		if line.op != DiffDelete {
+			newNo[i+1]++
+		}
+	}
+
+	var out strings.Builder
+
+	for i := 0; i < len(lines); {
+		for i < len(lines) && lines[i].op == DiffEqual {
+			i++
+		}
+
+		if i == len(lines) {
+			break
+		}
+
+		start := max(i-context, 0)
+		end := i
+
+		// Grow the hunk over changes and over unchanged runs short enough
+		// that their context would overlap with the next hunk's.
+		for end < len(lines) {
+			if lines[end].op != DiffEqual {
+				end++
+				continue
+			}
+
+			next := end
+			for next < len(lines) && lines[next].op == DiffEqual {
+				next++
+			}
+
+			if next == len(lines) || next-end > 2*context {
+				end = min(end+context, len(lines))
+				break
+			}
+
+			end = next
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(oldNo[start], oldNo[end]-oldNo[start]),
+			hunkRange(newNo[start], newNo[end]-newNo[start]),
+		)
+
+		for _, line := range lines[start:end] {
+			switch line.op {
+			case DiffInsert:
+				out.WriteByte('+')
+			case DiffDelete:
+				out.WriteByte('-')
+			default:
+				out.WriteByte(' ')
+			}
+
+			out.WriteString(line.text)
+
+			if !strings.HasSuffix(line.text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		i = end
+	}
+
+	return out.String()
+}
+
+// hunkRange formats the line range of a hunk. An empty range names the line
+// before it, as diff does.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
@@ -0,0 +1,49 @@
+package data
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	from := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	to := "one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+
+	want := "--- revision 1\n+++ revision 2\n" +
+		"@@ -1,5 +1,5 @@\n one\n-two\n+2\n three\n four\n five\n" +
+		"@@ -8,3 +8,4 @@\n eight\n nine\n ten\n+eleven\n"
+
+	assert.Equal(t, want, UnifiedDiff("revision 1", "revision 2", from, to, 3))
+	assert.Equal(t, "", UnifiedDiff("a", "b", from, from, 3))
+
+	got := UnifiedDiff("a", "b", "", "first line", 3)
+	assert.Equal(t, "--- a\n+++ b\n@@ -0,0 +1 @@\n+first line\n\\ No newline at end of file\n", got)
+}
+
+func TestWordDiff(t *testing.T) {
+	from := "The quick brown fox jumps"
+	to := "The slow brown fox  leaps"
+
+	ops := WordDiff(from, to)
+
+	var before, after strings.Builder
+
+	for _, op := range ops {
+		if op.Op != DiffInsert {
+			before.WriteString(op.Text)
+		}
+
+		if op.Op != DiffDelete {
+			after.WriteString(op.Text)
+		}
+	}
+
+	assert.Equal(t, from, before.String())
+	assert.Equal(t, to, after.String())
+
+	assert.Contains(t, ops, DiffOp{Op: DiffDelete, Text: "quick"})
+	assert.Contains(t, ops, DiffOp{Op: DiffInsert, Text: "slow"})
+	assert.Equal(t, []DiffOp{}, WordDiff("", ""))
+}
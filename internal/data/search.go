@@ -0,0 +1,213 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+)
+
+const (
+	SearchResultBook    = "book"
+	SearchResultChapter = "chapter"
+	SearchResultAuthor  = "author"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ts_headline marks matches with these private use characters rather than
+// HTML, so the document text can be escaped before the marks are turned
+// into <mark> tags. They are stripped from documents beforehand.
+const (
+	headlineStart = "\uE000"
+	headlineStop  = "\uE001"
+)
+
+var headlineMarker = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// SearchResult is a search match. Headline is HTML: the matched passage,
+// escaped, with the matching words wrapped in <mark> tags.
+type SearchResult struct {
+	Type     string  `json:"type"`
+	ID       int64   `json:"id"`
+	BookID   int64   `json:"bookId"`
+	Title    string  `json:"title"`
+	Headline string  `json:"headline"`
+	Rank     float64 `json:"rank"`
+}
+
+func ValidateSearchQuery(v *validator.Validator, query string) {
+	v.Check(query != "", "q", "must be provided")
+	v.Check(len(query) <= 200, "q", "must not be more than 200 bytes long")
+}
+
+type SearchModel struct {
+	DB *sql.DB
+}
+
+// Search matches the query against the weighted search vectors of published
+// books and their chapters. The query is parsed with websearch_to_tsquery so
+// quoted phrases, "or" and "-exclusions" are supported. Premium chapters are
+// only indexed and headlined by their title and description, so search can't
+// be used to read or probe paid content.
+func (m SearchModel) Search(query string, filters Filters) ([]*SearchResult, *Metadata, error) {
+	stmt := `
+	WITH q AS (
+		SELECT websearch_to_tsquery('simple', $1) AS query
+	), matches AS (
+		SELECT 'book' AS type, b.id, b.id AS book_id, b.title,
+			coalesce(nullif(b.description, ''), b.title) AS document,
+			ts_rank(b.search_vector, q.query) AS rank
+		FROM books b, q
+		WHERE b.search_vector @@ q.query AND b.is_published = true AND b.deleted_at IS NULL
+
+		UNION ALL
+
+		SELECT 'chapter' AS type, c.id, c.book_id, c.title,
+			CASE WHEN c.access = 'premium' THEN coalesce(nullif(c.description, ''), c.title)
+				ELSE coalesce(nullif(c.content, ''), nullif(c.description, ''), c.title)
+			END AS document,
+			ts_rank(c.search_vector, q.query) AS rank
+		FROM chapters c
+		INNER JOIN books b ON b.id = c.book_id, q
+		WHERE c.search_vector @@ q.query AND b.is_published = true AND c.is_published = true
+		AND c.deleted_at IS NULL AND b.deleted_at IS NULL
+	), page AS (
+		SELECT count(*) OVER() AS total, type, id, book_id, title, document, rank
+		FROM matches
+		ORDER BY rank DESC, type ASC, id ASC
+		LIMIT $2 OFFSET $3
+	)
+	SELECT page.total, page.type, page.id, page.book_id, page.title,
+		ts_headline('simple', translate(page.document, $4::text || $5::text, ''), q.query,
+			'StartSel=' || $4::text || ', StopSel=' || $5::text || ', MaxFragments=2, MaxWords=30, MinWords=10'),
+		page.rank
+	FROM page, q
+	ORDER BY page.rank DESC, page.type ASC, page.id ASC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, stmt, query, filters.limit(), filters.offset(), headlineStart, headlineStop)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	results := []*SearchResult{}
+
+	for rows.Next() {
+		var result SearchResult
+
+		err := rows.Scan(
+			&totalRecords,
+			&result.Type,
+			&result.ID,
+			&result.BookID,
+			&result.Title,
+			&result.Headline,
+			&result.Rank,
+		)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		result.Headline = markHeadline(result.Headline)
+
+		results = append(results, &result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return results, &metadata, nil
+}
+
+// markHeadline escapes a ts_headline result and turns its match markers into
+// <mark> tags.
+func markHeadline(headline string) string {
+	return headlineMarker.Replace(html.EscapeString(headline))
+}
+
+type Suggestion struct {
+	Type  string  `json:"type"`
+	ID    int64   `json:"id"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
+
+func ValidateSuggestQuery(v *validator.Validator, query string, limit int) {
+	v.Check(query != "", "q", "must be provided")
+	v.Check(len(query) <= 100, "q", "must not be more than 100 bytes long")
+	v.Check(limit > 0, "limit", "must be greater than zero")
+	v.Check(limit <= 20, "limit", "must be a maximum of 20")
+}
+
+// Suggest returns autocomplete candidates for published book titles and the
+// names of their authors. Prefix matches rank first, then trigram similarity
+// catches misspellings such as "hary poter".
+func (m SearchModel) Suggest(query string, limit int) ([]*Suggestion, error) {
+	stmt := `
+	SELECT type, id, text, score FROM (
+		SELECT 'book' AS type, id, title AS text,
+			similarity(title, $1) AS score,
+			title ILIKE $2 AS is_prefix
+		FROM books
+		WHERE is_published = true AND deleted_at IS NULL AND (title ILIKE $2 OR title % $1)
+
+		UNION ALL
+
+		SELECT 'author' AS type, u.id, u.name AS text,
+			similarity(u.name, $1) AS score,
+			u.name ILIKE $2 AS is_prefix
+		FROM users u
+		WHERE (u.name ILIKE $2 OR u.name % $1)
+		AND EXISTS (SELECT 1 FROM books b WHERE b.user_id = u.id AND b.is_published = true AND b.deleted_at IS NULL)
+	) suggestions
+	ORDER BY is_prefix DESC, score DESC, text ASC
+	LIMIT $3
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, stmt, query, escapeLike(query)+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	suggestions := []*Suggestion{}
+
+	for rows.Next() {
+		var suggestion Suggestion
+
+		err := rows.Scan(&suggestion.Type, &suggestion.ID, &suggestion.Text, &suggestion.Score)
+		if err != nil {
+			return nil, err
+		}
+
+		suggestions = append(suggestions, &suggestion)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -0,0 +1,13 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkHeadline(t *testing.T) {
+	headline := "a " + headlineStart + "dragon" + headlineStop + " <img src=x onerror=alert(1)> & co"
+
+	assert.Equal(t, "a <mark>dragon</mark> &lt;img src=x onerror=alert(1)&gt; &amp; co", markHeadline(headline))
+}
@@ -0,0 +1,343 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrBookInOtherSeries = errors.New("book already belongs to another series")
+	ErrInvalidSeriesBook = errors.New("invalid series book")
+)
+
+type Series struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	UserID      int64     `json:"userId"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Books       []*Book   `json:"books"`
+	Version     int       `json:"-"`
+}
+
+// BookSeries places a single book within its series.
+type BookSeries struct {
+	ID       int64         `json:"id"`
+	Title    string        `json:"title"`
+	Position int           `json:"position"`
+	Previous *SeriesVolume `json:"previous"`
+	Next     *SeriesVolume `json:"next"`
+}
+
+type SeriesVolume struct {
+	BookID int64  `json:"bookId"`
+	Title  string `json:"title"`
+	Href   string `json:"href"`
+}
+
+func ValidateSeries(v *validator.Validator, series *Series) {
+	v.Check(series.Title != "", "title", "must be provided")
+	v.Check(len(series.Title) <= 500, "title", "must not be more than 500 bytes long")
+
+	if series.Description != "" {
+		v.Check(len(series.Description) <= 2000, "description", "must not be more than 2000 bytes long")
+	}
+}
+
+func ValidateSeriesBookIDs(v *validator.Validator, bookIDs []int64) {
+	v.Check(len(bookIDs) <= 500, "bookIds", "must not contain more than 500 books")
+
+	seen := make(map[int64]bool, len(bookIDs))
+
+	for _, id := range bookIDs {
+		v.Check(id > 0, "bookIds", "must only contain valid book ids")
+		v.Check(!seen[id], "bookIds", "must not contain duplicate values")
+		seen[id] = true
+	}
+}
+
+type SeriesModel struct {
+	DB *sql.DB
+}
+
+func (m SeriesModel) Insert(series *Series) error {
+	query := `
+		INSERT INTO series (title, description, user_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, version
+	`
+
+	args := []any{series.Title, series.Description, series.UserID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&series.ID, &series.CreatedAt, &series.Version)
+}
+
+// Get returns the series with its volumes in order. Unpublished volumes are
+// only included when includeUnpublished is set, i.e. for the series author.
+func (m SeriesModel) Get(id int64, includeUnpublished bool) (*Series, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, title, coalesce(description, ''), user_id, created_at, version
+		FROM series
+		WHERE id = $1
+	`
+
+	var series Series
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&series.ID,
+		&series.Title,
+		&series.Description,
+		&series.UserID,
+		&series.CreatedAt,
+		&series.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	query = `
+		SELECT b.id, b.created_at, b.title, coalesce(b.description, ''), b.cover_picture, b.user_id, b.version, b.is_published, b.published_at, b.price, b.currency
+		FROM series_books sb
+		INNER JOIN books b ON b.id = sb.book_id
+		WHERE sb.series_id = $1 AND b.deleted_at IS NULL AND (b.is_published = true OR $2)
+		ORDER BY sb.position ASC
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, id, includeUnpublished)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	series.Books = []*Book{}
+
+	for rows.Next() {
+		var book Book
+
+		err := rows.Scan(
+			&book.ID,
+			&book.CreatedAt,
+			&book.Title,
+			&book.Description,
+			&book.CoverPicture,
+			&book.UserID,
+			&book.Version,
+			&book.IsPublished,
+			&book.PublishedAt,
+			&book.Price,
+			&book.Currency,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		series.Books = append(series.Books, &book)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &series, nil
+}
+
+// GetByBooks returns the series each of the books belongs to along with its
+// position and neighbouring published volumes. Books that are not in a
+// series are left out of the map.
+func (m SeriesModel) GetByBooks(bookIDs []int64) (map[int64]*BookSeries, error) {
+	query := `
+		SELECT sb.book_id, s.id, s.title, sb.position, prev.id, prev.title, next.id, next.title
+		FROM series_books sb
+		INNER JOIN series s ON s.id = sb.series_id
+		LEFT JOIN LATERAL (
+			SELECT b.id, b.title
+			FROM series_books p
+			INNER JOIN books b ON b.id = p.book_id
+			WHERE p.series_id = sb.series_id AND p.position < sb.position AND b.is_published = true AND b.deleted_at IS NULL
+			ORDER BY p.position DESC
+			LIMIT 1
+		) prev ON true
+		LEFT JOIN LATERAL (
+			SELECT b.id, b.title
+			FROM series_books n
+			INNER JOIN books b ON b.id = n.book_id
+			WHERE n.series_id = sb.series_id AND n.position > sb.position AND b.is_published = true AND b.deleted_at IS NULL
+			ORDER BY n.position ASC
+			LIMIT 1
+		) next ON true
+		WHERE sb.book_id = ANY($1)
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(bookIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	series := make(map[int64]*BookSeries, len(bookIDs))
+
+	for rows.Next() {
+		var bookID int64
+		var bookSeries BookSeries
+		var prevID, nextID sql.NullInt64
+		var prevTitle, nextTitle sql.NullString
+
+		err := rows.Scan(
+			&bookID,
+			&bookSeries.ID,
+			&bookSeries.Title,
+			&bookSeries.Position,
+			&prevID,
+			&prevTitle,
+			&nextID,
+			&nextTitle,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		bookSeries.Previous = newSeriesVolume(prevID, prevTitle)
+		bookSeries.Next = newSeriesVolume(nextID, nextTitle)
+
+		series[bookID] = &bookSeries
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func newSeriesVolume(id sql.NullInt64, title sql.NullString) *SeriesVolume {
+	if !id.Valid {
+		return nil
+	}
+
+	return &SeriesVolume{
+		BookID: id.Int64,
+		Title:  title.String,
+		Href:   fmt.Sprintf("/v1/books/%d", id.Int64),
+	}
+}
+
+// SetBooks replaces the volumes of the series with bookIDs, in that order,
+// inside a single transaction. Callers check that the user may add each
+// book; SetBooks only requires them to exist outside the trash and not be
+// part of another series. Trashed books stay in the series, numbered after
+// the listed ones, so they are still there when restored.
+func (m SeriesModel) SetBooks(seriesID int64, bookIDs []int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var live int
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT count(*)
+		FROM books
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`, pq.Array(bookIDs)).Scan(&live)
+
+	if err != nil {
+		return err
+	}
+
+	if live != len(bookIDs) {
+		return ErrInvalidSeriesBook
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM series_books sb
+		USING books b
+		WHERE sb.series_id = $1 AND b.id = sb.book_id
+		AND b.deleted_at IS NULL AND NOT (sb.book_id = ANY($2))
+	`, seriesID, pq.Array(bookIDs))
+
+	if err != nil {
+		return err
+	}
+
+	// Positions are unique per series and checked row by row, so move the
+	// remaining rows above every position they are renumbered to first.
+	_, err = tx.ExecContext(ctx, `
+		UPDATE series_books
+		SET position = position + m.top + $2
+		FROM (SELECT coalesce(max(position), 0) AS top FROM series_books WHERE series_id = $1) m
+		WHERE series_id = $1
+	`, seriesID, len(bookIDs))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO series_books (series_id, book_id, position)
+		SELECT $1, book_id, position
+		FROM unnest($2::bigint[]) WITH ORDINALITY AS t(book_id, position)
+		ON CONFLICT (series_id, book_id) DO UPDATE SET position = EXCLUDED.position
+	`, seriesID, pq.Array(bookIDs))
+
+	if err != nil {
+		var pqErr *pq.Error
+
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Constraint == "series_books_book_id_key":
+			return ErrBookInOtherSeries
+		default:
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE series_books sb
+		SET position = $2 + t.rank
+		FROM (
+			SELECT book_id, row_number() OVER (ORDER BY position) AS rank
+			FROM series_books
+			WHERE series_id = $1 AND NOT (book_id = ANY($3))
+		) t
+		WHERE sb.series_id = $1 AND sb.book_id = t.book_id
+	`, seriesID, len(bookIDs), pq.Array(bookIDs))
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,449 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+	"github.com/lib/pq"
+)
+
+const (
+	ShelfWantToRead       = "want-to-read"
+	ShelfCurrentlyReading = "currently-reading"
+	ShelfFinished         = "finished"
+	ShelfCustom           = "custom"
+)
+
+var (
+	ErrDuplicateShelfName = errors.New("duplicate shelf name")
+	ErrDuplicateShelfBook = errors.New("book is already on this shelf")
+)
+
+// defaultShelves are the reading status shelves every user has, in display
+// order. A book sits on at most one of them at a time.
+var defaultShelves = []struct {
+	kind string
+	name string
+}{
+	{ShelfWantToRead, "Want to Read"},
+	{ShelfCurrentlyReading, "Currently Reading"},
+	{ShelfFinished, "Finished"},
+}
+
+type Shelf struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"userId"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	IsPublic  bool      `json:"isPublic"`
+	BookCount int       `json:"bookCount"`
+	CreatedAt time.Time `json:"createdAt"`
+	Version   int       `json:"-"`
+}
+
+// IsStatus reports whether the shelf is one of the default reading status
+// shelves rather than a custom one.
+func (s *Shelf) IsStatus() bool {
+	return s.Kind != ShelfCustom
+}
+
+type ShelfBook struct {
+	Book    *Book     `json:"book"`
+	AddedAt time.Time `json:"addedAt"`
+}
+
+func ValidateShelf(v *validator.Validator, shelf *Shelf) {
+	v.Check(shelf.Name != "", "name", "must be provided")
+	v.Check(len(shelf.Name) <= 100, "name", "must not be more than 100 bytes long")
+}
+
+type ShelfModel struct {
+	DB *sql.DB
+}
+
+// EnsureDefaults creates the user's reading status shelves if they do not
+// exist yet.
+func (m ShelfModel) EnsureDefaults(userID int64) error {
+	query := `
+		INSERT INTO shelves (user_id, name, kind)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	for _, shelf := range defaultShelves {
+		_, err = tx.ExecContext(ctx, query, userID, shelf.name, shelf.kind)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m ShelfModel) Insert(shelf *Shelf) error {
+	query := `
+		INSERT INTO shelves (user_id, name, kind, is_public)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, version
+	`
+
+	args := []any{shelf.UserID, shelf.Name, shelf.Kind, shelf.IsPublic}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&shelf.ID, &shelf.CreatedAt, &shelf.Version)
+
+	if err != nil {
+		var pqErr *pq.Error
+
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Constraint == "shelves_user_id_name_idx":
+			return ErrDuplicateShelfName
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m ShelfModel) Get(id int64) (*Shelf, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT s.id, s.user_id, s.name, s.kind, s.is_public, s.created_at, s.version,
+			(SELECT count(*) FROM shelf_books sb INNER JOIN books b ON b.id = sb.book_id WHERE sb.shelf_id = s.id AND b.deleted_at IS NULL)
+		FROM shelves s
+		WHERE s.id = $1
+	`
+
+	var shelf Shelf
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&shelf.ID,
+		&shelf.UserID,
+		&shelf.Name,
+		&shelf.Kind,
+		&shelf.IsPublic,
+		&shelf.CreatedAt,
+		&shelf.Version,
+		&shelf.BookCount,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &shelf, nil
+}
+
+// GetAllByUser lists the user's shelves, status shelves first. With
+// publicOnly set only the shelves shown on the user's profile are returned.
+func (m ShelfModel) GetAllByUser(userID int64, publicOnly bool) ([]*Shelf, error) {
+	query := `
+		SELECT s.id, s.user_id, s.name, s.kind, s.is_public, s.created_at, s.version,
+			(SELECT count(*) FROM shelf_books sb INNER JOIN books b ON b.id = sb.book_id WHERE sb.shelf_id = s.id AND b.deleted_at IS NULL)
+		FROM shelves s
+		WHERE s.user_id = $1 AND (s.is_public OR NOT $2)
+		ORDER BY array_position(ARRAY['want-to-read', 'currently-reading', 'finished', 'custom'], s.kind), s.created_at, s.id
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, userID, publicOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	shelves := []*Shelf{}
+
+	for rows.Next() {
+		var shelf Shelf
+
+		err := rows.Scan(
+			&shelf.ID,
+			&shelf.UserID,
+			&shelf.Name,
+			&shelf.Kind,
+			&shelf.IsPublic,
+			&shelf.CreatedAt,
+			&shelf.Version,
+			&shelf.BookCount,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		shelves = append(shelves, &shelf)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return shelves, nil
+}
+
+func (m ShelfModel) Update(shelf *Shelf) error {
+	query := `
+		UPDATE shelves
+		SET name = $1, is_public = $2, version = version + 1
+		WHERE id = $3 AND version = $4
+		RETURNING version
+	`
+
+	args := []any{shelf.Name, shelf.IsPublic, shelf.ID, shelf.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&shelf.Version)
+
+	if err != nil {
+		var pqErr *pq.Error
+
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case errors.As(err, &pqErr) && pqErr.Constraint == "shelves_user_id_name_idx":
+			return ErrDuplicateShelfName
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete removes a custom shelf. Status shelves cannot be deleted.
+func (m ShelfModel) Delete(id int64) error {
+	query := `
+		DELETE FROM shelves
+		WHERE id = $1 AND kind = 'custom'
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// GetBooks returns a page of the published books on the shelf, most
+// recently added first.
+func (m ShelfModel) GetBooks(shelfID int64, filters Filters) ([]*ShelfBook, *Metadata, error) {
+	query := `
+		SELECT count(*) OVER(), b.id, b.created_at, b.title, coalesce(b.description, ''), b.cover_picture, b.user_id, b.is_published, b.published_at,
+			b.price, b.currency, b.rating_average, b.rating_count, b.word_count, (b.reading_seconds + 59) / 60, b.version, sb.added_at
+		FROM shelf_books sb
+		INNER JOIN books b ON b.id = sb.book_id
+		WHERE sb.shelf_id = $1 AND b.is_published = true AND b.deleted_at IS NULL
+		ORDER BY sb.added_at DESC, b.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, shelfID, filters.limit(), filters.offset())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer rows.Close()
+
+	totalRecords := 0
+	items := []*ShelfBook{}
+
+	for rows.Next() {
+		var book Book
+		var item ShelfBook
+
+		err := rows.Scan(
+			&totalRecords,
+			&book.ID,
+			&book.CreatedAt,
+			&book.Title,
+			&book.Description,
+			&book.CoverPicture,
+			&book.UserID,
+			&book.IsPublished,
+			&book.PublishedAt,
+			&book.Price,
+			&book.Currency,
+			&book.RatingAverage,
+			&book.RatingCount,
+			&book.WordCount,
+			&book.ReadingMinutes,
+			&book.Version,
+			&item.AddedAt,
+		)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		item.Book = &book
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+
+	return items, &metadata, nil
+}
+
+// AddBook puts the book on the shelf. Adding a book to a status shelf takes it
+// off the owner's other status shelves.
+func (m ShelfModel) AddBook(shelf *Shelf, bookID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = addShelfBook(ctx, tx, shelf, bookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m ShelfModel) RemoveBook(shelfID, bookID int64) error {
+	query := `
+		DELETE FROM shelf_books
+		WHERE shelf_id = $1 AND book_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, shelfID, bookID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// MoveBook takes the book off one shelf and puts it on another in a single
+// transaction.
+func (m ShelfModel) MoveBook(from, to *Shelf, bookID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM shelf_books WHERE shelf_id = $1 AND book_id = $2`, from.ID, bookID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	err = addShelfBook(ctx, tx, to, bookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func addShelfBook(ctx context.Context, tx *sql.Tx, shelf *Shelf, bookID int64) error {
+	if shelf.IsStatus() {
+		_, err := tx.ExecContext(ctx, `
+			DELETE FROM shelf_books sb
+			USING shelves s
+			WHERE sb.shelf_id = s.id AND s.user_id = $1 AND s.kind <> 'custom' AND s.id <> $2 AND sb.book_id = $3
+		`, shelf.UserID, shelf.ID, bookID)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO shelf_books (shelf_id, book_id) VALUES ($1, $2)`, shelf.ID, bookID)
+
+	if err != nil {
+		var pqErr *pq.Error
+
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Constraint == "shelf_books_pkey":
+			return ErrDuplicateShelfBook
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
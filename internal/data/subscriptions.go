@@ -0,0 +1,420 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/validator"
+	"github.com/lib/pq"
+)
+
+const (
+	SubscriptionIncomplete = "incomplete"
+	SubscriptionActive     = "active"
+	SubscriptionPastDue    = "past_due"
+	SubscriptionCancelled  = "cancelled"
+	SubscriptionExpired    = "expired"
+)
+
+var (
+	ErrActiveSubscription = errors.New("user already has a subscription")
+)
+
+// liveSubscription is the SQL counterpart of Subscription.IsLive for a
+// subscriptions row aliased as s.
+const liveSubscription = `(
+	(s.status IN ('active', 'cancelled') AND s.current_period_end > NOW())
+	OR (s.status = 'past_due' AND s.grace_until > NOW())
+)`
+
+type Plan struct {
+	ID             int64     `json:"id"`
+	Name           string    `json:"name"`
+	Price          int64     `json:"price"`
+	Currency       string    `json:"currency"`
+	IntervalMonths int       `json:"intervalMonths"`
+	IsActive       bool      `json:"isActive"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+func ValidatePlan(v *validator.Validator, plan *Plan) {
+	v.Check(plan.Name != "", "name", "must be provided")
+	v.Check(len(plan.Name) <= 200, "name", "must not be more than 200 bytes long")
+
+	v.Check(plan.Price > 0, "price", "must be greater than zero")
+	v.Check(plan.Price <= 10_000_000, "price", "must not be more than 10000000")
+	v.Check(validator.In(plan.Currency, SupportedCurrencies...), "currency", "must be a supported currency")
+
+	v.Check(plan.IntervalMonths >= 1, "intervalMonths", "must be at least 1")
+	v.Check(plan.IntervalMonths <= 12, "intervalMonths", "must not be more than 12")
+}
+
+type Subscription struct {
+	ID                 int64      `json:"id"`
+	UserID             int64      `json:"userId"`
+	PlanID             int64      `json:"planId"`
+	Status             string     `json:"status"`
+	CurrentPeriodStart *time.Time `json:"currentPeriodStart"`
+	CurrentPeriodEnd   *time.Time `json:"currentPeriodEnd"`
+	GraceUntil         *time.Time `json:"graceUntil,omitempty"`
+	PaymentProvider    string     `json:"paymentProvider,omitempty"`
+	PaymentRef         string     `json:"-"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+	Version            int        `json:"-"`
+}
+
+// IsLive reports whether the subscription grants access at now. A cancelled
+// subscription stays live until the end of the period already paid for, and
+// a past due one until its grace period runs out.
+func (s *Subscription) IsLive(now time.Time) bool {
+	switch s.Status {
+	case SubscriptionActive, SubscriptionCancelled:
+		return s.CurrentPeriodEnd != nil && now.Before(*s.CurrentPeriodEnd)
+	case SubscriptionPastDue:
+		return s.GraceUntil != nil && now.Before(*s.GraceUntil)
+	default:
+		return false
+	}
+}
+
+// Renew records a paid period ending at periodEnd. The first payment of an
+// incomplete subscription starts its period at now; later renewals continue
+// from the end of the previous period. Renewals that do not extend the
+// current period are replays and rejected with ErrInvalidTransition.
+func (s *Subscription) Renew(now, periodEnd time.Time) error {
+	switch s.Status {
+	case SubscriptionIncomplete, SubscriptionActive, SubscriptionPastDue:
+	default:
+		return ErrInvalidTransition
+	}
+
+	start := now
+
+	if s.CurrentPeriodEnd != nil {
+		start = *s.CurrentPeriodEnd
+	}
+
+	if !periodEnd.After(start) {
+		return ErrInvalidTransition
+	}
+
+	s.Status = SubscriptionActive
+	s.CurrentPeriodStart = &start
+	s.CurrentPeriodEnd = &periodEnd
+	s.GraceUntil = nil
+
+	return nil
+}
+
+// MarkPastDue records a failed renewal. Access continues for grace after the
+// end of the paid period while the provider retries the charge.
+func (s *Subscription) MarkPastDue(grace time.Duration) error {
+	if s.Status != SubscriptionActive || s.CurrentPeriodEnd == nil {
+		return ErrInvalidTransition
+	}
+
+	graceUntil := s.CurrentPeriodEnd.Add(grace)
+
+	s.Status = SubscriptionPastDue
+	s.GraceUntil = &graceUntil
+
+	return nil
+}
+
+// Cancel stops future renewals. Access already paid for is kept until the
+// end of the current period.
+func (s *Subscription) Cancel() error {
+	switch s.Status {
+	case SubscriptionIncomplete, SubscriptionActive, SubscriptionPastDue:
+	default:
+		return ErrInvalidTransition
+	}
+
+	s.Status = SubscriptionCancelled
+	s.GraceUntil = nil
+
+	return nil
+}
+
+type PlanModel struct {
+	DB *sql.DB
+}
+
+func (m PlanModel) Insert(plan *Plan) error {
+	query := `
+		INSERT INTO plans (name, price, currency, interval_months)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, is_active, created_at
+	`
+
+	args := []any{plan.Name, plan.Price, plan.Currency, plan.IntervalMonths}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&plan.ID, &plan.IsActive, &plan.CreatedAt)
+}
+
+func (m PlanModel) Get(id int64) (*Plan, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+		SELECT id, name, price, currency, interval_months, is_active, created_at
+		FROM plans
+		WHERE id = $1
+	`
+
+	var plan Plan
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&plan.ID,
+		&plan.Name,
+		&plan.Price,
+		&plan.Currency,
+		&plan.IntervalMonths,
+		&plan.IsActive,
+		&plan.CreatedAt,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &plan, nil
+}
+
+func (m PlanModel) GetAllActive() ([]*Plan, error) {
+	query := `
+		SELECT id, name, price, currency, interval_months, is_active, created_at
+		FROM plans
+		WHERE is_active = true
+		ORDER BY price ASC, id ASC
+	`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	plans := []*Plan{}
+
+	for rows.Next() {
+		var plan Plan
+
+		err := rows.Scan(
+			&plan.ID,
+			&plan.Name,
+			&plan.Price,
+			&plan.Currency,
+			&plan.IntervalMonths,
+			&plan.IsActive,
+			&plan.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		plans = append(plans, &plan)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return plans, nil
+}
+
+type SubscriptionModel struct {
+	DB *sql.DB
+}
+
+// Insert creates an incomplete subscription. Subscriptions of the user that
+// have lapsed are expired first; a user can only hold one open subscription
+// at a time.
+func (m SubscriptionModel) Insert(subscription *Subscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE subscriptions s
+		SET status = 'expired', updated_at = NOW(), version = version + 1
+		WHERE s.user_id = $1 AND s.status IN ('active', 'past_due') AND NOT `+liveSubscription,
+		subscription.UserID)
+
+	if err != nil {
+		return err
+	}
+
+	subscription.Status = SubscriptionIncomplete
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO subscriptions (user_id, plan_id, status)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at, version
+	`, subscription.UserID, subscription.PlanID, subscription.Status).Scan(
+		&subscription.ID,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+		&subscription.Version,
+	)
+
+	if err != nil {
+		var pqErr *pq.Error
+
+		switch {
+		case errors.As(err, &pqErr) && pqErr.Constraint == "subscriptions_user_id_open_idx":
+			return ErrActiveSubscription
+		default:
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m SubscriptionModel) Update(subscription *Subscription) error {
+	query := `
+		UPDATE subscriptions
+		SET status = $1, current_period_start = $2, current_period_end = $3, grace_until = $4,
+			payment_provider = nullif($5, ''), payment_ref = nullif($6, ''), updated_at = NOW(), version = version + 1
+		WHERE id = $7 AND version = $8
+		RETURNING updated_at, version
+	`
+
+	args := []any{
+		subscription.Status,
+		subscription.CurrentPeriodStart,
+		subscription.CurrentPeriodEnd,
+		subscription.GraceUntil,
+		subscription.PaymentProvider,
+		subscription.PaymentRef,
+		subscription.ID,
+		subscription.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&subscription.UpdatedAt, &subscription.Version)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetCurrentByUser returns the user's open subscription, or a cancelled one
+// that is still within its paid period.
+func (m SubscriptionModel) GetCurrentByUser(userID int64) (*Subscription, error) {
+	return m.getBy(`s.user_id = $1 AND (s.status IN ('incomplete', 'active', 'past_due') OR `+liveSubscription+`)`, userID)
+}
+
+func (m SubscriptionModel) GetByPaymentRef(ref string) (*Subscription, error) {
+	return m.getBy(`s.payment_ref = $1`, ref)
+}
+
+func (m SubscriptionModel) getBy(where string, arg any) (*Subscription, error) {
+	query := `
+		SELECT s.id, s.user_id, s.plan_id, s.status, s.current_period_start, s.current_period_end, s.grace_until,
+			coalesce(s.payment_provider, ''), coalesce(s.payment_ref, ''), s.created_at, s.updated_at, s.version
+		FROM subscriptions s
+		WHERE ` + where + `
+		ORDER BY s.created_at DESC, s.id DESC
+		LIMIT 1
+	`
+
+	var subscription Subscription
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, arg).Scan(
+		&subscription.ID,
+		&subscription.UserID,
+		&subscription.PlanID,
+		&subscription.Status,
+		&subscription.CurrentPeriodStart,
+		&subscription.CurrentPeriodEnd,
+		&subscription.GraceUntil,
+		&subscription.PaymentProvider,
+		&subscription.PaymentRef,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+		&subscription.Version,
+	)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &subscription, nil
+}
+
+// SetAuthorParticipation opts the author's premium chapters in or out of the
+// subscription catalogue.
+func (m SubscriptionModel) SetAuthorParticipation(userID int64, participating bool) error {
+	query := `DELETE FROM subscription_authors WHERE user_id = $1`
+
+	if participating {
+		query = `
+			INSERT INTO subscription_authors (user_id)
+			VALUES ($1)
+			ON CONFLICT (user_id) DO NOTHING
+		`
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, userID)
+	return err
+}
+
+func (m SubscriptionModel) IsAuthorParticipating(userID int64) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM subscription_authors WHERE user_id = $1)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var participating bool
+
+	err := m.DB.QueryRowContext(ctx, query, userID).Scan(&participating)
+	return participating, err
+}
@@ -0,0 +1,42 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionLifecycle(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	month := now.AddDate(0, 1, 0)
+
+	s := &Subscription{Status: SubscriptionIncomplete}
+	assert.False(t, s.IsLive(now))
+
+	assert.NoError(t, s.Renew(now, month))
+	assert.Equal(t, SubscriptionActive, s.Status)
+	assert.Equal(t, now, *s.CurrentPeriodStart)
+	assert.True(t, s.IsLive(now))
+	assert.False(t, s.IsLive(month))
+
+	// Replayed renewal events must not move the period.
+	assert.ErrorIs(t, s.Renew(now, month), ErrInvalidTransition)
+
+	assert.NoError(t, s.MarkPastDue(72*time.Hour))
+	assert.Equal(t, SubscriptionPastDue, s.Status)
+	assert.True(t, s.IsLive(month.Add(71*time.Hour)))
+	assert.False(t, s.IsLive(month.Add(72*time.Hour)))
+
+	next := month.AddDate(0, 1, 0)
+	assert.NoError(t, s.Renew(month.Add(time.Hour), next))
+	assert.Equal(t, month, *s.CurrentPeriodStart)
+	assert.Nil(t, s.GraceUntil)
+
+	assert.NoError(t, s.Cancel())
+	assert.True(t, s.IsLive(next.Add(-time.Second)))
+	assert.False(t, s.IsLive(next))
+	assert.ErrorIs(t, s.Renew(next, next.AddDate(0, 1, 0)), ErrInvalidTransition)
+	assert.ErrorIs(t, s.MarkPastDue(time.Hour), ErrInvalidTransition)
+	assert.ErrorIs(t, s.Cancel(), ErrInvalidTransition)
+}
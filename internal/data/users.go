@@ -29,6 +29,7 @@ type User struct {
 	Password     password  `json:"-"`
 	Activated    bool      `json:"activated"`
 	AuthProvider string    `json:"authProvider"`
+	IsAdmin      bool      `json:"-"`
 	Version      int       `json:"-"`
 }
 
@@ -160,7 +161,7 @@ func (m UserModel) GetByToken(scope, token string) (*User, error) {
 	tokenHash := sha256.Sum256([]byte(token))
 
 	query := `
-		SELECT users.id, users.created_at, users.name, users.email, users.password_hash, users.activated, users.auth_provider, users.version
+		SELECT users.id, users.created_at, users.name, users.email, users.password_hash, users.activated, users.auth_provider, users.is_admin, users.version
 		FROM users
 		INNER JOIN tokens 
 		ON users.id = tokens.user_id
@@ -185,6 +186,7 @@ func (m UserModel) GetByToken(scope, token string) (*User, error) {
 		&user.Password.hash,
 		&user.Activated,
 		&user.AuthProvider,
+		&user.IsAdmin,
 		&user.Version,
 	)
 
@@ -202,7 +204,7 @@ func (m UserModel) GetByToken(scope, token string) (*User, error) {
 
 func (m UserModel) GetByEmail(email string) (*User, error) {
 	query := `
-		SELECT id, created_at, name, email, password_hash, activated, auth_provider, version
+		SELECT id, created_at, name, email, password_hash, activated, auth_provider, is_admin, version
 		FROM users
 		WHERE email = $1
 	`
@@ -220,6 +222,7 @@ func (m UserModel) GetByEmail(email string) (*User, error) {
 		&user.Password.hash,
 		&user.Activated,
 		&user.AuthProvider,
+		&user.IsAdmin,
 		&user.Version,
 	)
 
@@ -237,7 +240,7 @@ func (m UserModel) GetByEmail(email string) (*User, error) {
 
 func (m UserModel) GetByID(id int64) (*User, error) {
 	query := `
-		SELECT id, created_at, name, email, password_hash, activated, auth_provider, version
+		SELECT id, created_at, name, email, password_hash, activated, auth_provider, is_admin, version
 		FROM users
 		WHERE id = $1
 	`
@@ -255,6 +258,7 @@ func (m UserModel) GetByID(id int64) (*User, error) {
 		&user.Password.hash,
 		&user.Activated,
 		&user.AuthProvider,
+		&user.IsAdmin,
 		&user.Version,
 	)
 
@@ -0,0 +1,140 @@
+package data
+
+import (
+	"math"
+	"unicode"
+)
+
+// Average silent reading speeds. Chinese and Japanese are read character
+// by character rather than word by word, and Thai, Lao, Khmer and Myanmar
+// are timed by syllable cluster, which is roughly half a syllable to a
+// syllable of text.
+const (
+	wordsPerMinute    = 230
+	cjkCharsPerMinute = 500
+	clustersPerMinute = 700
+)
+
+// TextStats is the length of a piece of text.
+type TextStats struct {
+	Words          int
+	ReadingSeconds int
+}
+
+// ReadingMinutes rounds the reading time up to whole minutes.
+func (s TextStats) ReadingMinutes() int {
+	return (s.ReadingSeconds + 59) / 60
+}
+
+// ContentStats measures chapter content written in the given format.
+// Markdown formatting is not counted.
+func ContentStats(format string, content *string) (TextStats, error) {
+	if content == nil {
+		return TextStats{}, nil
+	}
+
+	text, err := RenderText(format, *content)
+	if err != nil {
+		return TextStats{}, err
+	}
+
+	return CountText(text), nil
+}
+
+// CountText counts the words in text. Runs of letters and digits count as
+// one word, joined by inner apostrophes and hyphens. Han, Hiragana and
+// Katakana are written without spaces, so each of those characters counts
+// as a word of its own and is timed at the character reading speed. Thai,
+// Lao, Khmer and Myanmar are also written without spaces; each syllable
+// cluster of those scripts counts as a word and is timed at the cluster
+// reading speed.
+func CountText(text string) TextStats {
+	var words, cjk, clusters int
+	inWord := false
+
+	runes := []rune(text)
+
+	for i, r := range runes {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			cjk++
+			inWord = false
+		case unicode.In(r, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar) && (unicode.IsLetter(r) || unicode.IsMark(r)):
+			if startsCluster(runes, i) {
+				clusters++
+			}
+
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			if !inWord {
+				words++
+				inWord = true
+			}
+		case inWord && (r == '\'' || r == '’' || r == '-'):
+		default:
+			inWord = false
+		}
+	}
+
+	minutes := float64(words)/wordsPerMinute + float64(cjk)/cjkCharsPerMinute + float64(clusters)/clustersPerMinute
+
+	return TextStats{
+		Words:          words + cjk + clusters,
+		ReadingSeconds: int(math.Ceil(minutes * 60)),
+	}
+}
+
+// startsCluster reports whether the letter at runes[i], in a script written
+// without spaces, begins a new syllable cluster. Vowel signs and other marks
+// attach to the cluster before them, as do letters stacked under a virama or
+// coeng, consonants after a Thai or Lao leading vowel, and final consonants
+// silenced by an asat or similar killer mark.
+func startsCluster(runes []rune, i int) bool {
+	r := runes[i]
+
+	if unicode.IsMark(r) || clusterFollowers[r] {
+		return false
+	}
+
+	if i > 0 && (leadingVowels[runes[i-1]] || clusterStackers[runes[i-1]]) {
+		return false
+	}
+
+	j := i + 1
+
+	// Myanmar medials may sit between a consonant and the mark killing it.
+	for j < len(runes) && (runes[j] == '\u103B' || runes[j] == '\u103E') {
+		j++
+	}
+
+	return j == len(runes) || !clusterKillers[runes[j]]
+}
+
+var (
+	// Thai and Lao vowels written before the consonant they follow in speech.
+	leadingVowels = runeSet('\u0E40', '\u0E41', '\u0E42', '\u0E43', '\u0E44', '\u0EC0', '\u0EC1', '\u0EC2', '\u0EC3', '\u0EC4')
+
+	// Thai and Lao vowels and signs that are letters but never start a
+	// cluster: sara a, sara aa, sara am, lakkhangyao, paiyannoi, mai yamok
+	// and their Lao counterparts.
+	clusterFollowers = runeSet('\u0E30', '\u0E32', '\u0E33', '\u0E45', '\u0E2F', '\u0E46', '\u0EB0', '\u0EB2', '\u0EB3', '\u0EAF', '\u0EC6')
+
+	// The Myanmar virama and the Khmer coeng stack the next consonant under
+	// the current one.
+	clusterStackers = runeSet('\u1039', '\u17D2')
+
+	// Marks that silence the consonant before them: the Myanmar asat, dot
+	// below and virama, the Khmer toandakhiat and the Thai and Lao
+	// cancellation marks.
+	clusterKillers = runeSet('\u103A', '\u1037', '\u1039', '\u17CD', '\u0E4C', '\u0ECC')
+)
+
+func runeSet(runes ...rune) map[rune]bool {
+	set := make(map[rune]bool, len(runes))
+
+	for _, r := range runes {
+		set[r] = true
+	}
+
+	return set
+}
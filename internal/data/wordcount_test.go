@@ -0,0 +1,66 @@
+package data
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		words int
+	}{
+		{"empty", "", 0},
+		{"english", "The quick brown fox jumps over the lazy dog.", 9},
+		{"contractions and hyphens", "Don't stop — it's a well-known trick.", 6},
+		{"numbers", "Chapter 12 starts on page 3", 6},
+		{"accents", "Café naïve résumé", 3},
+		{"chinese", "我爱读书", 4},
+		{"japanese", "これは本です", 6},
+		{"mixed", "Read 三体 today", 4},
+		{"korean", "나는 책을 읽는다", 3},
+		{"myanmar", "မြန်မာစာ", 3},
+		{"myanmar stacked consonant", "မင်္ဂလာပါ", 3},
+		{"myanmar with medials", "ကျွန်တော် စာဖတ်တယ်။", 5},
+		{"thai", "สวัสดีครับ", 7},
+		{"thai leading vowel", "เมือง", 3},
+		{"thai silent final", "จันทร์", 3},
+		{"lao", "ສະບາຍດີ", 4},
+		{"khmer", "ភាសាខ្មែរ", 4},
+		{"khmer subscript", "ខ្ញុំ", 1},
+		{"mixed scripts", "Yangon ရန်ကုန် 2024", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.words, CountText(tt.text).Words)
+		})
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	assert.Equal(t, 0, CountText("").ReadingMinutes())
+
+	words := CountText(strings.Repeat("word ", 460))
+	assert.Equal(t, 120, words.ReadingSeconds)
+	assert.Equal(t, 2, words.ReadingMinutes())
+
+	chars := CountText(strings.Repeat("字", 500))
+	assert.Equal(t, 60, chars.ReadingSeconds)
+	assert.Equal(t, 1, chars.ReadingMinutes())
+
+	syllables := CountText(strings.Repeat("မာ", 700))
+	assert.Equal(t, 700, syllables.Words)
+	assert.Equal(t, 60, syllables.ReadingSeconds)
+
+	stats, err := ContentStats(ContentFormatMarkdown, ptr("# One\n\n**two** [three](https://example.com)"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats.Words)
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,121 @@
+package fake
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/Kaungmyatkyaw2/book-store-api/internal/payments"
+)
+
+const SignatureHeader = "X-Fake-Signature"
+
+// Provider is an in-memory payments.Provider. It never moves money; payments
+// are completed by posting a signed event to the webhook endpoint, which
+// Sign makes easy in tests and local development.
+type Provider struct {
+	secret []byte
+
+	mu            sync.Mutex
+	next          int
+	Payments      map[string]payments.PaymentRequest
+	Voids         []string
+	Refunds       []string
+	Subscriptions map[string]payments.SubscriptionRequest
+	Cancellations []string
+}
+
+func New(secret string) *Provider {
+	return &Provider{
+		secret:        []byte(secret),
+		Payments:      make(map[string]payments.PaymentRequest),
+		Subscriptions: make(map[string]payments.SubscriptionRequest),
+	}
+}
+
+func (p *Provider) Name() string {
+	return "fake"
+}
+
+func (p *Provider) CreatePayment(ctx context.Context, req payments.PaymentRequest) (*payments.Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.next++
+	ref := fmt.Sprintf("fake_pay_%d", p.next)
+	p.Payments[ref] = req
+
+	return &payments.Payment{
+		Ref:         ref,
+		CheckoutURL: fmt.Sprintf("https://payments.invalid/checkout/%s", ref),
+	}, nil
+}
+
+func (p *Provider) CancelPayment(ctx context.Context, paymentRef string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Voids = append(p.Voids, paymentRef)
+	return nil
+}
+
+func (p *Provider) Refund(ctx context.Context, paymentRef string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Refunds = append(p.Refunds, paymentRef)
+	return nil
+}
+
+func (p *Provider) CreateSubscription(ctx context.Context, req payments.SubscriptionRequest) (*payments.Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.next++
+	ref := fmt.Sprintf("fake_sub_%d", p.next)
+	p.Subscriptions[ref] = req
+
+	return &payments.Payment{
+		Ref:         ref,
+		CheckoutURL: fmt.Sprintf("https://payments.invalid/subscribe/%s", ref),
+	}, nil
+}
+
+func (p *Provider) CancelSubscription(ctx context.Context, subscriptionRef string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Cancellations = append(p.Cancellations, subscriptionRef)
+	return nil
+}
+
+func (p *Provider) ParseWebhook(payload []byte, header http.Header) (*payments.Event, error) {
+	signature, err := hex.DecodeString(header.Get(SignatureHeader))
+	if err != nil || !hmac.Equal(signature, p.mac(payload)) {
+		return nil, payments.ErrInvalidSignature
+	}
+
+	var event payments.Event
+
+	if err := json.Unmarshal(payload, &event); err != nil || event.Type == "" || event.PaymentRef == "" {
+		return nil, payments.ErrInvalidPayload
+	}
+
+	return &event, nil
+}
+
+// Sign returns the signature header value for payload.
+func (p *Provider) Sign(payload []byte) string {
+	return hex.EncodeToString(p.mac(payload))
+}
+
+func (p *Provider) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, p.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
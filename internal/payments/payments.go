@@ -0,0 +1,77 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+	EventRefundSucceeded  = "refund.succeeded"
+
+	EventSubscriptionRenewed       = "subscription.renewed"
+	EventSubscriptionRenewalFailed = "subscription.renewal_failed"
+	EventSubscriptionCancelled     = "subscription.cancelled"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidPayload   = errors.New("invalid webhook payload")
+)
+
+// PaymentRequest describes a charge the provider should collect. Amount is in
+// the currency's minor unit.
+type PaymentRequest struct {
+	OrderID     int64
+	Amount      int64
+	Currency    string
+	Description string
+}
+
+// SubscriptionRequest describes a recurring charge the provider should
+// collect every IntervalMonths until it is cancelled.
+type SubscriptionRequest struct {
+	SubscriptionID int64
+	Amount         int64
+	Currency       string
+	IntervalMonths int
+	Description    string
+}
+
+// Payment is the provider's handle for a charge. Ref identifies it in later
+// webhook events and refunds; CheckoutURL is where the buyer completes it.
+type Payment struct {
+	Ref         string `json:"ref"`
+	CheckoutURL string `json:"checkoutUrl"`
+}
+
+// Event is a verified webhook notification from the provider. Subscription
+// events carry the subscription's Ref in PaymentRef and, for renewals, the
+// end of the newly paid period in PeriodEnd.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	PaymentRef string    `json:"paymentRef"`
+	PeriodEnd  time.Time `json:"periodEnd"`
+}
+
+// Provider is implemented by every payment backend the store can sell
+// through.
+type Provider interface {
+	Name() string
+	CreatePayment(ctx context.Context, req PaymentRequest) (*Payment, error)
+	// CancelPayment voids a payment the buyer has not completed yet. A
+	// payment that completes anyway is still reported through the webhook.
+	CancelPayment(ctx context.Context, paymentRef string) error
+	Refund(ctx context.Context, paymentRef string) error
+	// CreateSubscription starts a recurring charge. The provider renews it on
+	// its own and reports every renewal through the webhook.
+	CreateSubscription(ctx context.Context, req SubscriptionRequest) (*Payment, error)
+	CancelSubscription(ctx context.Context, subscriptionRef string) error
+	// ParseWebhook verifies the signature of a webhook request and decodes
+	// the event it carries.
+	ParseWebhook(payload []byte, header http.Header) (*Event, error)
+}
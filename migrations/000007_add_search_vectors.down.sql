@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS chapters_search_vector_idx;
+DROP INDEX IF EXISTS books_search_vector_idx;
+
+ALTER TABLE chapters
+DROP COLUMN search_vector;
+
+ALTER TABLE books
+DROP COLUMN search_vector;
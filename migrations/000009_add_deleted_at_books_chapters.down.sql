@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS chapters_deleted_at_idx;
+DROP INDEX IF EXISTS books_deleted_at_idx;
+
+ALTER TABLE chapters
+DROP COLUMN deleted_at;
+
+ALTER TABLE books
+DROP COLUMN deleted_at;
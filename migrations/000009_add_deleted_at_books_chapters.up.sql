@@ -0,0 +1,8 @@
+ALTER TABLE books
+ADD COLUMN deleted_at timestamp(0) with time zone;
+
+ALTER TABLE chapters
+ADD COLUMN deleted_at timestamp(0) with time zone;
+
+CREATE INDEX IF NOT EXISTS books_deleted_at_idx ON books (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS chapters_deleted_at_idx ON chapters (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS book_collaborators;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS book_collaborators (
+    book_id bigint NOT NULL REFERENCES books ON DELETE CASCADE,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    role text NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+    invited_by bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    responded_at timestamp(0) with time zone,
+    PRIMARY KEY (book_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS book_collaborators_user_id_idx ON book_collaborators (user_id);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS series (
+    id bigserial PRIMARY KEY,
+    title text NOT NULL,
+    description text,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS series_books (
+    series_id bigint NOT NULL REFERENCES series ON DELETE CASCADE,
+    book_id bigint NOT NULL UNIQUE REFERENCES books ON DELETE CASCADE,
+    position integer NOT NULL CHECK (position > 0),
+    PRIMARY KEY (series_id, book_id),
+    UNIQUE (series_id, position)
+);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS entitlements;
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS cart_items;
+
+ALTER TABLE books
+DROP COLUMN currency,
+DROP COLUMN price;
\ No newline at end of file
@@ -0,0 +1,43 @@
+ALTER TABLE books
+ADD COLUMN price bigint NOT NULL DEFAULT 0 CHECK (price >= 0),
+ADD COLUMN currency text NOT NULL DEFAULT 'USD';
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    book_id bigint NOT NULL REFERENCES books ON DELETE CASCADE,
+    added_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, book_id)
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'refunded', 'cancelled')),
+    total bigint NOT NULL CHECK (total >= 0),
+    currency text NOT NULL,
+    payment_provider text,
+    payment_ref text UNIQUE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS orders_user_id_idx ON orders (user_id);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id bigserial PRIMARY KEY,
+    order_id bigint NOT NULL REFERENCES orders ON DELETE CASCADE,
+    book_id bigint REFERENCES books ON DELETE SET NULL,
+    title text NOT NULL,
+    price bigint NOT NULL CHECK (price >= 0),
+    UNIQUE (order_id, book_id)
+);
+
+CREATE TABLE IF NOT EXISTS entitlements (
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    book_id bigint NOT NULL REFERENCES books ON DELETE CASCADE,
+    source text NOT NULL,
+    order_id bigint REFERENCES orders ON DELETE CASCADE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, book_id)
+);
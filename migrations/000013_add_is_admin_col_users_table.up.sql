@@ -0,0 +1,2 @@
+ALTER TABLE users
+ADD COLUMN is_admin boolean NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+ALTER TABLE entitlements
+DROP COLUMN granted_by;
+
+ALTER TABLE chapters
+DROP COLUMN access;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE chapters
+ADD COLUMN access text NOT NULL DEFAULT 'free' CHECK (access IN ('free', 'premium'));
+
+ALTER TABLE entitlements
+ADD COLUMN granted_by bigint REFERENCES users ON DELETE SET NULL;
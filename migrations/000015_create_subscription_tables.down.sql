@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS subscription_authors;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS plans;
\ No newline at end of file
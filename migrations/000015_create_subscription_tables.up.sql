@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS plans (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    price bigint NOT NULL CHECK (price > 0),
+    currency text NOT NULL,
+    interval_months integer NOT NULL DEFAULT 1 CHECK (interval_months > 0),
+    is_active boolean NOT NULL DEFAULT true,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    plan_id bigint NOT NULL REFERENCES plans,
+    status text NOT NULL DEFAULT 'incomplete' CHECK (status IN ('incomplete', 'active', 'past_due', 'cancelled', 'expired')),
+    current_period_start timestamp(0) with time zone,
+    current_period_end timestamp(0) with time zone,
+    grace_until timestamp(0) with time zone,
+    payment_provider text,
+    payment_ref text UNIQUE,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS subscriptions_user_id_open_idx ON subscriptions (user_id) WHERE status IN ('incomplete', 'active', 'past_due');
+
+CREATE TABLE IF NOT EXISTS subscription_authors (
+    user_id bigint PRIMARY KEY REFERENCES users ON DELETE CASCADE,
+    joined_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+ALTER TABLE order_items
+DROP COLUMN discount;
+
+ALTER TABLE orders
+DROP COLUMN discount,
+DROP COLUMN coupon_id;
+
+DROP TABLE IF EXISTS coupons;
\ No newline at end of file
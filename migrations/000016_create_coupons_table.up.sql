@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS coupons (
+    id bigserial PRIMARY KEY,
+    code text NOT NULL,
+    kind text NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    amount bigint NOT NULL CHECK (amount > 0),
+    currency text,
+    book_id bigint REFERENCES books ON DELETE CASCADE,
+    created_by bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    max_uses integer CHECK (max_uses > 0),
+    max_uses_per_user integer CHECK (max_uses_per_user > 0),
+    starts_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    ends_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1,
+    CHECK (kind = 'percent' AND amount <= 100 OR kind = 'fixed' AND currency IS NOT NULL),
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS coupons_code_idx ON coupons (lower(code));
+
+ALTER TABLE orders
+ADD COLUMN coupon_id bigint REFERENCES coupons ON DELETE SET NULL,
+ADD COLUMN discount bigint NOT NULL DEFAULT 0 CHECK (discount >= 0);
+
+CREATE INDEX IF NOT EXISTS orders_coupon_id_idx ON orders (coupon_id) WHERE coupon_id IS NOT NULL;
+
+ALTER TABLE order_items
+ADD COLUMN discount bigint NOT NULL DEFAULT 0 CHECK (discount >= 0 AND discount <= price);
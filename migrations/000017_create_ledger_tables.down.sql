@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_transactions;
+DROP TABLE IF EXISTS payout_statements;
+DROP TABLE IF EXISTS payout_periods;
\ No newline at end of file
@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS payout_periods (
+    id bigserial PRIMARY KEY,
+    period_start timestamp(0) with time zone,
+    period_end timestamp(0) with time zone NOT NULL UNIQUE,
+    closed_by bigint REFERENCES users ON DELETE SET NULL,
+    closed_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS payout_statements (
+    id bigserial PRIMARY KEY,
+    payout_period_id bigint NOT NULL REFERENCES payout_periods ON DELETE CASCADE,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    currency text NOT NULL,
+    amount bigint NOT NULL CHECK (amount > 0),
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    UNIQUE (payout_period_id, user_id, currency)
+);
+
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id bigserial PRIMARY KEY,
+    kind text NOT NULL CHECK (kind IN ('sale', 'refund', 'payout')),
+    order_id bigint REFERENCES orders ON DELETE SET NULL,
+    payout_statement_id bigint REFERENCES payout_statements ON DELETE SET NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id bigserial PRIMARY KEY,
+    transaction_id bigint NOT NULL REFERENCES ledger_transactions ON DELETE CASCADE,
+    account text NOT NULL CHECK (account IN ('cash', 'author_payable', 'platform_revenue')),
+    user_id bigint REFERENCES users ON DELETE SET NULL,
+    book_id bigint REFERENCES books ON DELETE SET NULL,
+    amount bigint NOT NULL,
+    currency text NOT NULL,
+    payout_period_id bigint REFERENCES payout_periods ON DELETE SET NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS ledger_entries_transaction_id_idx ON ledger_entries (transaction_id);
+CREATE INDEX IF NOT EXISTS ledger_entries_author_idx ON ledger_entries (user_id, created_at) WHERE account = 'author_payable';
+CREATE INDEX IF NOT EXISTS ledger_transactions_order_id_idx ON ledger_transactions (order_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS books_rating_average_idx;
+
+ALTER TABLE books
+DROP COLUMN IF EXISTS rating_count,
+DROP COLUMN IF EXISTS rating_average;
+
+DROP TABLE IF EXISTS reviews;
\ No newline at end of file
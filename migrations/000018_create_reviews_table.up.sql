@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS reviews (
+    id bigserial PRIMARY KEY,
+    book_id bigint NOT NULL REFERENCES books ON DELETE CASCADE,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    rating smallint NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1,
+    CONSTRAINT reviews_book_id_user_id_key UNIQUE (book_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS reviews_user_id_idx ON reviews (user_id);
+
+ALTER TABLE books
+ADD COLUMN rating_average numeric(3, 2) NOT NULL DEFAULT 0,
+ADD COLUMN rating_count integer NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS books_rating_average_idx ON books (rating_average, id) WHERE deleted_at IS NULL;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id bigserial PRIMARY KEY,
+    chapter_id bigint NOT NULL REFERENCES chapters ON DELETE CASCADE,
+    parent_id bigint REFERENCES comments ON DELETE CASCADE,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    body text NOT NULL,
+    pinned_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    edited_at timestamp(0) with time zone,
+    deleted_at timestamp(0) with time zone,
+    deleted_by bigint REFERENCES users ON DELETE SET NULL,
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS comments_chapter_id_idx ON comments (chapter_id, created_at, id) WHERE parent_id IS NULL;
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON comments (parent_id, created_at, id) WHERE parent_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS comments_user_id_created_at_idx ON comments (user_id, created_at);
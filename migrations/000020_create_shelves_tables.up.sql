@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS shelves (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    name text NOT NULL,
+    kind text NOT NULL DEFAULT 'custom' CHECK (kind IN ('want-to-read', 'currently-reading', 'finished', 'custom')),
+    is_public boolean NOT NULL DEFAULT false,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS shelves_user_id_name_idx ON shelves (user_id, lower(name));
+CREATE UNIQUE INDEX IF NOT EXISTS shelves_user_id_kind_idx ON shelves (user_id, kind) WHERE kind <> 'custom';
+
+CREATE TABLE IF NOT EXISTS shelf_books (
+    shelf_id bigint NOT NULL REFERENCES shelves ON DELETE CASCADE,
+    book_id bigint NOT NULL REFERENCES books ON DELETE CASCADE,
+    added_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (shelf_id, book_id)
+);
+
+CREATE INDEX IF NOT EXISTS shelf_books_book_id_idx ON shelf_books (book_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS reading_progress;
\ No newline at end of file
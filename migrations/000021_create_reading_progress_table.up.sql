@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS reading_progress (
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    book_id bigint NOT NULL REFERENCES books ON DELETE CASCADE,
+    chapter_id bigint NOT NULL REFERENCES chapters ON DELETE CASCADE,
+    position integer CHECK (position >= 0),
+    percent numeric(5, 2) CHECK (percent BETWEEN 0 AND 100),
+    updated_at timestamp(3) with time zone NOT NULL,
+    PRIMARY KEY (user_id, book_id)
+);
+
+CREATE INDEX IF NOT EXISTS reading_progress_user_id_updated_at_idx ON reading_progress (user_id, updated_at DESC);
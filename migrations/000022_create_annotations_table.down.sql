@@ -0,0 +1 @@
+DROP TABLE IF EXISTS annotations;
\ No newline at end of file
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS annotations (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    book_id bigint NOT NULL REFERENCES books ON DELETE CASCADE,
+    chapter_id bigint NOT NULL REFERENCES chapters ON DELETE CASCADE,
+    kind text NOT NULL CHECK (kind IN ('highlight', 'bookmark', 'note')),
+    start_offset integer NOT NULL CHECK (start_offset >= 0),
+    end_offset integer NOT NULL,
+    quote text NOT NULL DEFAULT '',
+    prefix text NOT NULL DEFAULT '',
+    suffix text NOT NULL DEFAULT '',
+    fingerprint text NOT NULL,
+    note text NOT NULL DEFAULT '',
+    color text NOT NULL DEFAULT '',
+    orphaned boolean NOT NULL DEFAULT false,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1,
+    CHECK (end_offset >= start_offset)
+);
+
+CREATE INDEX IF NOT EXISTS annotations_chapter_id_idx ON annotations (chapter_id);
+CREATE INDEX IF NOT EXISTS annotations_user_id_book_id_idx ON annotations (user_id, book_id);
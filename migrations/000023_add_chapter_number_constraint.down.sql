@@ -0,0 +1 @@
+ALTER TABLE chapters DROP CONSTRAINT IF EXISTS chapters_book_id_chapter_no_key;
\ No newline at end of file
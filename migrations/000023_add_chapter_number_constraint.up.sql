@@ -0,0 +1,10 @@
+UPDATE chapters c
+SET chapter_no = n.rn
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY book_id ORDER BY deleted_at IS NOT NULL, created_at, id) AS rn
+    FROM chapters
+) n
+WHERE c.id = n.id;
+
+ALTER TABLE chapters
+    ADD CONSTRAINT chapters_book_id_chapter_no_key UNIQUE (book_id, chapter_no) DEFERRABLE INITIALLY IMMEDIATE;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS chapter_revisions;
\ No newline at end of file
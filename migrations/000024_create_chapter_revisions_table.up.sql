@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS chapter_revisions (
+    id bigserial PRIMARY KEY,
+    chapter_id bigint NOT NULL REFERENCES chapters ON DELETE CASCADE,
+    revision integer NOT NULL,
+    title text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    content text,
+    access text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    CONSTRAINT chapter_revisions_chapter_id_revision_key UNIQUE (chapter_id, revision)
+);
+
+INSERT INTO chapter_revisions (chapter_id, revision, title, description, content, access, created_at)
+SELECT id, version, title, coalesce(description, ''), content, access, updated_at
+FROM chapters;
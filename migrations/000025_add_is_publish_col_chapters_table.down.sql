@@ -0,0 +1,3 @@
+ALTER TABLE chapters
+DROP COLUMN published_at,
+DROP COLUMN is_published;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE chapters
+ADD COLUMN is_published boolean NOT NULL DEFAULT false,
+ADD COLUMN published_at timestamp(0) with time zone;
+
+UPDATE chapters SET is_published = true, published_at = created_at;
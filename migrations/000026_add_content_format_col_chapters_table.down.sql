@@ -0,0 +1,7 @@
+ALTER TABLE chapter_revisions
+DROP COLUMN content_format;
+
+ALTER TABLE chapters
+DROP COLUMN rendered_version,
+DROP COLUMN rendered_html,
+DROP COLUMN content_format;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TABLE chapters
+ADD COLUMN content_format text NOT NULL DEFAULT 'text' CHECK (content_format IN ('markdown', 'text')),
+ADD COLUMN rendered_html text,
+ADD COLUMN rendered_version integer;
+
+ALTER TABLE chapter_revisions
+ADD COLUMN content_format text NOT NULL DEFAULT 'text';
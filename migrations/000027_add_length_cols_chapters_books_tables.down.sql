@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS books_word_count_idx;
+
+ALTER TABLE books
+DROP COLUMN reading_seconds,
+DROP COLUMN word_count;
+
+ALTER TABLE chapters
+DROP COLUMN reading_seconds,
+DROP COLUMN word_count;
\ No newline at end of file
@@ -0,0 +1,26 @@
+ALTER TABLE chapters
+ADD COLUMN word_count integer NOT NULL DEFAULT 0,
+ADD COLUMN reading_seconds integer NOT NULL DEFAULT 0;
+
+ALTER TABLE books
+ADD COLUMN word_count integer NOT NULL DEFAULT 0,
+ADD COLUMN reading_seconds integer NOT NULL DEFAULT 0;
+
+-- A whitespace count is close enough for existing chapters; the exact,
+-- script-aware count is stored the next time a chapter is saved.
+UPDATE chapters
+SET word_count = coalesce(array_length(regexp_split_to_array(trim(content), '\s+'), 1), 0),
+    reading_seconds = ceil(coalesce(array_length(regexp_split_to_array(trim(content), '\s+'), 1), 0) * 60 / 230.0)
+WHERE trim(coalesce(content, '')) <> '';
+
+UPDATE books b
+SET word_count = s.words, reading_seconds = s.seconds
+FROM (
+    SELECT book_id, sum(word_count) AS words, sum(reading_seconds) AS seconds
+    FROM chapters
+    WHERE deleted_at IS NULL AND is_published = true
+    GROUP BY book_id
+) s
+WHERE b.id = s.book_id;
+
+CREATE INDEX IF NOT EXISTS books_word_count_idx ON books (word_count);
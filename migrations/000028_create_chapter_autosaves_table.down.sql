@@ -0,0 +1 @@
+DROP TABLE IF EXISTS chapter_autosaves;
\ No newline at end of file
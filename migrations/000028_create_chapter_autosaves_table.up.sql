@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS chapter_autosaves (
+    user_id bigint NOT NULL REFERENCES users ON DELETE CASCADE,
+    chapter_id bigint NOT NULL REFERENCES chapters ON DELETE CASCADE,
+    base_version integer NOT NULL,
+    title text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    content text NOT NULL DEFAULT '',
+    content_format text NOT NULL,
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, chapter_id)
+);
+
+CREATE INDEX IF NOT EXISTS chapter_autosaves_updated_at_idx ON chapter_autosaves (updated_at);
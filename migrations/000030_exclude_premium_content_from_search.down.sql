@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS chapters_search_vector_idx;
+
+ALTER TABLE chapters
+DROP COLUMN search_vector;
+
+ALTER TABLE chapters
+ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(content, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS chapters_search_vector_idx ON chapters USING GIN (search_vector);